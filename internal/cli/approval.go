@@ -0,0 +1,90 @@
+// Package cli implements the command-line interface for Ralph using Cobra.
+//
+// This file implements interactive tool approval for `ralph run --approve`.
+//
+// See specs/cli.md for detailed CLI specification.
+package cli
+
+import (
+	"fmt"
+	"strings"
+	"sync"
+
+	"github.com/JanDeDobbeleer/copilot-ralph/internal/core"
+	"github.com/JanDeDobbeleer/copilot-ralph/internal/sdk"
+	"github.com/JanDeDobbeleer/copilot-ralph/internal/tui/styles"
+)
+
+// maxApprovalDetail is the maximum number of characters of an argument shown in an approval prompt.
+const maxApprovalDetail = 200
+
+// terminalApprover asks on the console whether a tool may run.
+type terminalApprover struct {
+	console *console
+	mu      sync.Mutex
+}
+
+// ApproveTool implements core.ToolApprover. Tools asking at the same time are asked about one by one.
+func (a *terminalApprover) ApproveTool(request sdk.PermissionRequest) core.ApprovalAnswer {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+
+	tool := request.Tool()
+	question := fmt.Sprintf("🔐 %s wants to run", tool)
+	if summary := request.Summary(); summary != "" {
+		question += ": " + summary
+	}
+
+	fmt.Println()
+	fmt.Println(styles.WarningStyle.Render(question))
+	for _, detail := range request.Details() {
+		fmt.Println("   " + truncateDetail(detail))
+	}
+	fmt.Print(styles.WarningStyle.Render(fmt.Sprintf("Allow? [y] once, [a] always for %s, [n] deny: ", tool)))
+
+	line, ok := a.console.readLine()
+	if !ok {
+		fmt.Println()
+		return core.ApprovalDeny
+	}
+
+	return parseApprovalAnswer(line)
+}
+
+// parseApprovalAnswer maps a typed answer to an approval answer, denying anything unknown.
+func parseApprovalAnswer(line string) core.ApprovalAnswer {
+	switch strings.ToLower(strings.TrimSpace(line)) {
+	case "y", "yes", "once":
+		return core.ApprovalAllowOnce
+	case "a", "always":
+		return core.ApprovalAllowAlways
+	default:
+		return core.ApprovalDeny
+	}
+}
+
+// truncateDetail shortens a request argument to a single line for display.
+func truncateDetail(detail string) string {
+	detail = strings.Join(strings.Fields(detail), " ")
+
+	// Truncate on characters so multi-byte characters are never cut in half
+	if runes := []rune(detail); len(runes) > maxApprovalDetail {
+		return string(runes[:maxApprovalDetail]) + "..."
+	}
+
+	return detail
+}
+
+// approveLabel describes which tools need approval for display.
+func approveLabel(cfg *core.LoopConfig) string {
+	switch cfg.Approve {
+	case core.ApproveWrites:
+		return "ask before file writes"
+	case core.ApproveShell:
+		return "ask before shell commands and file writes"
+	case core.ApproveAll:
+		return "ask before every tool"
+	default:
+		return "never ask"
+	}
+}
@@ -0,0 +1,64 @@
+package cli
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/JanDeDobbeleer/copilot-ralph/internal/core"
+)
+
+func TestParseApprovalAnswer(t *testing.T) {
+	tests := []struct {
+		line string
+		want core.ApprovalAnswer
+	}{
+		{line: "y", want: core.ApprovalAllowOnce},
+		{line: " Yes\n", want: core.ApprovalAllowOnce},
+		{line: "a", want: core.ApprovalAllowAlways},
+		{line: "always", want: core.ApprovalAllowAlways},
+		{line: "n", want: core.ApprovalDeny},
+		{line: "", want: core.ApprovalDeny},
+		{line: "maybe", want: core.ApprovalDeny},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.line, func(t *testing.T) {
+			assert.Equal(t, tt.want, parseApprovalAnswer(tt.line))
+		})
+	}
+}
+
+func TestApproveLabel(t *testing.T) {
+	assert.Equal(t, "never ask", approveLabel(&core.LoopConfig{}))
+	assert.Equal(t, "ask before shell commands and file writes", approveLabel(&core.LoopConfig{Approve: core.ApproveShell}))
+}
+
+func TestTruncateDetail(t *testing.T) {
+	assert.Equal(t, "diff: a b", truncateDetail("diff: a\n  b"))
+	assert.Len(t, truncateDetail(strings.Repeat("x", 500)), maxApprovalDetail+3)
+
+	// A multi-byte character at the limit is kept whole
+	path := strings.Repeat("x", maxApprovalDetail-1) + "é/ü.go"
+	assert.Equal(t, strings.Repeat("x", maxApprovalDetail-1)+"é...", truncateDetail(path))
+	assert.Equal(t, "docs/ünïcode.md", truncateDetail("docs/ünïcode.md"))
+}
+
+func TestConsoleReadLineAfterInputClosed(t *testing.T) {
+	lines := make(chan string)
+	c := &console{lines: lines, prompts: make(chan chan string), done: make(chan struct{})}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	go watchControls(ctx, core.NewLoopEngine(nil, nil), c)
+
+	close(lines)
+	_, ok := c.readLine()
+	assert.False(t, ok)
+
+	cancel()
+	_, ok = c.readLine()
+	assert.False(t, ok)
+}
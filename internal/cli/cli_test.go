@@ -6,8 +6,10 @@ package cli
 import (
 	"bytes"
 	"errors"
+	"fmt"
 	"os"
 	"path/filepath"
+	"strings"
 	"testing"
 	"time"
 
@@ -97,6 +99,185 @@ func TestValidateRunConfig(t *testing.T) {
 			expectError: true,
 			errorMsg:    "max-iterations must be positive",
 		},
+		{
+			name: "approval without reviewer",
+			config: &core.LoopConfig{
+				Prompt:          "test",
+				MaxIterations:   10,
+				Timeout:         30 * time.Minute,
+				RequireApproval: true,
+			},
+			expectError: true,
+			errorMsg:    "require-approval requires a reviewer-model",
+		},
+		{
+			name: "invalid approve mode",
+			config: &core.LoopConfig{
+				Prompt:        "test",
+				MaxIterations: 10,
+				Timeout:       30 * time.Minute,
+				Approve:       "sometimes",
+			},
+			expectError: true,
+			errorMsg:    "invalid approve mode",
+		},
+		{
+			name: "empty fallback model",
+			config: &core.LoopConfig{
+				Prompt:         "test",
+				MaxIterations:  10,
+				Timeout:        30 * time.Minute,
+				FallbackModels: []string{"gpt-5", ""},
+				FallbackAfter:  2,
+			},
+			expectError: true,
+			errorMsg:    "fallback-model cannot contain empty model names",
+		},
+		{
+			name: "zero fallback after",
+			config: &core.LoopConfig{
+				Prompt:         "test",
+				MaxIterations:  10,
+				Timeout:        30 * time.Minute,
+				FallbackModels: []string{"gpt-5"},
+			},
+			expectError: true,
+			errorMsg:    "fallback-after must be positive",
+		},
+		{
+			name: "invalid completion policy",
+			config: &core.LoopConfig{
+				Prompt:           "test",
+				MaxIterations:    10,
+				Timeout:          30 * time.Minute,
+				CompletionPolicy: "sometimes",
+			},
+			expectError: true,
+			errorMsg:    "invalid completion policy",
+		},
+		{
+			name: "consecutive policy requires positive streak",
+			config: &core.LoopConfig{
+				Prompt:           "test",
+				MaxIterations:    10,
+				Timeout:          30 * time.Minute,
+				CompletionPolicy: core.CompletionConsecutive,
+			},
+			expectError: true,
+			errorMsg:    "promise-streak must be positive",
+		},
+		{
+			name: "invalid session strategy",
+			config: &core.LoopConfig{
+				Prompt:          "test",
+				MaxIterations:   10,
+				Timeout:         30 * time.Minute,
+				SessionStrategy: "sometimes",
+			},
+			expectError: true,
+			errorMsg:    "invalid session strategy",
+		},
+		{
+			name: "rotate strategy requires positive interval",
+			config: &core.LoopConfig{
+				Prompt:          "test",
+				MaxIterations:   10,
+				Timeout:         30 * time.Minute,
+				SessionStrategy: core.SessionRotate,
+			},
+			expectError: true,
+			errorMsg:    "session-rotate-every must be positive",
+		},
+		{
+			name: "invalid stagnation policy",
+			config: &core.LoopConfig{
+				Prompt:           "test",
+				MaxIterations:    10,
+				Timeout:          30 * time.Minute,
+				StagnationPolicy: "panic",
+			},
+			expectError: true,
+			errorMsg:    "invalid stagnation policy",
+		},
+		{
+			name: "switch-model policy requires a model",
+			config: &core.LoopConfig{
+				Prompt:           "test",
+				MaxIterations:    10,
+				Timeout:          30 * time.Minute,
+				StagnationLimit:  3,
+				StagnationPolicy: core.StagnationSwitchModel,
+			},
+			expectError: true,
+			errorMsg:    "stagnation-model is required",
+		},
+		{
+			name: "response similarity above one",
+			config: &core.LoopConfig{
+				Prompt:             "test",
+				MaxIterations:      10,
+				Timeout:            30 * time.Minute,
+				ResponseSimilarity: 1.5,
+			},
+			expectError: true,
+			errorMsg:    "response-similarity must be between 0 and 1",
+		},
+		{
+			name: "invalid repetition action",
+			config: &core.LoopConfig{
+				Prompt:           "test",
+				MaxIterations:    10,
+				Timeout:          30 * time.Minute,
+				RepetitionAction: "shout",
+			},
+			expectError: true,
+			errorMsg:    "invalid repetition action",
+		},
+		{
+			name: "negative summary limit",
+			config: &core.LoopConfig{
+				Prompt:        "test",
+				MaxIterations: 10,
+				Timeout:       30 * time.Minute,
+				SummaryLimit:  -1,
+			},
+			expectError: true,
+			errorMsg:    "summary-limit cannot be negative",
+		},
+		{
+			name: "checklist with plan",
+			config: &core.LoopConfig{
+				Prompt:        "test",
+				MaxIterations: 10,
+				Timeout:       30 * time.Minute,
+				Checklist:     "TODO.md",
+				Phases:        []core.Phase{{Name: "plan", Prompt: "Plan"}},
+			},
+			expectError: true,
+			errorMsg:    "checklist cannot be combined with a plan",
+		},
+		{
+			name: "negative iteration timeout",
+			config: &core.LoopConfig{
+				Prompt:           "test",
+				MaxIterations:    10,
+				Timeout:          30 * time.Minute,
+				IterationTimeout: -time.Second,
+			},
+			expectError: true,
+			errorMsg:    "iteration-timeout cannot be negative",
+		},
+		{
+			name: "negative max tokens",
+			config: &core.LoopConfig{
+				Prompt:        "test",
+				MaxIterations: 10,
+				Timeout:       30 * time.Minute,
+				MaxTokens:     -1,
+			},
+			expectError: true,
+			errorMsg:    "max-tokens cannot be negative",
+		},
 		{
 			name: "zero timeout not allowed",
 			config: &core.LoopConfig{
@@ -131,6 +312,9 @@ func TestBuildLoopConfig(t *testing.T) {
 		promise        string
 		model          string
 		workingDir     string
+		rollback       string
+		systemPrompt   string
+		systemMode     string
 		expectedPrompt string
 		maxIterations  int
 		timeout        time.Duration
@@ -153,8 +337,21 @@ func TestBuildLoopConfig(t *testing.T) {
 			promise:        "Done!",
 			model:          "gpt-3.5-turbo",
 			workingDir:     "/tmp",
+			rollback:       "go test ./...",
 			expectedPrompt: "override test",
 		},
+		{
+			name:           "loads system prompt file",
+			prompt:         "system prompt test",
+			maxIterations:  3,
+			timeout:        time.Minute,
+			promise:        "Done!",
+			model:          "gpt-4",
+			workingDir:     "project",
+			systemPrompt:   "You work on {{.Prompt}}",
+			systemMode:     "replace",
+			expectedPrompt: "system prompt test",
+		},
 	}
 
 	for _, tt := range tests {
@@ -165,12 +362,23 @@ func TestBuildLoopConfig(t *testing.T) {
 			oldPromise := runPromise
 			oldModel := runModel
 			oldWorkingDir := runWorkingDir
+			oldRollback := runRollback
+			oldSystemPrompt := runSystemPrompt
+			oldSystemMode := runSystemPromptMode
+
+			runSystemPrompt = ""
+			if tt.systemPrompt != "" {
+				runSystemPrompt = filepath.Join(t.TempDir(), "system.md")
+				require.NoError(t, os.WriteFile(runSystemPrompt, []byte(tt.systemPrompt), 0o644))
+			}
 
 			runMaxIterations = tt.maxIterations
 			runTimeout = tt.timeout
 			runPromise = tt.promise
 			runModel = tt.model
 			runWorkingDir = tt.workingDir
+			runRollback = tt.rollback
+			runSystemPromptMode = tt.systemMode
 
 			defer func() {
 				runMaxIterations = oldMaxIterations
@@ -178,16 +386,27 @@ func TestBuildLoopConfig(t *testing.T) {
 				runPromise = oldPromise
 				runModel = oldModel
 				runWorkingDir = oldWorkingDir
+				runRollback = oldRollback
+				runSystemPrompt = oldSystemPrompt
+				runSystemPromptMode = oldSystemMode
 			}()
 
-			result := buildLoopConfig(tt.prompt)
+			result, err := buildLoopConfig(tt.prompt)
+			require.NoError(t, err)
+
+			workingDir, err := filepath.Abs(tt.workingDir)
+			require.NoError(t, err)
 
 			assert.Equal(t, tt.expectedPrompt, result.Prompt)
 			assert.Equal(t, tt.maxIterations, result.MaxIterations)
 			assert.Equal(t, tt.timeout, result.Timeout)
 			assert.Equal(t, tt.promise, result.PromisePhrase)
 			assert.Equal(t, tt.model, result.Model)
-			assert.Equal(t, tt.workingDir, result.WorkingDir)
+			assert.Equal(t, workingDir, result.WorkingDir)
+			assert.Equal(t, filepath.Join(workingDir, core.StateDirName), result.CheckpointDir)
+			assert.Equal(t, tt.rollback, result.RollbackCommand)
+			assert.Equal(t, tt.systemPrompt, result.SystemPrompt)
+			assert.Equal(t, tt.systemMode, result.SystemPromptMode)
 		})
 	}
 }
@@ -235,8 +454,53 @@ func TestValidateSettings(t *testing.T) {
 		systemMode  string
 		logLevel    string
 		errorMsg    string
+		vars        []string
+		prices      []string
+		approve     string
+		parallel    int
 		expectError bool
 	}{
+		{
+			name:        "invalid price",
+			systemMode:  "append",
+			prices:      []string{"gpt-4=2"},
+			expectError: true,
+			errorMsg:    "invalid price",
+		},
+		{
+			name:        "valid price",
+			systemMode:  "append",
+			prices:      []string{"gpt-4=2:8", "gpt-5=1.25:10:0.125:0"},
+			expectError: false,
+		},
+		{
+			name:        "negative parallel",
+			systemMode:  "append",
+			parallel:    -1,
+			expectError: true,
+			errorMsg:    "parallel cannot be negative",
+		},
+		{
+			name:        "approve with parallel",
+			systemMode:  "append",
+			parallel:    2,
+			approve:     "shell",
+			expectError: true,
+			errorMsg:    "approve cannot be combined with parallel runs",
+		},
+		{
+			name:        "invalid var",
+			systemMode:  "append",
+			vars:        []string{"novalue"},
+			expectError: true,
+			errorMsg:    "invalid var",
+		},
+		{
+			name:        "valid var",
+			systemMode:  "append",
+			vars:        []string{"pkg=parser"},
+			expectError: false,
+		},
 		{
 			name:        "invalid system message mode",
 			systemMode:  "invalid",
@@ -250,10 +514,22 @@ func TestValidateSettings(t *testing.T) {
 		t.Run(tt.name, func(t *testing.T) {
 			// Save and restore globals
 			oldSystemMode := runSystemPromptMode
+			oldVars := runVars
+			oldPrices := runPrices
+			oldParallel := runParallel
+			oldApprove := runApprove
 			runSystemPromptMode = tt.systemMode
+			runVars = tt.vars
+			runPrices = tt.prices
+			runParallel = tt.parallel
+			runApprove = tt.approve
 
 			defer func() {
 				runSystemPromptMode = oldSystemMode
+				runVars = oldVars
+				runPrices = oldPrices
+				runParallel = oldParallel
+				runApprove = oldApprove
 			}()
 
 			err := validateSettings()
@@ -277,6 +553,9 @@ func TestPrintDryRun(t *testing.T) {
 		Timeout:       10 * time.Minute,
 		PromisePhrase: "Done!",
 		WorkingDir:    ".",
+
+		SessionStrategy:    core.SessionRotate,
+		SessionRotateEvery: 3,
 	}
 
 	// Capture stdout
@@ -298,11 +577,125 @@ func TestPrintDryRun(t *testing.T) {
 	assert.Contains(t, output, "test prompt")
 	assert.Contains(t, output, "gpt-4")
 	assert.Contains(t, output, "5")
+	assert.Contains(t, output, "[Iteration 1/5]")
+	assert.Contains(t, output, "<promise>Done!</promise>")
+	assert.Contains(t, output, "rotate (every 3 iterations)")
+}
+
+func TestPrintDryRunToolPolicy(t *testing.T) {
+	policy := filepath.Join(t.TempDir(), "policy.yaml")
+	require.NoError(t, os.WriteFile(policy, []byte(`
+default: deny
+rules:
+  - allow: bash
+    match: '^go (test|build|vet)'
+  - allow: write
+    match: '^internal/'
+`), 0o644))
+
+	cfg := &core.LoopConfig{
+		Prompt:        "test prompt",
+		Model:         "gpt-4",
+		MaxIterations: 5,
+		PromisePhrase: "Done!",
+		WorkingDir:    ".",
+		ToolPolicy:    policy,
+	}
+
+	// Capture stdout
+	oldStdout := os.Stdout
+	r, w, _ := os.Pipe()
+	os.Stdout = w
+
+	err := printDryRun(cfg)
+
+	w.Close()
+	os.Stdout = oldStdout
+
+	var buf bytes.Buffer
+	buf.ReadFrom(r)
+	output := buf.String()
+
+	require.NoError(t, err)
+	assert.Contains(t, output, policy+" (2 rules, default deny)")
+	assert.Contains(t, output, "1. allow bash matching ^go (test|build|vet)")
+	assert.Contains(t, output, "2. allow write matching ^internal/")
+
+	cfg.ToolPolicy = filepath.Join(t.TempDir(), "missing.yaml")
+	assert.ErrorContains(t, printDryRun(cfg), "failed to read tool policy file")
+}
+
+func TestResolveMCPConfig(t *testing.T) {
+	workingDir := t.TempDir()
+	explicit := filepath.Join(t.TempDir(), "mcp.json")
+
+	path, err := resolveMCPConfig("", workingDir)
+	require.NoError(t, err)
+	assert.Empty(t, path, "no project configuration")
+
+	project := filepath.Join(workingDir, core.StateDirName, core.MCPConfigFileName)
+	require.NoError(t, os.MkdirAll(filepath.Dir(project), 0o755))
+	require.NoError(t, os.WriteFile(project, []byte(`{"mcpServers": {}}`), 0o644))
+
+	path, err = resolveMCPConfig("", workingDir)
+	require.NoError(t, err)
+	assert.Equal(t, project, path)
+
+	path, err = resolveMCPConfig(explicit, workingDir)
+	require.NoError(t, err)
+	assert.Equal(t, explicit, path, "the flag overrides the project configuration")
+
+	// A working directory that is a file cannot hold a project configuration
+	notDir := filepath.Join(t.TempDir(), "file")
+	require.NoError(t, os.WriteFile(notDir, nil, 0o644))
+	_, err = resolveMCPConfig("", notDir)
+	assert.ErrorContains(t, err, "failed to read MCP config file")
+}
+
+func TestPrintMCPServers(t *testing.T) {
+	mcp := filepath.Join(t.TempDir(), "mcp.json")
+	require.NoError(t, os.WriteFile(mcp, []byte(`{
+  "mcpServers": {
+    "docs": {"command": "npx", "args": ["-y", "@acme/docs-mcp"]},
+    "issues": {"type": "sse", "url": "https://mcp.example.com/issues"}
+  }
+}`), 0o644))
+
+	cfg := &core.LoopConfig{
+		Prompt:        "test prompt",
+		Model:         "gpt-4",
+		MaxIterations: 5,
+		PromisePhrase: "Done!",
+		WorkingDir:    ".",
+		MCPConfig:     mcp,
+	}
+
+	// Capture stdout
+	oldStdout := os.Stdout
+	r, w, _ := os.Pipe()
+	os.Stdout = w
+
+	err := printDryRun(cfg)
+	printLoopConfig(cfg)
+
+	w.Close()
+	os.Stdout = oldStdout
+
+	var buf bytes.Buffer
+	buf.ReadFrom(r)
+	output := buf.String()
+
+	require.NoError(t, err)
+	assert.Equal(t, 2, strings.Count(output, "docs (local: npx -y @acme/docs-mcp)"))
+	assert.Equal(t, 2, strings.Count(output, "issues (sse: https://mcp.example.com/issues)"))
+
+	cfg.MCPConfig = filepath.Join(t.TempDir(), "missing.json")
+	assert.ErrorContains(t, printDryRun(cfg), "failed to read MCP config file")
 }
 
 func TestToolErrorsContinueExecution(t *testing.T) {
 	// Create a mock event stream with tool errors
-	events := make(chan any, 10)
+	events := make(chan core.Event, 10)
 
 	// Send events including tool errors
 	go func() {
@@ -377,3 +770,109 @@ func TestToolErrorsContinueExecution(t *testing.T) {
 	assert.True(t, successfulToolSeen, "Successful tool after error should be processed")
 	assert.True(t, iterationCompleteSeen, "Iteration complete should be processed after tool error")
 }
+
+func TestPromiseStatus(t *testing.T) {
+	tests := []struct {
+		result *core.LoopResult
+		name   string
+		policy core.CompletionPolicy
+		want   string
+	}{
+		{
+			name:   "accepted",
+			result: &core.LoopResult{PromiseIteration: 2, FirstPromiseIteration: 2},
+			want:   "accepted in iteration 2",
+		},
+		{
+			name:   "never detected",
+			result: &core.LoopResult{},
+			want:   "not reached",
+		},
+		{
+			name:   "ignored",
+			result: &core.LoopResult{FirstPromiseIteration: 3},
+			policy: core.CompletionIgnore,
+			want:   "ignored, first detected in iteration 3",
+		},
+		{
+			name:   "streak not reached",
+			result: &core.LoopResult{FirstPromiseIteration: 3},
+			policy: core.CompletionConsecutive,
+			want:   "detected but not accepted, first in iteration 3",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.want, promiseStatus(tt.result, &core.LoopConfig{CompletionPolicy: tt.policy}))
+		})
+	}
+}
+
+func TestExitCode(t *testing.T) {
+	tests := []struct {
+		result *core.LoopResult
+		name   string
+		want   int
+	}{
+		{
+			name: "nil result",
+			want: exitCancelled,
+		},
+		{
+			name:   "promise accepted",
+			result: &core.LoopResult{State: core.StateComplete, Iterations: 2, PromiseIteration: 2},
+			want:   exitSuccess,
+		},
+		{
+			name:   "completed without promise",
+			result: &core.LoopResult{State: core.StateComplete, Iterations: 10},
+			want:   exitMaxIterations,
+		},
+		{
+			name:   "promise ignored",
+			result: &core.LoopResult{State: core.StateComplete, Iterations: 10, FirstPromiseIteration: 3},
+			want:   exitMaxIterations,
+		},
+		{
+			name:   "cancelled",
+			result: &core.LoopResult{State: core.StateCancelled},
+			want:   exitCancelled,
+		},
+		{
+			name:   "timeout",
+			result: &core.LoopResult{State: core.StateFailed, Error: core.ErrLoopTimeout},
+			want:   exitTimeout,
+		},
+		{
+			name:   "stagnated",
+			result: &core.LoopResult{State: core.StateFailed, Error: fmt.Errorf("iteration 4 failed: %w", core.ErrStagnation)},
+			want:   exitStagnated,
+		},
+		{
+			name:   "budget exceeded",
+			result: &core.LoopResult{State: core.StateFailed, Error: fmt.Errorf("iteration 2 failed: %w", core.ErrBudgetExceeded)},
+			want:   exitBudget,
+		},
+		{
+			name:   "failed",
+			result: &core.LoopResult{State: core.StateFailed, Error: errors.New("boom")},
+			want:   exitFailed,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.want, exitCode(tt.result))
+		})
+	}
+}
+
+func TestParsePrices(t *testing.T) {
+	prices := parsePrices([]string{"gpt-4=2:8", "gpt-5=1.25:10:0.125:0.5", "broken"})
+
+	assert.Equal(t, map[string]core.ModelPrice{
+		"gpt-4": {Input: 2, Output: 8},
+		"gpt-5": {Input: 1.25, Output: 10, CacheRead: 0.125, CacheWrite: 0.5},
+	}, prices)
+}
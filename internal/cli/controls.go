@@ -0,0 +1,158 @@
+// Package cli implements the command-line interface for Ralph using Cobra.
+//
+// This file implements the interactive controls of a running `ralph run` loop.
+//
+// See specs/cli.md for detailed CLI specification.
+package cli
+
+import (
+	"bufio"
+	"context"
+	"fmt"
+	"os"
+	"os/signal"
+	"strings"
+
+	"github.com/JanDeDobbeleer/copilot-ralph/internal/core"
+	"github.com/JanDeDobbeleer/copilot-ralph/internal/tui/styles"
+)
+
+// console owns the lines typed on an interactive terminal during a run. A line answers
+// the pending prompt, if there is one, and controls the loop otherwise.
+type console struct {
+	lines   <-chan string
+	prompts chan chan string
+	done    chan struct{}
+}
+
+// newConsole creates a console reading lines from in when it is an interactive terminal.
+func newConsole(in *os.File) *console {
+	return &console{
+		lines:   terminalLines(in),
+		prompts: make(chan chan string),
+		done:    make(chan struct{}),
+	}
+}
+
+// interactive reports whether lines can be typed on the console.
+func (c *console) interactive() bool {
+	return c.lines != nil
+}
+
+// readLine waits for the next line typed on the console.
+// It reports false when the console stopped before a line was typed.
+func (c *console) readLine() (string, bool) {
+	answer := make(chan string, 1)
+
+	select {
+	case c.prompts <- answer:
+	case <-c.done:
+		return "", false
+	}
+
+	select {
+	case line, ok := <-answer:
+		return line, ok
+	case <-c.done:
+		return "", false
+	}
+}
+
+// watchControls toggles pause whenever Enter is pressed on an empty line of the console
+// or a pause signal (Ctrl+Z) is received, and steers the loop with every other line
+// typed, until ctx is done. Lines answering a prompt are handed to it instead.
+func watchControls(ctx context.Context, engine *core.LoopEngine, c *console) {
+	defer close(c.done)
+
+	sigCh := make(chan os.Signal, 1)
+	if len(pauseSignals) > 0 {
+		signal.Notify(sigCh, pauseSignals...)
+		defer signal.Stop(sigCh)
+	}
+
+	lines := c.lines
+	var pending chan string
+
+	for {
+		// Only one prompt waits for a line at a time
+		prompts := c.prompts
+		if pending != nil {
+			prompts = nil
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-sigCh:
+			togglePause(engine)
+		case answer := <-prompts:
+			if lines == nil {
+				close(answer)
+				continue
+			}
+
+			pending = answer
+		case line, ok := <-lines:
+			if !ok {
+				lines = nil
+				if pending != nil {
+					close(pending)
+					pending = nil
+				}
+				continue
+			}
+
+			if pending != nil {
+				pending <- line
+				pending = nil
+				continue
+			}
+
+			if strings.TrimSpace(line) == "" {
+				togglePause(engine)
+				continue
+			}
+
+			if err := engine.Steer(line); err != nil {
+				fmt.Println(styles.ErrorStyle.Render(fmt.Sprintf("✗ Failed to steer: %v", err)))
+			}
+		}
+	}
+}
+
+// terminalLines returns the lines typed on in, or nil when in is not an interactive terminal.
+func terminalLines(in *os.File) <-chan string {
+	info, err := in.Stat()
+	if err != nil || info.Mode()&os.ModeCharDevice == 0 {
+		return nil
+	}
+
+	lines := make(chan string)
+	go func() {
+		defer close(lines)
+		scanner := bufio.NewScanner(in)
+		for scanner.Scan() {
+			lines <- scanner.Text()
+		}
+	}()
+
+	return lines
+}
+
+// togglePause pauses or resumes the loop and tells the user what happens next.
+// Pausing and resuming themselves are shown through the loop events.
+func togglePause(engine *core.LoopEngine) {
+	paused, err := engine.TogglePause()
+	if err != nil {
+		return
+	}
+
+	if paused {
+		fmt.Println(styles.WarningStyle.Render("\n⏸ Pausing after the current iteration..."))
+		return
+	}
+
+	if engine.State() != core.StatePaused {
+		fmt.Println(styles.InfoStyle.Render("\n▶ Continuing without pausing"))
+	}
+}
@@ -0,0 +1,14 @@
+//go:build !windows
+
+// Package cli implements the command-line interface for Ralph using Cobra.
+//
+// This file defines the signals that toggle pause on Unix systems.
+package cli
+
+import (
+	"os"
+	"syscall"
+)
+
+// pauseSignals toggle pause instead of suspending the process.
+var pauseSignals = []os.Signal{syscall.SIGTSTP}
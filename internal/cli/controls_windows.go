@@ -0,0 +1,11 @@
+//go:build windows
+
+// Package cli implements the command-line interface for Ralph using Cobra.
+//
+// This file defines the signals that toggle pause on Windows, which has none.
+package cli
+
+import "os"
+
+// pauseSignals toggle pause instead of suspending the process.
+var pauseSignals []os.Signal
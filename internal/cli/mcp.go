@@ -0,0 +1,53 @@
+// Package cli implements the command-line interface for Ralph using Cobra.
+//
+// This file implements the MCP servers of `ralph run --mcp-config`.
+//
+// See specs/cli.md for detailed CLI specification.
+package cli
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"github.com/JanDeDobbeleer/copilot-ralph/internal/core"
+	"github.com/JanDeDobbeleer/copilot-ralph/internal/sdk"
+)
+
+// resolveMCPConfig returns the absolute path of the MCP configuration: path when set,
+// otherwise the project configuration in the working directory if it exists, or "" for none.
+func resolveMCPConfig(path, workingDir string) (string, error) {
+	if path == "" {
+		project := filepath.Join(workingDir, core.StateDirName, core.MCPConfigFileName)
+		if _, err := os.Stat(project); err != nil {
+			if errors.Is(err, os.ErrNotExist) {
+				return "", nil
+			}
+
+			return "", fmt.Errorf("failed to read MCP config file %s: %w", project, err)
+		}
+		path = project
+	}
+
+	config, err := filepath.Abs(path)
+	if err != nil {
+		return "", fmt.Errorf("invalid MCP config path: %w", err)
+	}
+
+	return config, nil
+}
+
+// loadMCPConfig loads the MCP configuration of the loop, nil when none is configured.
+func loadMCPConfig(cfg *core.LoopConfig) (*sdk.MCPConfig, error) {
+	if cfg.MCPConfig == "" {
+		return nil, nil
+	}
+
+	return sdk.LoadMCPConfig(cfg.MCPConfig)
+}
+
+// mcpServerLabel describes an MCP server for display, e.g. "issues (http: https://mcp.example.com)".
+func mcpServerLabel(name string, server sdk.MCPServer) string {
+	return fmt.Sprintf("%s (%s)", name, server.String())
+}
@@ -0,0 +1,247 @@
+// Package cli implements the command-line interface for Ralph using Cobra.
+//
+// This file implements parallel best-of-N runs for `ralph run --parallel`.
+//
+// See specs/cli.md for detailed CLI specification.
+package cli
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+	"os"
+	"os/signal"
+	"sync"
+	"syscall"
+	"time"
+
+	"github.com/JanDeDobbeleer/copilot-ralph/internal/core"
+	"github.com/JanDeDobbeleer/copilot-ralph/internal/tui/styles"
+)
+
+// prefixWriter writes complete lines to a shared writer, each starting with a prefix,
+// so the output of concurrent runs stays readable. Writers sharing out must share mu.
+type prefixWriter struct {
+	mu     *sync.Mutex
+	out    io.Writer
+	prefix string
+	buf    []byte
+}
+
+// Write buffers p and writes every complete line with the prefix.
+func (w *prefixWriter) Write(p []byte) (int, error) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	w.buf = append(w.buf, p...)
+	for {
+		i := bytes.IndexByte(w.buf, '\n')
+		if i < 0 {
+			return len(p), nil
+		}
+
+		if err := w.writeLine(w.buf[:i+1]); err != nil {
+			return 0, err
+		}
+
+		w.buf = w.buf[i+1:]
+	}
+}
+
+// Flush writes a pending incomplete line.
+func (w *prefixWriter) Flush() error {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	if len(w.buf) == 0 {
+		return nil
+	}
+
+	line := append(w.buf, '\n')
+	w.buf = nil
+
+	return w.writeLine(line)
+}
+
+// writeLine writes a single line with the prefix. Must be called with lock held.
+func (w *prefixWriter) writeLine(line []byte) error {
+	if _, err := io.WriteString(w.out, w.prefix); err != nil {
+		return err
+	}
+
+	_, err := w.out.Write(line)
+	return err
+}
+
+// executeParallel runs n loops concurrently in separate git worktrees, checks out
+// the branch of the best one when it is verified and exits the process with its exit code.
+func executeParallel(loopConfig *core.LoopConfig, n int, settings clientSettings) error {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	run, err := core.NewParallelRun(ctx, loopConfig, n)
+	if err != nil {
+		return err
+	}
+
+	sigCh := make(chan os.Signal, 1)
+	signal.Notify(sigCh, os.Interrupt, syscall.SIGTERM)
+
+	startTime := time.Now()
+	var mu sync.Mutex
+	var wg sync.WaitGroup
+	for _, candidate := range run.Candidates {
+		out := &prefixWriter{
+			mu:     &mu,
+			out:    os.Stdout,
+			prefix: styles.InfoStyle.Render("["+candidate.Name+"]") + " ",
+		}
+
+		wg.Go(func() {
+			runCandidate(ctx, candidate, out, settings)
+		})
+	}
+
+	done := make(chan struct{})
+	go func() {
+		wg.Wait()
+		close(done)
+	}()
+
+	select {
+	case <-sigCh:
+		fmt.Println(styles.WarningStyle.Render("\n⚠ Received interrupt signal, cancelling runs..."))
+		signal.Stop(sigCh)
+		cancel()
+
+		// Set up force exit on second interrupt
+		go func() {
+			<-sigCh
+			fmt.Println(styles.ErrorStyle.Render("\n⚠ Second interrupt received, forcing exit..."))
+			os.Exit(exitCancelled)
+		}()
+		signal.Notify(sigCh, os.Interrupt, syscall.SIGTERM)
+
+		// Wait for the runs to finish cancelling
+		<-done
+	case <-done:
+	}
+
+	signal.Stop(sigCh)
+
+	// Ranking and cleanup still happen after an interrupt, so they use their own context
+	rankErr := run.Rank(context.Background(), loopConfig.VerifyCommand, runPreferSmallDiff)
+	var finishErr error
+	if rankErr == nil {
+		finishErr = run.Finish(context.Background())
+	}
+
+	printParallelSummary(run, startTime, rankErr, finishErr)
+
+	if rankErr != nil {
+		os.Exit(exitFailed)
+	}
+
+	os.Exit(exitCode(run.Winner().Result))
+
+	return nil
+}
+
+// runCandidate runs the loop of a single candidate and writes its events to out.
+func runCandidate(ctx context.Context, candidate *core.Candidate, out *prefixWriter, settings clientSettings) {
+	defer out.Flush()
+
+	sdkClient, err := createSDKClient(candidate.Config, settings)
+	if err != nil {
+		fmt.Fprintln(out, styles.ErrorStyle.Render(fmt.Sprintf("✗ Failed to create SDK client: %v", err)))
+		return
+	}
+	defer sdkClient.Stop()
+
+	if err := sdkClient.Start(); err != nil {
+		fmt.Fprintln(out, styles.ErrorStyle.Render(fmt.Sprintf("✗ Failed to start SDK client: %v", err)))
+		return
+	}
+
+	engine := core.NewLoopEngine(candidate.Config, sdkClient)
+
+	if candidate.Config.ReviewerModel != "" {
+		reviewer, err := createReviewerClient(candidate.Config, settings)
+		if err != nil {
+			fmt.Fprintln(out, styles.ErrorStyle.Render(fmt.Sprintf("✗ Failed to create reviewer client: %v", err)))
+			return
+		}
+		engine.SetReviewer(reviewer)
+	}
+
+	eventsDone := make(chan struct{})
+	go func() {
+		writeEvents(out, engine.Events(), candidate.Config)
+		close(eventsDone)
+	}()
+
+	candidate.Result, _ = engine.Start(ctx)
+
+	// Wait for events to finish displaying (with timeout to prevent hanging)
+	select {
+	case <-eventsDone:
+	case <-time.After(1 * time.Second):
+	}
+}
+
+// printParallelSummary displays the ranked candidates and which branch was checked out.
+func printParallelSummary(run *core.ParallelRun, startTime time.Time, rankErr, finishErr error) {
+	fmt.Println()
+	fmt.Println(styles.TitleStyle.Render("🏁 Parallel Summary"))
+	fmt.Println(styles.InfoStyle.Render("Run ID:     ") + run.ID)
+	fmt.Println(styles.InfoStyle.Render("Duration:   ") + time.Since(startTime).Round(time.Second).String())
+
+	for i, candidate := range run.Candidates {
+		marker := "  "
+		if i == 0 && rankErr == nil {
+			marker = styles.SuccessStyle.Render("★ ")
+		}
+
+		fmt.Println(marker + styles.InfoStyle.Render(candidate.Name+": ") + candidateSummary(candidate))
+	}
+
+	switch {
+	case rankErr != nil:
+		fmt.Println(styles.ErrorStyle.Render("Error:      ") + rankErr.Error())
+		fmt.Println(styles.InfoStyle.Render("Worktrees:  ") + "kept in " + run.WorktreesDir())
+	case finishErr != nil:
+		fmt.Println(styles.ErrorStyle.Render("Error:      ") + finishErr.Error())
+		fmt.Println(styles.InfoStyle.Render("Winner:     ") + "git switch " + run.Winner().Branch)
+	case !run.Winner().Verified:
+		fmt.Println(styles.WarningStyle.Render("Winner:     ") + "none verified, checkout left unchanged")
+		for _, candidate := range run.Candidates {
+			fmt.Println(styles.InfoStyle.Render("Branch:     ") + candidate.Branch)
+		}
+	default:
+		fmt.Println(styles.InfoStyle.Render("Winner:     ") + run.Winner().Name + ", checked out " + run.Winner().Branch)
+	}
+
+	fmt.Println()
+}
+
+// candidateSummary describes the outcome of a parallel run candidate,
+// e.g. "promise accepted in iteration 3, verified, 42 lines changed on ralph/x-1".
+func candidateSummary(candidate *core.Candidate) string {
+	result := candidate.Result
+	status := "not started"
+	switch {
+	case result == nil:
+	case result.PromiseReached():
+		status = fmt.Sprintf("promise accepted in iteration %d", result.PromiseIteration)
+	default:
+		status = fmt.Sprintf("%s after %d iterations", result.State, result.Iterations)
+	}
+
+	verified := "not verified"
+	if candidate.Verified {
+		verified = "verified"
+	}
+
+	return fmt.Sprintf("%s, %s, %d lines changed on %s", status, verified, candidate.DiffLines, candidate.Branch)
+}
@@ -0,0 +1,42 @@
+// Package cli implements the command-line interface for Ralph using Cobra.
+//
+// This file implements the tool policy of `ralph run --tool-policy`.
+//
+// See specs/cli.md for detailed CLI specification.
+package cli
+
+import (
+	"fmt"
+
+	"github.com/JanDeDobbeleer/copilot-ralph/internal/core"
+	"github.com/JanDeDobbeleer/copilot-ralph/internal/sdk"
+)
+
+// loadToolPolicy loads the tool policy of the loop, nil when none is configured.
+func loadToolPolicy(cfg *core.LoopConfig) (*sdk.ToolPolicy, error) {
+	if cfg.ToolPolicy == "" {
+		return nil, nil
+	}
+
+	return sdk.LoadToolPolicy(cfg.ToolPolicy)
+}
+
+// toolPolicyLabel describes the tool policy for display,
+// e.g. "policy.yaml (2 rules, default deny)".
+func toolPolicyLabel(cfg *core.LoopConfig, policy *sdk.ToolPolicy) string {
+	if policy == nil {
+		return "none, every tool is allowed"
+	}
+
+	rules := "rules"
+	if len(policy.Rules) == 1 {
+		rules = "rule"
+	}
+
+	return fmt.Sprintf("%s (%d %s, default %s)", cfg.ToolPolicy, len(policy.Rules), rules, policy.Default)
+}
+
+// toolRuleLabel describes a rule of the tool policy for display, e.g. "1. deny write".
+func toolRuleLabel(index int, rule *sdk.ToolRule) string {
+	return fmt.Sprintf("%d. %s", index+1, rule.String())
+}
@@ -0,0 +1,93 @@
+// Package cli implements the command-line interface for Ralph using Cobra.
+//
+// This file implements the `ralph resume` command for continuing interrupted loops.
+//
+// See specs/cli.md for detailed CLI specification.
+package cli
+
+import (
+	"fmt"
+	"path/filepath"
+	"time"
+
+	"github.com/spf13/cobra"
+
+	"github.com/JanDeDobbeleer/copilot-ralph/internal/core"
+	"github.com/JanDeDobbeleer/copilot-ralph/internal/tui/styles"
+)
+
+// resumeCmd represents the resume command
+var resumeCmd = &cobra.Command{
+	Use:   "resume [run-id]",
+	Short: "Resume an interrupted AI development loop",
+	Long: `Resume a loop that was cancelled, failed, or interrupted.
+
+Ralph saves a checkpoint to .ralph/ in the working directory after every
+iteration. Resuming continues with the remaining iteration and time budget
+of the original run. Without a run ID, the most recent run is resumed.
+
+Examples:
+  # Resume the most recent run
+  ralph resume
+
+  # Resume a specific run
+  ralph resume 20260101-120000-a1b2c3`,
+	Args: cobra.MaximumNArgs(1),
+	RunE: runResume,
+}
+
+var (
+	resumeWorkingDir string
+	resumeStreaming  bool
+	resumeLogLevel   string
+)
+
+func init() {
+	resumeCmd.Flags().StringVar(&resumeWorkingDir, "working-dir", ".", "working directory of the run to resume")
+	resumeCmd.Flags().BoolVar(&resumeStreaming, "streaming", true, "enable streaming responses")
+	resumeCmd.Flags().StringVar(&resumeLogLevel, "log-level", "info", "log level: debug, info, warn, error")
+}
+
+// runResume resumes the loop captured in a checkpoint.
+func runResume(cmd *cobra.Command, args []string) error {
+	var runID string
+	if len(args) > 0 {
+		runID = args[0]
+	}
+
+	checkpoint, err := loadResumableCheckpoint(resumeWorkingDir, runID)
+	if err != nil {
+		return err
+	}
+
+	printLoopConfig(checkpoint.Config)
+	printResumeInfo(checkpoint)
+
+	return executeLoop(checkpoint.Config, checkpoint, clientSettings{logLevel: resumeLogLevel, streaming: resumeStreaming})
+}
+
+// loadResumableCheckpoint loads the checkpoint of a run and ensures it can be continued.
+func loadResumableCheckpoint(workingDir, runID string) (*core.Checkpoint, error) {
+	checkpoint, err := core.LoadCheckpoint(filepath.Join(workingDir, core.StateDirName), runID)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := checkpoint.Resumable(); err != nil {
+		return nil, err
+	}
+
+	if err := validateRunConfig(checkpoint.Config); err != nil {
+		return nil, fmt.Errorf("invalid checkpoint configuration: %w", err)
+	}
+
+	return checkpoint, nil
+}
+
+// printResumeInfo displays where a resumed run picks up.
+func printResumeInfo(checkpoint *core.Checkpoint) {
+	fmt.Println(styles.WarningStyle.Render("Run ID:         ") + checkpoint.RunID)
+	fmt.Println(styles.WarningStyle.Render("Resuming after: ") + fmt.Sprintf("iteration %d (%s)", checkpoint.Iteration, checkpoint.State))
+	fmt.Println(styles.WarningStyle.Render("Remaining:      ") + fmt.Sprintf("%d iterations, %s",
+		checkpoint.RemainingIterations(), checkpoint.RemainingTime().Round(time.Second)))
+}
@@ -37,5 +37,6 @@ func init() {
 
 	// Add subcommands
 	rootCmd.AddCommand(runCmd)
+	rootCmd.AddCommand(resumeCmd)
 	rootCmd.AddCommand(versionCmd)
 }
@@ -9,9 +9,13 @@ import (
 	"context"
 	"errors"
 	"fmt"
+	"io"
+	"maps"
 	"os"
 	"os/signal"
 	"path/filepath"
+	"slices"
+	"strconv"
 	"strings"
 	"syscall"
 	"time"
@@ -31,6 +35,8 @@ const (
 	exitCancelled     = 2
 	exitTimeout       = 3
 	exitMaxIterations = 4
+	exitStagnated     = 5
+	exitBudget        = 6
 )
 
 // runCmd represents the run command
@@ -40,7 +46,8 @@ var runCmd = &cobra.Command{
 	Long: `Run an AI development loop with a prompt.
 
 The loop will iterate until the AI outputs the completion promise phrase,
-reaches the maximum iterations, or times out.
+reaches the maximum iterations, or times out. The process only exits with
+code 0 when the promise was accepted.
 
 Examples:
   # Direct prompt (required)
@@ -59,7 +66,19 @@ Examples:
   ralph run --dry-run "Update documentation"
 
   # Override promise phrase
-  ralph run --promise "Task complete!" "Fix bug"`,
+  ralph run --promise "Task complete!" "Fix bug"
+
+  # Template variables
+  ralph run --var pkg=parser "Add tests for {{.Vars.pkg}} ({{.Remaining}} left)"
+
+  # Multi-phase run defined in a plan file
+  ralph run --plan plan.yaml
+
+  # Run until every item in a Markdown task list is checked
+  ralph run --checklist TODO.md
+
+  # Best of three runs in separate git worktrees, ranked by a test command
+  ralph run --parallel 3 --verify "go test ./..." "Fix the flaky test"`,
 	Args: cobra.MaximumNArgs(1),
 	RunE: runLoop,
 }
@@ -67,6 +86,7 @@ Examples:
 var (
 	runMaxIterations    int
 	runTimeout          time.Duration
+	runIterTimeout      time.Duration
 	runPromise          string
 	runModel            string
 	runWorkingDir       string
@@ -75,36 +95,143 @@ var (
 	runSystemPrompt     string
 	runSystemPromptMode string
 	runLogLevel         string
+	runCompletion       string
+	runPromiseStreak    int
+	runVerify           string
+	runDiagnostics      []string
+	runVars             []string
+	runSummaryLimit     int
+	runRequestSummary   bool
+	runSession          string
+	runSessionRotate    int
+	runGitCommit        bool
+	runGitBranch        bool
+	runRollback         string
+	runStagnation       int
+	runStagnationPolicy string
+	runStagnationModel  string
+	runRepetition       int
+	runSimilarity       float64
+	runRepetitionAction string
+	runMaxTokens        int64
+	runMaxCost          float64
+	runPrices           []string
+	runPlan             string
+	runChecklist        string
+	runParallel         int
+	runFallbackModels   []string
+	runFallbackAfter    int
+	runReviewerModel    string
+	runRequireApproval  bool
+	runApprove          string
+	runToolPolicy       string
+	runMCPConfig        string
+	runPreferSmallDiff  bool
 )
 
 func init() {
 	runCmd.Flags().IntVarP(&runMaxIterations, "max-iterations", "m", 10, "maximum loop iterations")
 	runCmd.Flags().DurationVarP(&runTimeout, "timeout", "t", 30*time.Minute, "maximum loop runtime")
+	runCmd.Flags().DurationVar(&runIterTimeout, "iteration-timeout", 0, "maximum runtime of a single iteration before it is aborted and the loop continues (0 disables)")
 	runCmd.Flags().StringVar(&runPromise, "promise", "I'm special!", "completion promise phrase")
 	runCmd.Flags().StringVar(&runModel, "model", "gpt-4", "AI model to use")
+	runCmd.Flags().StringSliceVar(&runFallbackModels, "fallback-model", nil, "models to fall back to, in order, when the model keeps failing, is rate limited, refuses, or stagnates (comma-separated)")
+	runCmd.Flags().StringVar(&runReviewerModel, "reviewer-model", "", "model that reviews the diff and final message of every iteration; its critique feeds the next prompt")
+	runCmd.Flags().BoolVar(&runRequireApproval, "require-approval", false, "only accept the promise when the reviewer approves the same iteration (requires --reviewer-model)")
+	runCmd.Flags().StringVar(&runApprove, "approve", "never", "ask in the terminal before running tools: never, writes, shell (shell commands and writes), or all")
+	runCmd.Flags().StringVar(&runToolPolicy, "tool-policy", "", "YAML policy file of allowed and denied tools and argument patterns, enforced without asking")
+	runCmd.Flags().StringVar(&runMCPConfig, "mcp-config", "", "JSON file of MCP servers available to the model (default: .ralph/mcp.json in the working directory, if present)")
+	runCmd.Flags().IntVar(&runFallbackAfter, "fallback-after", 2, "failing iterations in a row before falling back to the next model")
 	runCmd.Flags().StringVar(&runWorkingDir, "working-dir", ".", "working directory for loop execution")
 	runCmd.Flags().BoolVar(&runDryRun, "dry-run", false, "show what would be executed without running")
 	runCmd.Flags().BoolVar(&runStreaming, "streaming", true, "enable streaming responses")
 	runCmd.Flags().StringVar(&runSystemPrompt, "system-prompt", "", "custom system message, can be a prompt or path to Markdown file")
 	runCmd.Flags().StringVar(&runSystemPromptMode, "system-prompt-mode", "append", "system message mode: append or replace")
 	runCmd.Flags().StringVar(&runLogLevel, "log-level", "info", "log level: debug, info, warn, error")
+	runCmd.Flags().StringVar(&runCompletion, "completion", "stop", "completion policy: stop, consecutive, or ignore")
+	runCmd.Flags().IntVar(&runPromiseStreak, "promise-streak", 2, "consecutive promises required by the consecutive completion policy")
+	runCmd.Flags().StringArrayVar(&runDiagnostics, "diagnostic", nil, "diagnostic command run after each iteration whose failures feed the next prompt (repeatable)")
+	runCmd.Flags().IntVar(&runSummaryLimit, "summary-limit", 2000, "maximum characters of the previous iteration summary carried into the next prompt (0 disables)")
+	runCmd.Flags().BoolVar(&runRequestSummary, "request-summary", false, "ask the model to end each iteration with a <summary> block")
+	runCmd.Flags().StringArrayVar(&runVars, "var", nil, "template variable as key=value, available as {{.Vars.key}} in prompts (repeatable)")
+	runCmd.Flags().StringVar(&runSession, "session", "persistent", "session strategy: persistent, fresh (new session every iteration), or rotate")
+	runCmd.Flags().IntVar(&runSessionRotate, "session-rotate-every", 5, "iterations per session when using the rotate session strategy")
+	runCmd.Flags().BoolVar(&runGitCommit, "git-commit", false, "commit the changes of every iteration to the local git repository")
+	runCmd.Flags().BoolVar(&runGitBranch, "git-branch", false, "commit iterations to a new ralph/<run-id> branch (implies --git-commit)")
+	runCmd.Flags().StringVar(&runRollback, "rollback", "", "check command run after every iteration; iterations that make it fail or report more failures are reverted (requires git)")
+	runCmd.Flags().IntVar(&runStagnation, "stagnation-limit", 0, "iterations without file changes before the stagnation policy applies (0 disables)")
+	runCmd.Flags().StringVar(&runStagnationPolicy, "stagnation-policy", "stop", "stagnation policy: stop, nudge, or switch-model")
+	runCmd.Flags().StringVar(&runStagnationModel, "stagnation-model", "", "model to switch to with the switch-model stagnation policy")
+	runCmd.Flags().IntVar(&runRepetition, "repetition-limit", 0, "identical failing tool calls before the model is corrected (0 disables)")
+	runCmd.Flags().Float64Var(&runSimilarity, "response-similarity", 0, "similarity from 0 to 1 at which a response counts as repeated (0 disables)")
+	runCmd.Flags().StringVar(&runRepetitionAction, "repetition-action", "hint", "on repetition: hint in the next prompt, or interrupt the iteration and hint")
+	runCmd.Flags().Int64Var(&runMaxTokens, "max-tokens", 0, "stop the loop once this many input and output tokens were used (0 disables)")
+	runCmd.Flags().Float64Var(&runMaxCost, "max-cost", 0, "stop the loop once the cost in USD exceeds this amount (0 disables)")
+	runCmd.Flags().StringArrayVar(&runPrices, "price", nil, "model price in USD per million tokens as model=input:output[:cache-read:cache-write] (repeatable)")
+	runCmd.Flags().StringVar(&runPlan, "plan", "", "plan file (YAML, or Markdown with YAML frontmatter) that splits the run into phases")
+	runCmd.Flags().StringVar(&runChecklist, "checklist", "", "Markdown task list; the run completes when every - [ ] item is checked instead of on the promise")
+	runCmd.Flags().IntVar(&runParallel, "parallel", 0, "run this many loops concurrently in separate git worktrees and keep the best branch (requires git)")
+	runCmd.Flags().BoolVar(&runPreferSmallDiff, "prefer-small-diff", false, "rank parallel runs with smaller diffs first among equally verified runs")
+	runCmd.Flags().StringVar(&runVerify, "verify", "", "command that must exit 0 before a promise is accepted (e.g. \"go test ./...\")")
 }
 
 // runLoop executes the AI development loop.
 func runLoop(cmd *cobra.Command, args []string) error {
 	// Resolve prompt from arguments, flag, or stdin
-	prompt, err := resolvePrompt(args[0])
-	if err != nil {
-		return err
+	var prompt string
+	if len(args) > 0 {
+		var err error
+		prompt, err = resolvePrompt(args[0])
+		if err != nil {
+			return err
+		}
 	}
 
-	// Require prompt
-	if prompt == "" {
+	// Require prompt, a plan or checklist can provide it instead
+	if prompt == "" && runPlan == "" && runChecklist == "" {
 		return errors.New("prompt is required (provide as argument or via stdin)")
 	}
 
 	// Build loop configuration from flags
-	loopConfig := buildLoopConfig(prompt)
+	loopConfig, err := buildLoopConfig(prompt)
+	if err != nil {
+		return err
+	}
+
+	if runPlan != "" {
+		plan, err := core.LoadPlan(runPlan)
+		if err != nil {
+			return err
+		}
+		plan.Apply(loopConfig)
+	}
+
+	if runChecklist != "" {
+		checklist, err := filepath.Abs(runChecklist)
+		if err != nil {
+			return fmt.Errorf("invalid checklist path: %w", err)
+		}
+
+		loopConfig.Checklist = checklist
+		if loopConfig.Prompt == "" {
+			loopConfig.Prompt = core.ChecklistPrompt(checklist)
+		}
+	}
+
+	if runToolPolicy != "" {
+		policy, err := filepath.Abs(runToolPolicy)
+		if err != nil {
+			return fmt.Errorf("invalid tool policy path: %w", err)
+		}
+
+		loopConfig.ToolPolicy = policy
+	}
+
+	mcpConfig, err := resolveMCPConfig(runMCPConfig, runWorkingDir)
+	if err != nil {
+		return err
+	}
+	loopConfig.MCPConfig = mcpConfig
 
 	// Validate configuration
 	if err := validateRunConfig(loopConfig); err != nil {
@@ -116,6 +243,21 @@ func runLoop(cmd *cobra.Command, args []string) error {
 		return err
 	}
 
+	// Validate prompt templates
+	if err := validateTemplates(loopConfig); err != nil {
+		return err
+	}
+
+	// Validate the tool policy
+	if _, err := loadToolPolicy(loopConfig); err != nil {
+		return err
+	}
+
+	// Validate the MCP servers
+	if _, err := loadMCPConfig(loopConfig); err != nil {
+		return err
+	}
+
 	// Handle dry run
 	if loopConfig.DryRun {
 		return printDryRun(loopConfig)
@@ -124,8 +266,25 @@ func runLoop(cmd *cobra.Command, args []string) error {
 	// Print configuration
 	printLoopConfig(loopConfig)
 
+	if runParallel > 1 {
+		return executeParallel(loopConfig, runParallel, runClientSettings())
+	}
+
+	return executeLoop(loopConfig, nil, runClientSettings())
+}
+
+// executeLoop runs the loop until it finishes and exits the process with the matching code.
+// When checkpoint is set, the run captured in it is resumed instead of starting a new one.
+func executeLoop(loopConfig *core.LoopConfig, checkpoint *core.Checkpoint, settings clientSettings) error {
+	// Typed lines steer the loop and answer approval prompts
+	terminal := newConsole(os.Stdin)
+	approve := loopConfig.Approve != "" && loopConfig.Approve != core.ApproveNever
+	if approve && !terminal.interactive() {
+		return errors.New("approve requires an interactive terminal")
+	}
+
 	// Create SDK client
-	sdkClient, err := createSDKClient(loopConfig)
+	sdkClient, err := createSDKClient(loopConfig, settings)
 	if err != nil {
 		return fmt.Errorf("failed to create SDK client: %w", err)
 	}
@@ -138,6 +297,21 @@ func runLoop(cmd *cobra.Command, args []string) error {
 
 	// Create loop engine
 	engine := core.NewLoopEngine(loopConfig, sdkClient)
+	if checkpoint != nil {
+		engine = core.ResumeLoopEngine(checkpoint, sdkClient)
+	}
+
+	if loopConfig.ReviewerModel != "" {
+		reviewer, err := createReviewerClient(loopConfig, settings)
+		if err != nil {
+			return fmt.Errorf("failed to create reviewer client: %w", err)
+		}
+		engine.SetReviewer(reviewer)
+	}
+
+	if approve {
+		engine.SetApprover(&terminalApprover{console: terminal})
+	}
 
 	// Set up signal handling for graceful shutdown
 	ctx, cancel := context.WithCancel(context.Background())
@@ -154,6 +328,9 @@ func runLoop(cmd *cobra.Command, args []string) error {
 		close(eventsDone)
 	}()
 
+	// Pause and resume on Enter or Ctrl+Z, steer with typed lines
+	go watchControls(ctx, engine, terminal)
+
 	// Start the loop in a goroutine
 	resultCh := make(chan *core.LoopResult, 1)
 	go func() {
@@ -201,34 +378,49 @@ func runLoop(cmd *cobra.Command, args []string) error {
 
 	// Print summary if we have a result
 	if result != nil {
-		printSummary(result, startTime)
+		printSummary(result, loopConfig, startTime)
 	}
 
 	// Always exit with appropriate code - never return to let Cobra continue
+	os.Exit(exitCode(result))
+
+	return nil
+}
+
+// exitCode maps a loop result to the process exit code.
+// A completed loop only exits successfully when the promise was accepted.
+func exitCode(result *core.LoopResult) int {
 	if result == nil {
-		os.Exit(exitCancelled)
+		return exitCancelled
 	}
 
 	switch result.State {
 	case core.StateComplete:
-		os.Exit(exitSuccess)
+		if !result.PromiseReached() {
+			return exitMaxIterations
+		}
+		return exitSuccess
 	case core.StateCancelled:
-		os.Exit(exitCancelled)
+		return exitCancelled
 	case core.StateFailed:
 		if result.Error != nil {
 			if errors.Is(result.Error, context.DeadlineExceeded) || errors.Is(result.Error, core.ErrLoopTimeout) {
-				os.Exit(exitTimeout)
+				return exitTimeout
 			}
 			if errors.Is(result.Error, core.ErrMaxIterations) {
-				os.Exit(exitMaxIterations)
+				return exitMaxIterations
+			}
+			if errors.Is(result.Error, core.ErrStagnation) {
+				return exitStagnated
+			}
+			if errors.Is(result.Error, core.ErrBudgetExceeded) {
+				return exitBudget
 			}
 		}
-		os.Exit(exitFailed)
+		return exitFailed
 	default:
-		os.Exit(exitFailed)
+		return exitFailed
 	}
-
-	return nil
 }
 
 // resolvePrompt determines the prompt from various sources.
@@ -265,21 +457,66 @@ func resolvePrompt(prompt string) (string, error) {
 }
 
 // buildLoopConfig creates a LoopConfig from command-line flags.
-func buildLoopConfig(prompt string) *core.LoopConfig {
-	return &core.LoopConfig{
-		Prompt:        prompt,
-		MaxIterations: runMaxIterations,
-		Timeout:       runTimeout,
-		PromisePhrase: runPromise,
-		Model:         runModel,
-		WorkingDir:    runWorkingDir,
-		DryRun:        runDryRun,
+// The working directory is made absolute and a system prompt file is read, so a
+// checkpoint of the run can be resumed from anywhere.
+func buildLoopConfig(prompt string) (*core.LoopConfig, error) {
+	workingDir, err := filepath.Abs(runWorkingDir)
+	if err != nil {
+		return nil, fmt.Errorf("invalid working directory: %w", err)
+	}
+
+	var systemPrompt string
+	if runSystemPrompt != "" {
+		systemPrompt, err = resolvePrompt(runSystemPrompt)
+		if err != nil {
+			return nil, err
+		}
 	}
+
+	return &core.LoopConfig{
+		Prompt:             prompt,
+		MaxIterations:      runMaxIterations,
+		Timeout:            runTimeout,
+		IterationTimeout:   runIterTimeout,
+		PromisePhrase:      runPromise,
+		Model:              runModel,
+		FallbackModels:     runFallbackModels,
+		FallbackAfter:      runFallbackAfter,
+		ReviewerModel:      runReviewerModel,
+		RequireApproval:    runRequireApproval,
+		Approve:            core.ApprovalMode(runApprove),
+		WorkingDir:         workingDir,
+		CheckpointDir:      filepath.Join(workingDir, core.StateDirName),
+		SystemPrompt:       systemPrompt,
+		SystemPromptMode:   runSystemPromptMode,
+		DryRun:             runDryRun,
+		VerifyCommand:      runVerify,
+		RollbackCommand:    runRollback,
+		Diagnostics:        runDiagnostics,
+		Vars:               parseVars(runVars),
+		SummaryLimit:       runSummaryLimit,
+		RequestSummary:     runRequestSummary,
+		CompletionPolicy:   core.CompletionPolicy(runCompletion),
+		PromiseStreak:      runPromiseStreak,
+		SessionStrategy:    core.SessionStrategy(runSession),
+		SessionRotateEvery: runSessionRotate,
+		StagnationLimit:    runStagnation,
+		StagnationPolicy:   core.StagnationPolicy(runStagnationPolicy),
+		StagnationModel:    runStagnationModel,
+		RepetitionLimit:    runRepetition,
+		ResponseSimilarity: runSimilarity,
+		RepetitionAction:   core.RepetitionAction(runRepetitionAction),
+		GitCommit:          runGitCommit || runGitBranch,
+		GitBranch:          runGitBranch,
+		MaxTokens:          runMaxTokens,
+		MaxCost:            runMaxCost,
+		Prices:             parsePrices(runPrices),
+	}, nil
 }
 
 // validateRunConfig validates the loop configuration.
 func validateRunConfig(cfg *core.LoopConfig) error {
-	if cfg.Prompt == "" {
+	if cfg.Prompt == "" && len(cfg.Phases) == 0 {
 		return errors.New("prompt cannot be empty")
 	}
 
@@ -291,6 +528,94 @@ func validateRunConfig(cfg *core.LoopConfig) error {
 		return fmt.Errorf("timeout must be positive (got: %v)", cfg.Timeout)
 	}
 
+	if cfg.Checklist != "" && len(cfg.Phases) > 0 {
+		return errors.New("checklist cannot be combined with a plan")
+	}
+
+	if cfg.IterationTimeout < 0 {
+		return fmt.Errorf("iteration-timeout cannot be negative (got: %v)", cfg.IterationTimeout)
+	}
+
+	if cfg.SummaryLimit < 0 {
+		return fmt.Errorf("summary-limit cannot be negative (got: %d)", cfg.SummaryLimit)
+	}
+
+	switch cfg.CompletionPolicy {
+	case "", core.CompletionStop, core.CompletionIgnore:
+	case core.CompletionConsecutive:
+		if cfg.PromiseStreak <= 0 {
+			return fmt.Errorf("promise-streak must be positive (got: %d)", cfg.PromiseStreak)
+		}
+	default:
+		return fmt.Errorf("invalid completion policy: %q (must be stop, consecutive, or ignore)", cfg.CompletionPolicy)
+	}
+
+	switch cfg.SessionStrategy {
+	case "", core.SessionPersistent, core.SessionFresh:
+	case core.SessionRotate:
+		if cfg.SessionRotateEvery <= 0 {
+			return fmt.Errorf("session-rotate-every must be positive (got: %d)", cfg.SessionRotateEvery)
+		}
+	default:
+		return fmt.Errorf("invalid session strategy: %q (must be persistent, fresh, or rotate)", cfg.SessionStrategy)
+	}
+
+	if cfg.StagnationLimit < 0 {
+		return fmt.Errorf("stagnation-limit cannot be negative (got: %d)", cfg.StagnationLimit)
+	}
+
+	switch cfg.StagnationPolicy {
+	case "", core.StagnationStop, core.StagnationNudge:
+	case core.StagnationSwitchModel:
+		if cfg.StagnationModel == "" {
+			return errors.New("stagnation-model is required by the switch-model stagnation policy")
+		}
+	default:
+		return fmt.Errorf("invalid stagnation policy: %q (must be stop, nudge, or switch-model)", cfg.StagnationPolicy)
+	}
+
+	if cfg.RepetitionLimit < 0 {
+		return fmt.Errorf("repetition-limit cannot be negative (got: %d)", cfg.RepetitionLimit)
+	}
+
+	if cfg.ResponseSimilarity < 0 || cfg.ResponseSimilarity > 1 {
+		return fmt.Errorf("response-similarity must be between 0 and 1 (got: %g)", cfg.ResponseSimilarity)
+	}
+
+	switch cfg.RepetitionAction {
+	case "", core.RepetitionHint, core.RepetitionInterrupt:
+	default:
+		return fmt.Errorf("invalid repetition action: %q (must be hint or interrupt)", cfg.RepetitionAction)
+	}
+
+	for _, model := range cfg.FallbackModels {
+		if strings.TrimSpace(model) == "" {
+			return errors.New("fallback-model cannot contain empty model names")
+		}
+	}
+
+	if len(cfg.FallbackModels) > 0 && cfg.FallbackAfter <= 0 {
+		return fmt.Errorf("fallback-after must be positive (got: %d)", cfg.FallbackAfter)
+	}
+
+	if cfg.RequireApproval && cfg.ReviewerModel == "" {
+		return errors.New("require-approval requires a reviewer-model")
+	}
+
+	switch cfg.Approve {
+	case "", core.ApproveNever, core.ApproveWrites, core.ApproveShell, core.ApproveAll:
+	default:
+		return fmt.Errorf("invalid approve mode: %q (must be never, writes, shell, or all)", cfg.Approve)
+	}
+
+	if cfg.MaxTokens < 0 {
+		return fmt.Errorf("max-tokens cannot be negative (got: %d)", cfg.MaxTokens)
+	}
+
+	if cfg.MaxCost < 0 {
+		return fmt.Errorf("max-cost cannot be negative (got: %g)", cfg.MaxCost)
+	}
+
 	return nil
 }
 
@@ -301,23 +626,377 @@ func validateSettings() error {
 		return fmt.Errorf("invalid system-prompt-mode: %q (must be append or replace)", runSystemPromptMode)
 	}
 
+	for _, v := range runVars {
+		if key, _, ok := strings.Cut(v, "="); !ok || key == "" {
+			return fmt.Errorf("invalid var: %q (must be key=value)", v)
+		}
+	}
+
+	for _, p := range runPrices {
+		if _, _, err := parsePrice(p); err != nil {
+			return err
+		}
+	}
+
+	if runParallel < 0 {
+		return fmt.Errorf("parallel cannot be negative (got: %d)", runParallel)
+	}
+
+	if runParallel > 1 && runApprove != "" && runApprove != string(core.ApproveNever) {
+		return errors.New("approve cannot be combined with parallel runs")
+	}
+
+	return nil
+}
+
+// parseVars converts key=value pairs into a template variable map.
+func parseVars(pairs []string) map[string]string {
+	vars := make(map[string]string, len(pairs))
+	for _, pair := range pairs {
+		key, value, _ := strings.Cut(pair, "=")
+		vars[key] = value
+	}
+
+	return vars
+}
+
+// parsePrices converts model=input:output[:cache-read:cache-write] pairs into a price table.
+func parsePrices(specs []string) map[string]core.ModelPrice {
+	prices := make(map[string]core.ModelPrice, len(specs))
+	for _, spec := range specs {
+		model, price, err := parsePrice(spec)
+		if err != nil {
+			continue
+		}
+		prices[model] = price
+	}
+
+	return prices
+}
+
+// parsePrice parses a single model=input:output[:cache-read:cache-write] price.
+func parsePrice(spec string) (string, core.ModelPrice, error) {
+	invalid := fmt.Errorf("invalid price: %q (must be model=input:output[:cache-read:cache-write])", spec)
+
+	model, values, ok := strings.Cut(spec, "=")
+	if !ok || model == "" {
+		return "", core.ModelPrice{}, invalid
+	}
+
+	fields := strings.Split(values, ":")
+	if len(fields) != 2 && len(fields) != 4 {
+		return "", core.ModelPrice{}, invalid
+	}
+
+	amounts := make([]float64, 4)
+	for i, field := range fields {
+		amount, err := strconv.ParseFloat(field, 64)
+		if err != nil || amount < 0 {
+			return "", core.ModelPrice{}, invalid
+		}
+		amounts[i] = amount
+	}
+
+	return model, core.ModelPrice{
+		Input:      amounts[0],
+		Output:     amounts[1],
+		CacheRead:  amounts[2],
+		CacheWrite: amounts[3],
+	}, nil
+}
+
+// validateTemplates renders the prompt and system prompt templates once so
+// template errors surface before the loop starts.
+func validateTemplates(cfg *core.LoopConfig) error {
+	if _, err := core.PreviewPrompt(cfg); err != nil {
+		return err
+	}
+
+	if _, _, err := buildSystemPrompt(cfg); err != nil {
+		return err
+	}
+
 	return nil
 }
 
 // printDryRun displays what would be executed without running.
 func printDryRun(cfg *core.LoopConfig) error {
+	policy, err := loadToolPolicy(cfg)
+	if err != nil {
+		return err
+	}
+
+	mcp, err := loadMCPConfig(cfg)
+	if err != nil {
+		return err
+	}
+
 	fmt.Println(styles.TitleStyle.Render("🔍 Dry Run - Configuration Preview"))
 	fmt.Println()
 	fmt.Println(styles.InfoStyle.Render("  Prompt:            ") + cfg.Prompt)
 	fmt.Println(styles.InfoStyle.Render("  Model:             ") + cfg.Model)
+	fmt.Println(styles.InfoStyle.Render("  Fallback:          ") + fallbackLabel(cfg))
 	fmt.Println(styles.InfoStyle.Render("  Max iterations:    ") + fmt.Sprintf("%d", cfg.MaxIterations))
-	fmt.Println(styles.InfoStyle.Render("  Timeout:           ") + cfg.Timeout.String())
+	fmt.Println(styles.InfoStyle.Render("  Timeout:           ") + timeoutLabel(cfg))
 	fmt.Println(styles.InfoStyle.Render("  Promise phrase:    ") + cfg.PromisePhrase)
+	for i, phase := range cfg.Phases {
+		fmt.Println(styles.InfoStyle.Render("  Phase:             ") + phaseLabel(cfg, i, &phase))
+	}
+	fmt.Println(styles.InfoStyle.Render("  Completion:        ") + completionLabel(cfg))
+	fmt.Println(styles.InfoStyle.Render("  Reviewer:          ") + reviewerLabel(cfg))
+	fmt.Println(styles.InfoStyle.Render("  Approve:           ") + approveLabel(cfg))
+	fmt.Println(styles.InfoStyle.Render("  Tool policy:       ") + toolPolicyLabel(cfg, policy))
+	if policy != nil {
+		for i := range policy.Rules {
+			fmt.Println(styles.InfoStyle.Render("  Tool rule:         ") + toolRuleLabel(i, &policy.Rules[i]))
+		}
+	}
+	if mcp != nil {
+		for _, name := range mcp.Names() {
+			fmt.Println(styles.InfoStyle.Render("  MCP server:        ") + mcpServerLabel(name, mcp.Servers[name]))
+		}
+	}
+	if cfg.Checklist != "" {
+		fmt.Println(styles.InfoStyle.Render("  Checklist:         ") + cfg.Checklist)
+	}
+	fmt.Println(styles.InfoStyle.Render("  Session:           ") + sessionLabel(cfg))
+	fmt.Println(styles.InfoStyle.Render("  Git:               ") + gitLabel(cfg))
+	if runParallel > 1 {
+		fmt.Println(styles.InfoStyle.Render("  Parallel:          ") + parallelLabel(cfg, runParallel, runPreferSmallDiff))
+	}
+	if cfg.VerifyCommand != "" {
+		fmt.Println(styles.InfoStyle.Render("  Verify command:    ") + cfg.VerifyCommand)
+	}
+	for _, command := range cfg.Diagnostics {
+		fmt.Println(styles.InfoStyle.Render("  Diagnostic:        ") + command)
+	}
+	if cfg.RollbackCommand != "" {
+		fmt.Println(styles.InfoStyle.Render("  Rollback check:    ") + cfg.RollbackCommand)
+	}
+	fmt.Println(styles.InfoStyle.Render("  Stagnation:        ") + stagnationLabel(cfg))
+	fmt.Println(styles.InfoStyle.Render("  Repetition:        ") + repetitionLabel(cfg))
+	fmt.Println(styles.InfoStyle.Render("  Budget:            ") + budgetLabel(cfg))
+	for _, model := range slices.Sorted(maps.Keys(cfg.Prices)) {
+		fmt.Println(styles.InfoStyle.Render("  Price:             ") + priceLabel(model, cfg.Prices[model]))
+	}
+	fmt.Println(styles.InfoStyle.Render("  Carried summary:   ") + summaryLabel(cfg))
+	for _, key := range slices.Sorted(maps.Keys(cfg.Vars)) {
+		fmt.Println(styles.InfoStyle.Render("  Var:               ") + key + "=" + cfg.Vars[key])
+	}
 	fmt.Println(styles.InfoStyle.Render("  Working directory: ") + cfg.WorkingDir)
 	fmt.Println()
+
+	prompt, err := core.PreviewPrompt(cfg)
+	if err != nil {
+		return err
+	}
+
+	systemPrompt, mode, err := buildSystemPrompt(cfg)
+	if err != nil {
+		return err
+	}
+
+	fmt.Println(styles.SubTitleStyle.Render("━━━ Iteration 1 prompt ━━━"))
+	fmt.Println(prompt)
+	fmt.Println()
+	fmt.Println(styles.SubTitleStyle.Render(fmt.Sprintf("━━━ System prompt (%s) ━━━", mode)))
+	fmt.Println(systemPrompt)
+	fmt.Println()
 	return nil
 }
 
+// phaseLabel describes a phase of a multi-phase run for display,
+// e.g. "1. plan (3 iterations, promise \"PLANNED\", model gpt-5)".
+func phaseLabel(cfg *core.LoopConfig, index int, phase *core.Phase) string {
+	promise := phase.Promise
+	if promise == "" {
+		promise = cfg.PromisePhrase
+	}
+
+	model := phase.Model
+	if model == "" {
+		model = cfg.Model
+	}
+
+	return fmt.Sprintf("%d. %s (%d iterations, promise %q, model %s)", index+1, phase.Name, phase.MaxIterations, promise, model)
+}
+
+// timeoutLabel describes the loop and iteration timeouts for display.
+func timeoutLabel(cfg *core.LoopConfig) string {
+	if cfg.IterationTimeout <= 0 {
+		return cfg.Timeout.String()
+	}
+
+	return fmt.Sprintf("%s (%s per iteration)", cfg.Timeout, cfg.IterationTimeout)
+}
+
+// summaryLabel describes how the previous iteration summary is carried over.
+func summaryLabel(cfg *core.LoopConfig) string {
+	if cfg.SummaryLimit <= 0 {
+		return "disabled"
+	}
+
+	label := fmt.Sprintf("up to %d characters", cfg.SummaryLimit)
+	if cfg.RequestSummary {
+		label += ", requested from the model"
+	}
+
+	return label
+}
+
+// completionLabel describes the completion policy for display.
+func completionLabel(cfg *core.LoopConfig) string {
+	switch cfg.CompletionPolicy {
+	case core.CompletionConsecutive:
+		return fmt.Sprintf("%s (%d in a row)", cfg.CompletionPolicy, cfg.PromiseStreak)
+	case "":
+		return core.CompletionStop.String()
+	default:
+		return cfg.CompletionPolicy.String()
+	}
+}
+
+// reviewerLabel describes the reviewer for display, e.g. "gpt-5 (approval required)".
+func reviewerLabel(cfg *core.LoopConfig) string {
+	if cfg.ReviewerModel == "" {
+		return "disabled"
+	}
+
+	if cfg.RequireApproval {
+		return cfg.ReviewerModel + " (approval required)"
+	}
+
+	return cfg.ReviewerModel
+}
+
+// sessionLabel describes the session strategy for display.
+func sessionLabel(cfg *core.LoopConfig) string {
+	switch cfg.SessionStrategy {
+	case core.SessionRotate:
+		return fmt.Sprintf("%s (every %d iterations)", cfg.SessionStrategy, cfg.SessionRotateEvery)
+	case "":
+		return core.SessionPersistent.String()
+	default:
+		return cfg.SessionStrategy.String()
+	}
+}
+
+// fallbackLabel describes the fallback chain for display,
+// e.g. "claude-sonnet → gpt-4.1 after 2 failing iterations".
+func fallbackLabel(cfg *core.LoopConfig) string {
+	if len(cfg.FallbackModels) == 0 {
+		return "disabled"
+	}
+
+	return fmt.Sprintf("%s after %d failing iterations", strings.Join(cfg.FallbackModels, " → "), cfg.FallbackAfter)
+}
+
+// stagnationLabel describes the stagnation policy for display.
+func stagnationLabel(cfg *core.LoopConfig) string {
+	if cfg.StagnationLimit <= 0 {
+		return "disabled"
+	}
+
+	policy := cfg.StagnationPolicy
+	if policy == "" {
+		policy = core.StagnationStop
+	}
+
+	label := fmt.Sprintf("%s after %d unchanged iterations", policy, cfg.StagnationLimit)
+	if policy == core.StagnationSwitchModel {
+		label += " (to " + cfg.StagnationModel + ")"
+	}
+
+	return label
+}
+
+// repetitionLabel describes the repetition detection for display.
+func repetitionLabel(cfg *core.LoopConfig) string {
+	var checks []string
+	if cfg.RepetitionLimit > 0 {
+		checks = append(checks, fmt.Sprintf("%d identical failing tool calls", cfg.RepetitionLimit))
+	}
+	if cfg.ResponseSimilarity > 0 {
+		checks = append(checks, fmt.Sprintf("responses %.0f%% similar", cfg.ResponseSimilarity*100))
+	}
+
+	if len(checks) == 0 {
+		return "disabled"
+	}
+
+	action := cfg.RepetitionAction
+	if action == "" {
+		action = core.RepetitionHint
+	}
+
+	return fmt.Sprintf("%s on %s", action, strings.Join(checks, " or "))
+}
+
+// gitLabel describes the git integration for display.
+func gitLabel(cfg *core.LoopConfig) string {
+	switch {
+	case cfg.GitBranch:
+		return "commit every iteration to a new " + core.GitBranchPrefix + "<run-id> branch"
+	case cfg.GitCommit:
+		return "commit every iteration to the current branch"
+	default:
+		return "disabled"
+	}
+}
+
+// parallelLabel describes a parallel run and how its candidates are ranked for display,
+// e.g. "3 runs in git worktrees, ranked by \"go test ./...\", then smaller diff".
+func parallelLabel(cfg *core.LoopConfig, runs int, preferSmallDiff bool) string {
+	ranking := "promise"
+	if cfg.VerifyCommand != "" {
+		ranking = strconv.Quote(cfg.VerifyCommand)
+	}
+
+	label := fmt.Sprintf("%d runs in git worktrees, ranked by %s", runs, ranking)
+	if preferSmallDiff {
+		label += ", then smaller diff"
+	}
+
+	return label
+}
+
+// budgetLabel describes the token and cost budgets for display.
+func budgetLabel(cfg *core.LoopConfig) string {
+	var budgets []string
+	if cfg.MaxTokens > 0 {
+		budgets = append(budgets, fmt.Sprintf("%d tokens", cfg.MaxTokens))
+	}
+	if cfg.MaxCost > 0 {
+		budgets = append(budgets, fmt.Sprintf("$%.2f", cfg.MaxCost))
+	}
+
+	if len(budgets) == 0 {
+		return "unlimited"
+	}
+
+	return strings.Join(budgets, ", ")
+}
+
+// priceLabel describes a model price for display.
+func priceLabel(model string, price core.ModelPrice) string {
+	label := fmt.Sprintf("%s $%g input, $%g output", model, price.Input, price.Output)
+	if price.CacheRead > 0 || price.CacheWrite > 0 {
+		label += fmt.Sprintf(", $%g cache read, $%g cache write", price.CacheRead, price.CacheWrite)
+	}
+
+	return label + " per million tokens"
+}
+
+// usageLabel describes token usage and cost for display, e.g. "1234 tokens ($0.0200)".
+func usageLabel(usage core.Usage) string {
+	label := fmt.Sprintf("%d tokens", usage.Tokens())
+	if usage.Cost > 0 {
+		label += fmt.Sprintf(" ($%.4f)", usage.Cost)
+	}
+
+	return label
+}
+
 // printLoopConfig displays the loop configuration before starting.
 func printLoopConfig(cfg *core.LoopConfig) {
 	// Print Ralph ASCII art
@@ -328,70 +1007,350 @@ func printLoopConfig(cfg *core.LoopConfig) {
 	fmt.Println(styles.TitleStyle.Render("▶ Starting Ralph Loop"))
 	fmt.Println(styles.WarningStyle.Render("Prompt:         ") + cfg.Prompt)
 	fmt.Println(styles.WarningStyle.Render("Model:          ") + cfg.Model)
+	if len(cfg.FallbackModels) > 0 {
+		fmt.Println(styles.WarningStyle.Render("Fallback:       ") + fallbackLabel(cfg))
+	}
 	fmt.Println(styles.WarningStyle.Render("Max iterations: ") + fmt.Sprintf("%d", cfg.MaxIterations))
-	fmt.Println(styles.WarningStyle.Render("Timeout:        ") + cfg.Timeout.String())
+	fmt.Println(styles.WarningStyle.Render("Timeout:        ") + timeoutLabel(cfg))
+	for i, phase := range cfg.Phases {
+		fmt.Println(styles.WarningStyle.Render("Phase:          ") + phaseLabel(cfg, i, &phase))
+	}
+	fmt.Println(styles.WarningStyle.Render("Completion:     ") + completionLabel(cfg))
+	if cfg.ReviewerModel != "" {
+		fmt.Println(styles.WarningStyle.Render("Reviewer:       ") + reviewerLabel(cfg))
+	}
+	if cfg.Approve != "" && cfg.Approve != core.ApproveNever {
+		fmt.Println(styles.WarningStyle.Render("Approve:        ") + approveLabel(cfg))
+	}
+	if cfg.ToolPolicy != "" {
+		fmt.Println(styles.WarningStyle.Render("Tool policy:    ") + cfg.ToolPolicy)
+	}
+	if mcp, err := loadMCPConfig(cfg); err == nil && mcp != nil {
+		for _, name := range mcp.Names() {
+			fmt.Println(styles.WarningStyle.Render("MCP server:     ") + mcpServerLabel(name, mcp.Servers[name]))
+		}
+	}
+	if cfg.Checklist != "" {
+		fmt.Println(styles.WarningStyle.Render("Checklist:      ") + cfg.Checklist)
+	}
+	fmt.Println(styles.WarningStyle.Render("Session:        ") + sessionLabel(cfg))
+	if cfg.GitCommit {
+		fmt.Println(styles.WarningStyle.Render("Git:            ") + gitLabel(cfg))
+	}
+	if runParallel > 1 {
+		fmt.Println(styles.WarningStyle.Render("Parallel:       ") + parallelLabel(cfg, runParallel, runPreferSmallDiff))
+	}
+	if cfg.VerifyCommand != "" {
+		fmt.Println(styles.WarningStyle.Render("Verify:         ") + cfg.VerifyCommand)
+	}
+	for _, command := range cfg.Diagnostics {
+		fmt.Println(styles.WarningStyle.Render("Diagnostic:     ") + command)
+	}
+	if cfg.RollbackCommand != "" {
+		fmt.Println(styles.WarningStyle.Render("Rollback:       ") + cfg.RollbackCommand)
+	}
+	if cfg.StagnationLimit > 0 {
+		fmt.Println(styles.WarningStyle.Render("Stagnation:     ") + stagnationLabel(cfg))
+	}
+	if cfg.RepetitionLimit > 0 || cfg.ResponseSimilarity > 0 {
+		fmt.Println(styles.WarningStyle.Render("Repetition:     ") + repetitionLabel(cfg))
+	}
+	if cfg.MaxTokens > 0 || cfg.MaxCost > 0 {
+		fmt.Println(styles.WarningStyle.Render("Budget:         ") + budgetLabel(cfg))
+	}
 	fmt.Println(styles.WarningStyle.Render("Working dir:    ") + cfg.WorkingDir)
 }
 
 // displayEvents listens for loop events and displays them to stdout.
-func displayEvents(events <-chan any, cfg *core.LoopConfig) {
+func displayEvents(events <-chan core.Event, cfg *core.LoopConfig) {
+	writeEvents(os.Stdout, events, cfg)
+}
+
+// writeEvents listens for loop events and writes them to out.
+func writeEvents(out io.Writer, events <-chan core.Event, cfg *core.LoopConfig) {
 	// var lastEvent any
 	var newline bool
+	// usage is the latest usage report, shown when its iteration completes
+	var usage *core.UsageEvent
 
 	for event := range events {
 		switch e := event.(type) {
 		case *core.LoopStartEvent:
-			fmt.Println()
-			fmt.Print(styles.TitleStyle.Render("▶ Loop started"))
+			fmt.Fprintln(out)
+			fmt.Fprint(out, styles.TitleStyle.Render("▶ Loop started"))
 
 		case *core.IterationStartEvent:
-			fmt.Println()
-			fmt.Println(styles.SubTitleStyle.Render(fmt.Sprintf("━━━ Iteration %d/%d ━━━", e.Iteration, cfg.MaxIterations)))
-			fmt.Println()
+			fmt.Fprintln(out)
+			fmt.Fprintln(out, styles.SubTitleStyle.Render(fmt.Sprintf("━━━ Iteration %d/%d ━━━", e.Iteration, cfg.MaxIterations)))
+			fmt.Fprintln(out)
 
 		case *core.AIResponseEvent:
 			// Print as we receive it for streaming effect
-			fmt.Print(e.Text)
+			fmt.Fprint(out, e.Text)
 
 		case *core.ToolExecutionStartEvent:
 			// Print newline if previous event was AI response
 			if newline {
-				fmt.Println()
+				fmt.Fprintln(out)
 			}
 
-			fmt.Println(styles.InfoStyle.Render(e.Info("🛠️")))
+			fmt.Fprintln(out, styles.InfoStyle.Render(e.Info("🛠️")))
 
 		case *core.ToolExecutionEvent:
 			if e.Error != nil {
 				err := styles.ErrorStyle.Render(fmt.Sprintf("(%s)", e.Error))
-				fmt.Printf("%s %s\n", e.Info("❌"), err)
+				fmt.Fprintf(out, "%s %s\n", e.Info("❌"), err)
 			} else {
-				fmt.Println(styles.SuccessStyle.Render(e.Info("✔️")))
+				fmt.Fprintln(out, styles.SuccessStyle.Render(e.Info("✔️")))
 			}
 
 		case *core.IterationCompleteEvent:
 			// Print newline if previous event was AI response
 			if newline {
-				fmt.Println()
+				fmt.Fprintln(out)
+			}
+
+			complete := fmt.Sprintf("✓ Iteration %d complete", e.Iteration)
+			if usage != nil && usage.Iteration == e.Iteration {
+				complete += fmt.Sprintf(" · %s total", usageLabel(usage.Total))
+			}
+
+			fmt.Fprintln(out, styles.InfoStyle.Render(complete))
+
+		case *core.ChecklistProgressEvent:
+			// Print newline if previous event was AI response
+			if newline {
+				fmt.Fprintln(out)
+			}
+
+			fmt.Fprintln(out, styles.InfoStyle.Render(fmt.Sprintf("☑ Checklist %s %d/%d", progressBar(e.Done, e.Total), e.Done, e.Total)))
+
+		case *core.PhaseStartEvent:
+			fmt.Fprintln(out)
+			fmt.Fprintln(out, styles.TitleStyle.Render(fmt.Sprintf("▶ Phase %d/%d: %s (%s)", e.Phase, e.Phases, e.Name, e.Model)))
+
+		case *core.PhaseCompleteEvent:
+			// Print newline if previous event was AI response
+			if newline {
+				fmt.Fprintln(out)
+			}
+
+			fmt.Fprintln(out, styles.SuccessStyle.Render(fmt.Sprintf("✓ Phase %d/%d: %s", e.Phase, e.Phases, phaseSummary(&e.Result))))
+
+		case *core.IterationTimeoutEvent:
+			// Print newline if previous event was AI response
+			if newline {
+				fmt.Fprintln(out)
 			}
 
-			fmt.Println(styles.InfoStyle.Render(fmt.Sprintf("✓ Iteration %d complete", e.Iteration)))
+			fmt.Fprintln(out, styles.WarningStyle.Render(fmt.Sprintf("⏱ Iteration %d timed out after %s, continuing with the next iteration", e.Iteration, e.Timeout)))
+
+		case *core.UsageEvent:
+			usage = e
+			continue
 
 		case *core.PromiseDetectedEvent:
 			// Print newline if previous event was AI response
 			if newline {
-				fmt.Println()
+				fmt.Fprintln(out)
+			}
+
+			fmt.Fprintln(out, styles.SuccessStyle.Render(fmt.Sprintf("🎉 Promise detected: \"%s\"", e.Phrase)))
+
+		case *core.VerificationFailedEvent:
+			// Print newline if previous event was AI response
+			if newline {
+				fmt.Fprintln(out)
+			}
+
+			fmt.Fprintln(out, styles.ErrorStyle.Render(fmt.Sprintf("✗ Promise rejected: %q exited with code %d", e.Command, e.ExitCode)))
+			if output := strings.TrimSpace(e.Output); output != "" {
+				fmt.Fprintln(out, output)
+			}
+
+		case *core.DiagnosticsEvent:
+			// Print newline if previous event was AI response
+			if newline {
+				fmt.Fprintln(out)
+			}
+
+			summary := fmt.Sprintf("🔬 Diagnostics: %d failing, %d passing", e.Failed, e.Passed)
+			if e.Failed > 0 {
+				fmt.Fprintln(out, styles.WarningStyle.Render(summary))
+				break
+			}
+
+			fmt.Fprintln(out, styles.SuccessStyle.Render(summary))
+
+		case *core.SessionRotatedEvent:
+			fmt.Fprintln(out, styles.InfoStyle.Render(fmt.Sprintf("🔄 New session for iteration %d (%s)", e.Iteration, e.Strategy)))
+
+		case *core.IterationRevertedEvent:
+			// Print newline if previous event was AI response
+			if newline {
+				fmt.Fprintln(out)
+			}
+
+			fmt.Fprintln(out, styles.WarningStyle.Render(fmt.Sprintf("↩ Iteration %d reverted: %s", e.Iteration, e.Reason)))
+
+		case *core.StagnationEvent:
+			// Print newline if previous event was AI response
+			if newline {
+				fmt.Fprintln(out)
+			}
+
+			message := fmt.Sprintf("⚠ No file changes in %d iterations", e.Iterations)
+			switch {
+			case e.Model != "":
+				message += ", switching to " + e.Model
+			case e.Policy == core.StagnationStop:
+				message += ", stopping"
+			default:
+				message += ", nudging the model"
+			}
+
+			fmt.Fprintln(out, styles.WarningStyle.Render(message))
+
+		case *core.ReviewStartEvent:
+			// Print newline if previous event was AI response
+			if newline {
+				fmt.Fprintln(out)
+			}
+
+			fmt.Fprintln(out, styles.SubTitleStyle.Render(fmt.Sprintf("━━━ Review of iteration %d (%s) ━━━", e.Iteration, e.Model)))
+
+		case *core.ReviewerResponseEvent:
+			// Print as we receive it for streaming effect
+			fmt.Fprint(out, e.Text)
+
+		case *core.ReviewCompleteEvent:
+			// Print newline if previous event was reviewer response
+			if newline {
+				fmt.Fprintln(out)
+			}
+
+			if e.Verdict == core.ReviewApprove {
+				fmt.Fprintln(out, styles.SuccessStyle.Render(fmt.Sprintf("🧐 Reviewer approved iteration %d", e.Iteration)))
+				break
+			}
+
+			fmt.Fprintln(out, styles.WarningStyle.Render(fmt.Sprintf("🧐 Reviewer requested changes to iteration %d", e.Iteration)))
+
+		case *core.ModelSwitchedEvent:
+			// Print newline if previous event was AI response
+			if newline {
+				fmt.Fprintln(out)
+			}
+
+			fmt.Fprintln(out, styles.WarningStyle.Render(fmt.Sprintf("🔀 Falling back from %s to %s (%s)", e.From, e.To, e.Reason)))
+
+		case *core.RepetitionDetectedEvent:
+			// Print newline if previous event was AI response
+			if newline {
+				fmt.Fprintln(out)
+			}
+
+			message := fmt.Sprintf("🔁 Repeated failing tool call (%dx): %s", e.Count, e.Detail)
+			if e.Repetition == core.RepetitionResponse {
+				message = fmt.Sprintf("🔁 Response %.0f%% similar to an earlier one", e.Similarity*100)
+			}
+			if e.Interrupted {
+				message += ", interrupting iteration"
+			}
+
+			fmt.Fprintln(out, styles.WarningStyle.Render(message))
+
+		case *core.GitCommitEvent:
+			// Print newline if previous event was AI response
+			if newline {
+				fmt.Fprintln(out)
+			}
+
+			if e.Commit == "" {
+				fmt.Fprintln(out, styles.InfoStyle.Render(fmt.Sprintf("📝 No changes to commit in iteration %d", e.Iteration)))
+				break
+			}
+
+			fmt.Fprintln(out, styles.InfoStyle.Render(fmt.Sprintf("📝 Committed iteration %d as %s", e.Iteration, shortCommit(e.Commit))))
+
+		case *core.LoopPausedEvent:
+			// Print newline if previous event was AI response
+			if newline {
+				fmt.Fprintln(out)
+			}
+
+			fmt.Fprintln(out, styles.WarningStyle.Render(fmt.Sprintf("⏸ Paused after iteration %d, press Enter or Ctrl+Z to resume", e.Iteration)))
+
+		case *core.LoopResumedEvent:
+			fmt.Fprintln(out, styles.InfoStyle.Render(fmt.Sprintf("▶ Resumed after %s", e.Paused.Round(time.Second))))
+
+		case *core.SteeringEvent:
+			// Print newline if previous event was AI response
+			if newline {
+				fmt.Fprintln(out)
+			}
+
+			if e.Immediate {
+				fmt.Fprintln(out, styles.InfoStyle.Render(fmt.Sprintf("🧭 Steering iteration %d: %s", e.Iteration, e.Message)))
+				break
+			}
+
+			fmt.Fprintln(out, styles.InfoStyle.Render(fmt.Sprintf("🧭 Steering queued for iteration %d: %s", e.Iteration, e.Message)))
+
+		case *core.ToolApprovalEvent:
+			switch e.Answer {
+			case core.ApprovalAllowAlways:
+				fmt.Fprintln(out, styles.SuccessStyle.Render(fmt.Sprintf("✓ Allowed %s for the rest of the run", e.Tool)))
+			case core.ApprovalAllowOnce:
+				fmt.Fprintln(out, styles.SuccessStyle.Render(fmt.Sprintf("✓ Allowed %s once", e.Tool)))
+			default:
+				fmt.Fprintln(out, styles.ErrorStyle.Render(fmt.Sprintf("✗ Denied %s, the model is told to find another way", e.Tool)))
+			}
+
+		case *core.ToolDeniedEvent:
+			// Print newline if previous event was AI response
+			if newline {
+				fmt.Fprintln(out)
+			}
+
+			denied := e.Tool
+			if e.Summary != "" {
+				denied = fmt.Sprintf("%s (%s)", e.Tool, truncateDetail(e.Summary))
 			}
 
-			fmt.Println(styles.SuccessStyle.Render(fmt.Sprintf("🎉 Promise detected: \"%s\"", e.Phrase)))
+			fmt.Fprintln(out, styles.ErrorStyle.Render(fmt.Sprintf("⛔ Tool policy denied %s by rule %q", denied, e.Rule)))
+
+		case *core.CompletionSignaledEvent:
+			// Print newline if previous event was AI response
+			if newline {
+				fmt.Fprintln(out)
+			}
+
+			fmt.Fprintln(out, styles.SuccessStyle.Render(fmt.Sprintf("🏁 Completion signaled: %s", e.Summary)))
+
+		case *core.BlockedEvent:
+			// Print newline if previous event was AI response
+			if newline {
+				fmt.Fprintln(out)
+			}
+
+			fmt.Fprintln(out, styles.WarningStyle.Render(fmt.Sprintf("🚧 Blocked in iteration %d: %s", e.Iteration, e.Reason)))
+
+		case *core.NoteEvent:
+			// Print newline if previous event was AI response
+			if newline {
+				fmt.Fprintln(out)
+			}
+
+			fmt.Fprintln(out, styles.InfoStyle.Render(fmt.Sprintf("📌 Note: %s", e.Text)))
 
 		case *core.ErrorEvent:
 			// Print newline if previous event was AI response
 			if newline {
-				fmt.Println()
+				fmt.Fprintln(out)
 			}
 
-			fmt.Println(styles.ErrorStyle.Render(fmt.Sprintf("✗ Error: %v", e.Error)))
+			fmt.Fprintln(out, styles.ErrorStyle.Render(fmt.Sprintf("✗ Error: %v", e.Error)))
 
 		case *core.LoopCompleteEvent:
 			// Will be handled by summary
@@ -404,19 +1363,24 @@ func displayEvents(events <-chan any, cfg *core.LoopConfig) {
 		case *core.LoopCancelledEvent:
 			// Print newline if previous event was AI response
 			if newline {
-				fmt.Println()
+				fmt.Fprintln(out)
 			}
 
-			fmt.Println(styles.WarningStyle.Render("⚠ Loop cancelled"))
+			fmt.Fprintln(out, styles.WarningStyle.Render("⚠ Loop cancelled"))
 			return
 		}
 
-		_, newline = event.(*core.AIResponseEvent)
+		switch event.(type) {
+		case *core.AIResponseEvent, *core.ReviewerResponseEvent:
+			newline = true
+		default:
+			newline = false
+		}
 	}
 }
 
 // printSummary displays the final loop summary.
-func printSummary(result *core.LoopResult, startTime time.Time) {
+func printSummary(result *core.LoopResult, cfg *core.LoopConfig, startTime time.Time) {
 	duration := time.Since(startTime)
 
 	fmt.Println()
@@ -436,41 +1400,245 @@ func printSummary(result *core.LoopResult, startTime time.Time) {
 	}
 
 	fmt.Println(styles.InfoStyle.Render("Status:     ") + status)
+	if result.RunID != "" {
+		fmt.Println(styles.InfoStyle.Render("Run ID:     ") + result.RunID)
+	}
 	fmt.Println(styles.InfoStyle.Render("Iterations: ") + fmt.Sprintf("%d", result.Iterations))
 	fmt.Println(styles.InfoStyle.Render("Duration:   ") + duration.Round(time.Second).String())
 
+	promise := styles.WarningStyle.Render(promiseStatus(result, cfg))
+	if result.PromiseReached() {
+		promise = styles.SuccessStyle.Render(promiseStatus(result, cfg))
+	}
+
+	fmt.Println(styles.InfoStyle.Render("Promise:    ") + promise)
+	fmt.Println(styles.InfoStyle.Render("Sessions:   ") + fmt.Sprintf("%d (%s)", result.Sessions, sessionLabel(cfg)))
+
+	if models := modelSummary(result.IterationModels); strings.Contains(models, "→") {
+		fmt.Println(styles.InfoStyle.Render("Models:     ") + models)
+	}
+
+	if result.ChecklistTotal > 0 {
+		fmt.Println(styles.InfoStyle.Render("Checklist:  ") + fmt.Sprintf("%d/%d items checked", result.ChecklistDone, result.ChecklistTotal))
+	}
+
+	for _, phase := range result.Phases {
+		fmt.Println(styles.InfoStyle.Render("Phase:      ") + phaseSummary(&phase))
+	}
+
+	if len(result.FailureHistory) > 0 {
+		fmt.Println(styles.InfoStyle.Render("Failures:   ") + failureCurve(result.FailureHistory))
+	}
+
+	if cfg.ReviewerModel != "" {
+		fmt.Println(styles.InfoStyle.Render("Approvals:  ") + fmt.Sprintf("%d of %d iterations", result.Approvals, result.Iterations))
+	}
+
+	if result.Reverts > 0 {
+		fmt.Println(styles.InfoStyle.Render("Reverts:    ") + fmt.Sprintf("%d", result.Reverts))
+	}
+
+	if result.Usage.Tokens() > 0 {
+		fmt.Println(styles.InfoStyle.Render("Tokens:     ") + fmt.Sprintf("%d input, %d output", result.Usage.InputTokens, result.Usage.OutputTokens))
+	}
+
+	if result.Usage.Cost > 0 {
+		fmt.Println(styles.InfoStyle.Render("Cost:       ") + fmt.Sprintf("$%.4f", result.Usage.Cost))
+	}
+
+	if result.StartCommit != "" {
+		fmt.Println(styles.InfoStyle.Render("Git:        ") + gitSummary(result))
+	}
+
 	if result.Error != nil {
 		fmt.Println(styles.ErrorStyle.Render("Error:      ") + result.Error.Error())
 	}
 
+	if code := exitCode(result); result.RunID != "" && (code == exitCancelled || code == exitFailed) {
+		fmt.Println(styles.InfoStyle.Render("Resume:     ") + "ralph resume " + result.RunID)
+	}
+
 	fmt.Println()
 }
 
+// promiseStatus describes what became of the completion promise, e.g. "accepted in iteration 3".
+// A promise that was detected but not accepted is only called ignored under the ignore policy.
+func promiseStatus(result *core.LoopResult, cfg *core.LoopConfig) string {
+	switch {
+	case result.PromiseReached():
+		return fmt.Sprintf("accepted in iteration %d", result.PromiseIteration)
+	case result.FirstPromiseIteration == 0:
+		return "not reached"
+	case cfg.CompletionPolicy == core.CompletionIgnore:
+		return fmt.Sprintf("ignored, first detected in iteration %d", result.FirstPromiseIteration)
+	default:
+		return fmt.Sprintf("detected but not accepted, first in iteration %d", result.FirstPromiseIteration)
+	}
+}
+
+// phaseSummary describes the outcome of a phase, e.g. "plan complete in 3 iterations (2m10s)".
+func phaseSummary(phase *core.PhaseResult) string {
+	if phase.Iterations == 0 {
+		return phase.Name + " not started"
+	}
+
+	status := "complete"
+	if !phase.Complete() {
+		status = "incomplete"
+	}
+
+	return fmt.Sprintf("%s %s in %d iterations (%s)", phase.Name, status, phase.Iterations, phase.Duration.Round(time.Second))
+}
+
+// modelSummary describes which models ran which iterations,
+// e.g. "gpt-5 (1-3) → claude-sonnet (4-6)".
+func modelSummary(models []string) string {
+	var spans []string
+	start := 0
+	for i := range models {
+		if i+1 < len(models) && models[i+1] == models[i] {
+			continue
+		}
+
+		span := fmt.Sprintf("%s (%d-%d)", models[i], start+1, i+1)
+		if start == i {
+			span = fmt.Sprintf("%s (%d)", models[i], i+1)
+		}
+
+		spans = append(spans, span)
+		start = i + 1
+	}
+
+	return strings.Join(spans, " → ")
+}
+
+// gitSummary describes the commits made during the loop, e.g. "3 commits on ralph/x since abc1234".
+func gitSummary(result *core.LoopResult) string {
+	summary := fmt.Sprintf("%d commits", result.Commits)
+	if result.Branch != "" {
+		summary += " on " + result.Branch
+	}
+
+	return summary + " since " + shortCommit(result.StartCommit)
+}
+
+// shortCommit abbreviates a commit hash for display.
+func shortCommit(commit string) string {
+	if len(commit) > 7 {
+		return commit[:7]
+	}
+	return commit
+}
+
+// progressBarWidth is the number of cells in a progress bar.
+const progressBarWidth = 20
+
+// progressBar renders done out of total as a bar, e.g. "[██████░░░░░░░░░░░░░░]".
+func progressBar(done, total int) string {
+	filled := 0
+	if total > 0 {
+		filled = min(done*progressBarWidth/total, progressBarWidth)
+	}
+
+	return "[" + strings.Repeat("█", filled) + strings.Repeat("░", progressBarWidth-filled) + "]"
+}
+
+// failureCurve renders the failure count per iteration, e.g. "14 → 6 → 0".
+func failureCurve(history []int) string {
+	counts := make([]string, 0, len(history))
+	for _, count := range history {
+		counts = append(counts, fmt.Sprintf("%d", count))
+	}
+
+	return strings.Join(counts, " → ")
+}
+
+// clientSettings holds the SDK client flags that are not part of the loop configuration,
+// so they are not stored in checkpoints and each command sets its own.
+type clientSettings struct {
+	logLevel  string
+	streaming bool
+}
+
+// runClientSettings returns the client settings of the run command flags.
+func runClientSettings() clientSettings {
+	return clientSettings{logLevel: runLogLevel, streaming: runStreaming}
+}
+
 // createSDKClient creates an SDK client with the given configuration.
-func createSDKClient(loopConfig *core.LoopConfig) (*sdk.CopilotClient, error) {
+func createSDKClient(loopConfig *core.LoopConfig, settings clientSettings) (*sdk.CopilotClient, error) {
 	opts := []sdk.ClientOption{
 		sdk.WithModel(loopConfig.Model),
 		sdk.WithWorkingDir(loopConfig.WorkingDir),
 		sdk.WithTimeout(loopConfig.Timeout),
-		sdk.WithStreaming(runStreaming),
-		sdk.WithLogLevel(runLogLevel),
+		sdk.WithStreaming(settings.streaming),
+		sdk.WithLogLevel(settings.logLevel),
 	}
 
-	// Build system prompt from template with user's task and promise phrase
-	systemPrompt := core.BuildSystemPrompt(loopConfig.PromisePhrase)
+	systemPrompt, mode, err := buildSystemPrompt(loopConfig)
+	if err != nil {
+		return nil, err
+	}
 
-	// Use the built-in system prompt, or override if user specified custom one
-	if runSystemPrompt != "" {
-		systemPrompt, err := resolvePrompt(runSystemPrompt)
-		if err != nil {
-			return nil, err
-		}
+	opts = append(opts, sdk.WithSystemMessage(systemPrompt, mode))
+
+	policy, err := loadToolPolicy(loopConfig)
+	if err != nil {
+		return nil, err
+	}
+
+	if policy != nil {
+		opts = append(opts, sdk.WithToolPolicy(policy))
+	}
+
+	mcp, err := loadMCPConfig(loopConfig)
+	if err != nil {
+		return nil, err
+	}
+
+	if mcp != nil {
+		opts = append(opts, sdk.WithMCPServers(mcp.Servers))
+	}
+
+	return sdk.NewCopilotClient(opts...)
+}
+
+// createReviewerClient creates the SDK client of the reviewer model.
+// The reviewer keeps the default system message, its instructions are part of every review prompt.
+// It is read-only: writes and shell commands are denied before the tool policy of the worker applies.
+func createReviewerClient(loopConfig *core.LoopConfig, settings clientSettings) (*sdk.CopilotClient, error) {
+	opts := []sdk.ClientOption{
+		sdk.WithModel(loopConfig.ReviewerModel),
+		sdk.WithWorkingDir(loopConfig.WorkingDir),
+		sdk.WithTimeout(loopConfig.Timeout),
+		sdk.WithStreaming(settings.streaming),
+		sdk.WithLogLevel(settings.logLevel),
+	}
 
-		opts = append(opts, sdk.WithSystemMessage(systemPrompt, runSystemPromptMode))
-	} else {
-		// Use the default system prompt template with "append" mode
-		opts = append(opts, sdk.WithSystemMessage(systemPrompt, "append"))
+	policy, err := loadToolPolicy(loopConfig)
+	if err != nil {
+		return nil, err
 	}
 
+	opts = append(opts, sdk.WithToolPolicy(policy.ReadOnly()))
+
 	return sdk.NewCopilotClient(opts...)
 }
+
+// buildSystemPrompt renders the system prompt and returns it with its mode.
+// The built-in template is appended to the SDK system message, unless the user
+// specified a custom one, which is rendered the same way and uses --system-prompt-mode.
+func buildSystemPrompt(cfg *core.LoopConfig) (string, string, error) {
+	if cfg.SystemPrompt == "" {
+		systemPrompt, err := core.BuildSystemPrompt(cfg)
+		return systemPrompt, "append", err
+	}
+
+	mode := cfg.SystemPromptMode
+	if mode == "" {
+		mode = "append"
+	}
+
+	systemPrompt, err := core.RenderSystemPrompt(cfg.SystemPrompt, cfg)
+	return systemPrompt, mode, err
+}
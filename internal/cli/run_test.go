@@ -2,8 +2,12 @@ package cli
 
 import (
 	"bytes"
+	"context"
 	"os"
 	"os/exec"
+	"path/filepath"
+	"strings"
+	"sync"
 	"testing"
 	"time"
 
@@ -28,7 +32,7 @@ func TestDisplayEventsAndPrints(t *testing.T) {
 	require.NoError(t, err)
 	os.Stdout = w
 
-	events := make(chan any, 20)
+	events := make(chan core.Event, 20)
 	cfg := &core.LoopConfig{MaxIterations: 5, PromisePhrase: "Done!"}
 
 	// Send a variety of events
@@ -43,6 +47,14 @@ func TestDisplayEventsAndPrints(t *testing.T) {
 		events <- &core.ToolExecutionEvent{ToolEvent: core.ToolEvent{ToolName: "fail", Iteration: 1}, Error: assert.AnError}
 		events <- &core.IterationCompleteEvent{Iteration: 1, Duration: time.Millisecond}
 		events <- &core.PromiseDetectedEvent{Phrase: "Done!"}
+		events <- &core.LoopPausedEvent{Iteration: 1}
+		events <- &core.LoopResumedEvent{Paused: 3 * time.Second, Iteration: 1}
+		events <- &core.SteeringEvent{Message: "use the parser", Iteration: 2}
+		events <- &core.ToolApprovalEvent{Tool: "shell", Answer: core.ApprovalDeny, Iteration: 1}
+		events <- &core.ToolDeniedEvent{Tool: "write", Summary: "go.mod", Rule: "deny write", Iteration: 1}
+		events <- &core.NoteEvent{Text: "kept the old parser", Iteration: 1}
+		events <- &core.BlockedEvent{Reason: "fixtures are missing", Iteration: 1}
+		events <- &core.CompletionSignaledEvent{Summary: "parser rewritten", Iteration: 1}
 		// Send cancelled to stop displayEvents
 		events <- &core.LoopCancelledEvent{}
 	}()
@@ -63,6 +75,14 @@ func TestDisplayEventsAndPrints(t *testing.T) {
 	assert.Contains(t, output, "Iteration 1/5")
 	assert.Contains(t, output, "Hello world")
 	assert.Contains(t, output, "Promise detected")
+	assert.Contains(t, output, "Paused after iteration 1")
+	assert.Contains(t, output, "Resumed after 3s")
+	assert.Contains(t, output, "Steering queued for iteration 2: use the parser")
+	assert.Contains(t, output, "Denied shell")
+	assert.Contains(t, output, `Tool policy denied write (go.mod) by rule "deny write"`)
+	assert.Contains(t, output, "Note: kept the old parser")
+	assert.Contains(t, output, "Blocked in iteration 1: fixtures are missing")
+	assert.Contains(t, output, "Completion signaled: parser rewritten")
 }
 
 func TestPrintLoopConfigAndSummary(t *testing.T) {
@@ -75,9 +95,9 @@ func TestPrintLoopConfigAndSummary(t *testing.T) {
 	cfg := &core.LoopConfig{Prompt: "task", Model: "gpt-4", MaxIterations: 2, Timeout: 5 * time.Minute, PromisePhrase: "Done!", WorkingDir: "."}
 	printLoopConfig(cfg)
 
-	result := &core.LoopResult{State: core.StateComplete, Iterations: 2}
+	result := &core.LoopResult{State: core.StateComplete, Iterations: 2, Sessions: 2, Reverts: 1}
 	start := time.Now().Add(-2 * time.Second)
-	printSummary(result, start)
+	printSummary(result, cfg, start)
 
 	w.Close()
 	os.Stdout = oldStdout
@@ -89,6 +109,8 @@ func TestPrintLoopConfigAndSummary(t *testing.T) {
 	assert.Contains(t, out, "Starting Ralph Loop")
 	assert.Contains(t, out, "Loop Summary")
 	assert.Contains(t, out, "Iterations:")
+	assert.Contains(t, out, "Sessions:   2 (persistent)")
+	assert.Contains(t, out, "Reverts:    1")
 }
 
 func TestCreateSDKClientReturnsClient(t *testing.T) {
@@ -113,9 +135,152 @@ func TestCreateSDKClientReturnsClient(t *testing.T) {
 	runSystemPromptMode = "append"
 
 	cfg := &core.LoopConfig{Prompt: "task", PromisePhrase: "I'm special!", Model: "gpt-test", Timeout: 30 * time.Second, MaxIterations: 1}
-	client, err := createSDKClient(cfg)
+	client, err := createSDKClient(cfg, runClientSettings())
 	require.NoError(t, err)
 	require.NotNil(t, client)
 	// Client Model should match
 	assert.Equal(t, "gpt-test", client.Model())
 }
+
+func TestFailureCurve(t *testing.T) {
+	assert.Equal(t, "14 → 6 → 0", failureCurve([]int{14, 6, 0}))
+	assert.Equal(t, "3", failureCurve([]int{3}))
+}
+
+func TestParseVars(t *testing.T) {
+	vars := parseVars([]string{"pkg=parser", "query=a=b", "empty="})
+
+	assert.Equal(t, map[string]string{"pkg": "parser", "query": "a=b", "empty": ""}, vars)
+}
+
+func TestValidateTemplates(t *testing.T) {
+	cfg := &core.LoopConfig{Prompt: "Fix {{.Vars.pkg}}", PromisePhrase: "done", MaxIterations: 1, Vars: map[string]string{"pkg": "parser"}}
+
+	require.NoError(t, validateTemplates(cfg))
+
+	cfg.SystemPrompt = "You work on {{.Vars.missing}}"
+	require.Error(t, validateTemplates(cfg))
+
+	cfg.SystemPrompt = ""
+	cfg.Prompt = "Fix {{.Vars.missing}}"
+	require.Error(t, validateTemplates(cfg))
+}
+
+func TestLoadResumableCheckpoint(t *testing.T) {
+	dir := t.TempDir()
+	cfg := &core.LoopConfig{
+		Prompt:        "task",
+		PromisePhrase: "done",
+		MaxIterations: 3,
+		Timeout:       time.Minute,
+		CheckpointDir: filepath.Join(dir, core.StateDirName),
+	}
+
+	engine := core.NewLoopEngine(cfg, nil)
+	go func() {
+		for range engine.Events() {
+		}
+	}()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	_, _ = engine.Start(ctx)
+
+	checkpoint, err := loadResumableCheckpoint(dir, "")
+	require.NoError(t, err)
+	assert.Equal(t, engine.RunID(), checkpoint.RunID)
+	assert.Equal(t, core.StateCancelled, checkpoint.State)
+	assert.Equal(t, 3, checkpoint.RemainingIterations())
+
+	_, err = loadResumableCheckpoint(t.TempDir(), "")
+	assert.ErrorIs(t, err, core.ErrNoCheckpoint)
+}
+
+func TestGitSummary(t *testing.T) {
+	result := &core.LoopResult{StartCommit: "0123456789abcdef", Commits: 3}
+	assert.Equal(t, "3 commits since 0123456", gitSummary(result))
+
+	result.Branch = "ralph/run"
+	assert.Equal(t, "3 commits on ralph/run since 0123456", gitSummary(result))
+}
+
+func TestRepetitionLabel(t *testing.T) {
+	assert.Equal(t, "disabled", repetitionLabel(&core.LoopConfig{}))
+	assert.Equal(t, "hint on 3 identical failing tool calls", repetitionLabel(&core.LoopConfig{RepetitionLimit: 3}))
+	assert.Equal(t, "interrupt on 3 identical failing tool calls or responses 90% similar",
+		repetitionLabel(&core.LoopConfig{RepetitionLimit: 3, ResponseSimilarity: 0.9, RepetitionAction: core.RepetitionInterrupt}))
+}
+
+func TestPhaseLabelAndSummary(t *testing.T) {
+	cfg := &core.LoopConfig{PromisePhrase: "Done!", Model: "gpt-4"}
+	phase := &core.Phase{Name: "plan", MaxIterations: 3, Model: "gpt-5"}
+	assert.Equal(t, `1. plan (3 iterations, promise "Done!", model gpt-5)`, phaseLabel(cfg, 0, phase))
+
+	result := &core.PhaseResult{Name: "plan", Iterations: 3, PromiseIteration: 3, Duration: 130 * time.Second}
+	assert.Equal(t, "plan complete in 3 iterations (2m10s)", phaseSummary(result))
+	assert.Equal(t, "implement not started", phaseSummary(&core.PhaseResult{Name: "implement"}))
+}
+
+func TestProgressBar(t *testing.T) {
+	assert.Equal(t, "["+strings.Repeat("░", 20)+"]", progressBar(0, 4))
+	assert.Equal(t, "["+strings.Repeat("█", 5)+strings.Repeat("░", 15)+"]", progressBar(1, 4))
+	assert.Equal(t, "["+strings.Repeat("█", 20)+"]", progressBar(4, 4))
+	assert.Equal(t, "["+strings.Repeat("░", 20)+"]", progressBar(0, 0))
+}
+
+func TestReviewerLabel(t *testing.T) {
+	assert.Equal(t, "disabled", reviewerLabel(&core.LoopConfig{}))
+	assert.Equal(t, "gpt-5", reviewerLabel(&core.LoopConfig{ReviewerModel: "gpt-5"}))
+	assert.Equal(t, "gpt-5 (approval required)", reviewerLabel(&core.LoopConfig{ReviewerModel: "gpt-5", RequireApproval: true}))
+}
+
+func TestModelSummary(t *testing.T) {
+	assert.Empty(t, modelSummary(nil))
+	assert.Equal(t, "gpt-5 (1-3)", modelSummary([]string{"gpt-5", "gpt-5", "gpt-5"}))
+	assert.Equal(t, "gpt-5 (1-2) → claude-sonnet (3) → gpt-4.1 (4-5)",
+		modelSummary([]string{"gpt-5", "gpt-5", "claude-sonnet", "gpt-4.1", "gpt-4.1"}))
+}
+
+func TestFallbackLabel(t *testing.T) {
+	assert.Equal(t, "disabled", fallbackLabel(&core.LoopConfig{}))
+	assert.Equal(t, "claude-sonnet → gpt-4.1 after 2 failing iterations",
+		fallbackLabel(&core.LoopConfig{FallbackModels: []string{"claude-sonnet", "gpt-4.1"}, FallbackAfter: 2}))
+}
+
+func TestPrefixWriter(t *testing.T) {
+	var mu sync.Mutex
+	var out bytes.Buffer
+	first := &prefixWriter{mu: &mu, out: &out, prefix: "[run 1] "}
+	second := &prefixWriter{mu: &mu, out: &out, prefix: "[run 2] "}
+
+	_, err := first.Write([]byte("Streaming "))
+	require.NoError(t, err)
+	_, err = second.Write([]byte("other\nlines\n"))
+	require.NoError(t, err)
+	_, err = first.Write([]byte("text\npartial"))
+	require.NoError(t, err)
+	require.NoError(t, first.Flush())
+	require.NoError(t, second.Flush())
+
+	assert.Equal(t, "[run 2] other\n[run 2] lines\n[run 1] Streaming text\n[run 1] partial\n", out.String())
+}
+
+func TestParallelLabel(t *testing.T) {
+	assert.Equal(t, "3 runs in git worktrees, ranked by promise", parallelLabel(&core.LoopConfig{}, 3, false))
+	assert.Equal(t, `2 runs in git worktrees, ranked by "go test ./...", then smaller diff`,
+		parallelLabel(&core.LoopConfig{VerifyCommand: "go test ./..."}, 2, true))
+}
+
+func TestCandidateSummary(t *testing.T) {
+	candidate := &core.Candidate{Branch: "ralph/x-1"}
+	assert.Equal(t, "not started, not verified, 0 lines changed on ralph/x-1", candidateSummary(candidate))
+
+	candidate.Result = &core.LoopResult{State: core.StateComplete, Iterations: 3, PromiseIteration: 3}
+	candidate.Verified = true
+	candidate.DiffLines = 42
+	assert.Equal(t, "promise accepted in iteration 3, verified, 42 lines changed on ralph/x-1", candidateSummary(candidate))
+
+	candidate.Result = &core.LoopResult{State: core.StateFailed, Iterations: 5}
+	candidate.Verified = false
+	assert.Equal(t, "failed after 5 iterations, not verified, 42 lines changed on ralph/x-1", candidateSummary(candidate))
+}
@@ -0,0 +1,185 @@
+// Package core provides interactive approval and policy denials of tool executions.
+
+package core
+
+import (
+	"fmt"
+
+	"github.com/JanDeDobbeleer/copilot-ralph/internal/sdk"
+)
+
+// ApprovalMode decides which tool executions need the user's approval.
+type ApprovalMode string
+
+const (
+	// ApproveNever runs every tool without asking.
+	ApproveNever ApprovalMode = "never"
+	// ApproveWrites asks before writing files.
+	ApproveWrites ApprovalMode = "writes"
+	// ApproveShell asks before running shell commands and writing files.
+	ApproveShell ApprovalMode = "shell"
+	// ApproveAll asks before running any tool.
+	ApproveAll ApprovalMode = "all"
+)
+
+// String returns the string representation of the mode.
+func (m ApprovalMode) String() string {
+	return string(m)
+}
+
+// Requires reports whether a tool execution of the given kind needs approval.
+func (m ApprovalMode) Requires(kind sdk.PermissionKind) bool {
+	switch m {
+	case ApproveAll:
+		return true
+	case ApproveShell:
+		return kind == sdk.PermissionShell || kind == sdk.PermissionWrite
+	case ApproveWrites:
+		return kind == sdk.PermissionWrite
+	default:
+		return false
+	}
+}
+
+// ApprovalAnswer is the user's answer when asked whether a tool may run.
+type ApprovalAnswer string
+
+const (
+	// ApprovalAllowOnce allows this execution of the tool.
+	ApprovalAllowOnce ApprovalAnswer = "once"
+	// ApprovalAllowAlways allows this and every later execution of the tool in the run.
+	ApprovalAllowAlways ApprovalAnswer = "always"
+	// ApprovalDeny denies the execution, the model is told the user refused it.
+	ApprovalDeny ApprovalAnswer = "deny"
+)
+
+// String returns the string representation of the answer.
+func (a ApprovalAnswer) String() string {
+	return string(a)
+}
+
+// Allowed reports whether the answer lets the tool run.
+func (a ApprovalAnswer) Allowed() bool {
+	return a == ApprovalAllowOnce || a == ApprovalAllowAlways
+}
+
+// ToolApprover asks the user whether a tool may run.
+type ToolApprover interface {
+	// ApproveTool asks about a single tool execution.
+	ApproveTool(request sdk.PermissionRequest) ApprovalAnswer
+}
+
+// toolDeniedNote tells the model a tool execution was refused.
+const toolDeniedNote = "The user denied running %s in iteration %d. " +
+	"Do not retry it; find another way to make progress, or explain in your response why it is needed."
+
+// toolPolicyNote tells the model the tool policy refused a tool execution.
+const toolPolicyNote = "The tool policy denied running %s in iteration %d (rule: %s). " +
+	"Do not retry it; stay within what the policy allows, or explain in your response why it is needed."
+
+// SetApprover configures who is asked before tools run, according to the approval mode.
+// It must be called before Start.
+func (e *LoopEngine) SetApprover(approver ToolApprover) {
+	e.approver = approver
+}
+
+// gateTools makes the SDK client, and the reviewer client if any, ask for approval before running tools.
+func (e *LoopEngine) gateTools() error {
+	if e.approver == nil || e.config.Approve == "" || e.config.Approve == ApproveNever {
+		return nil
+	}
+
+	gate, ok := e.sdk.(PermissionGate)
+	if !ok {
+		return fmt.Errorf("SDK client cannot ask for approval with mode %s", e.config.Approve)
+	}
+
+	gate.SetPermissionHandler(e.approveTool)
+
+	if e.reviewer == nil {
+		return nil
+	}
+
+	reviewerGate, ok := e.reviewer.(PermissionGate)
+	if !ok {
+		return fmt.Errorf("reviewer client cannot ask for approval with mode %s", e.config.Approve)
+	}
+
+	reviewerGate.SetPermissionHandler(e.approveReviewerTool)
+
+	return nil
+}
+
+// approveTool decides whether a tool may run, asking the approver when the approval mode requires it.
+// Denials are carried into the next prompt.
+func (e *LoopEngine) approveTool(request sdk.PermissionRequest) bool {
+	allowed, iteration := e.askApproval(request)
+	if !allowed {
+		e.carry(fmt.Sprintf(toolDeniedNote, toolDescription(request.Tool(), request.Summary()), iteration))
+	}
+
+	return allowed
+}
+
+// approveReviewerTool decides whether a tool of the reviewer may run, like approveTool.
+// Denials are not carried, the worker did not ask for the tool.
+func (e *LoopEngine) approveReviewerTool(request sdk.PermissionRequest) bool {
+	allowed, _ := e.askApproval(request)
+	return allowed
+}
+
+// askApproval asks the approver about a tool execution when the approval mode requires it
+// and emits the answer. It reports whether the tool may run and the current iteration.
+func (e *LoopEngine) askApproval(request sdk.PermissionRequest) (bool, int) {
+	if !e.config.Approve.Requires(request.Kind) {
+		return true, 0
+	}
+
+	tool := request.Tool()
+
+	e.mu.RLock()
+	always := e.alwaysAllowed[tool]
+	iteration := e.iteration
+	e.mu.RUnlock()
+
+	if always {
+		return true, iteration
+	}
+
+	answer := e.approver.ApproveTool(request)
+	if !answer.Allowed() {
+		answer = ApprovalDeny
+	}
+
+	if answer == ApprovalAllowAlways {
+		e.mu.Lock()
+		if e.alwaysAllowed == nil {
+			e.alwaysAllowed = make(map[string]bool)
+		}
+		e.alwaysAllowed[tool] = true
+		e.mu.Unlock()
+	}
+
+	e.emit(NewToolApprovalEvent(tool, request.Summary(), answer, iteration))
+
+	return answer.Allowed(), iteration
+}
+
+// denyTool reports a tool execution the tool policy of the SDK client refused
+// and carries the denial into the next prompt.
+func (e *LoopEngine) denyTool(denied *sdk.ToolDeniedEvent, iteration int) {
+	tool := denied.Request.Tool()
+	summary := denied.Request.Summary()
+
+	e.emit(NewToolDeniedEvent(tool, summary, denied.Rule, iteration))
+	e.carry(fmt.Sprintf(toolPolicyNote, toolDescription(tool, summary), iteration, denied.Rule))
+}
+
+// toolDescription names a tool execution for the model, e.g. "shell (go test ./...)".
+func toolDescription(tool, summary string) string {
+	if summary == "" {
+		return tool
+	}
+
+	return fmt.Sprintf("%s (%s)", tool, summary)
+}
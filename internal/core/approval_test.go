@@ -0,0 +1,195 @@
+package core
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/JanDeDobbeleer/copilot-ralph/internal/sdk"
+)
+
+// scriptedApprover answers approval requests in order and records them.
+type scriptedApprover struct {
+	answers  []ApprovalAnswer
+	requests []sdk.PermissionRequest
+}
+
+// ApproveTool implements ToolApprover.
+func (a *scriptedApprover) ApproveTool(request sdk.PermissionRequest) ApprovalAnswer {
+	a.requests = append(a.requests, request)
+	answer := a.answers[0]
+	a.answers = a.answers[1:]
+	return answer
+}
+
+func TestApprovalMode_Requires(t *testing.T) {
+	tests := []struct {
+		mode ApprovalMode
+		want []sdk.PermissionKind
+	}{
+		{mode: ApproveNever},
+		{mode: ApproveWrites, want: []sdk.PermissionKind{sdk.PermissionWrite}},
+		{mode: ApproveShell, want: []sdk.PermissionKind{sdk.PermissionShell, sdk.PermissionWrite}},
+		{mode: ApproveAll, want: []sdk.PermissionKind{sdk.PermissionShell, sdk.PermissionWrite, sdk.PermissionRead, sdk.PermissionMCP, sdk.PermissionURL}},
+	}
+
+	kinds := []sdk.PermissionKind{sdk.PermissionShell, sdk.PermissionWrite, sdk.PermissionRead, sdk.PermissionMCP, sdk.PermissionURL}
+	for _, tt := range tests {
+		t.Run(tt.mode.String(), func(t *testing.T) {
+			var got []sdk.PermissionKind
+			for _, kind := range kinds {
+				if tt.mode.Requires(kind) {
+					got = append(got, kind)
+				}
+			}
+			assert.Equal(t, tt.want, got)
+		})
+	}
+}
+
+func TestLoopEngine_ApproveTools(t *testing.T) {
+	shell := sdk.PermissionRequest{Kind: sdk.PermissionShell, Arguments: map[string]any{"fullCommandText": "go test ./..."}}
+	write := sdk.PermissionRequest{Kind: sdk.PermissionWrite, Arguments: map[string]any{"fileName": "main.go"}}
+	read := sdk.PermissionRequest{Kind: sdk.PermissionRead, Arguments: map[string]any{"path": "go.mod"}}
+
+	approver := &scriptedApprover{answers: []ApprovalAnswer{ApprovalAllowAlways, ApprovalDeny}}
+	mockSDK := NewMockSDKClient()
+
+	var allowed []bool
+	mockSDK.OnPrompt = func(string) {
+		if len(allowed) > 0 {
+			return
+		}
+
+		for _, request := range []sdk.PermissionRequest{shell, shell, write, read} {
+			allowed = append(allowed, mockSDK.PermissionHandler(request))
+		}
+	}
+
+	config := &LoopConfig{Prompt: "Test task", MaxIterations: 2, PromisePhrase: "done", Approve: ApproveShell}
+	engine := NewLoopEngine(config, mockSDK)
+	engine.SetApprover(approver)
+
+	var approvals []*ToolApprovalEvent
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		for event := range engine.Events() {
+			if ev, ok := event.(*ToolApprovalEvent); ok {
+				approvals = append(approvals, ev)
+			}
+		}
+	}()
+
+	_, err := engine.Start(context.Background())
+	<-done
+	require.NoError(t, err)
+
+	// Always allowing the shell covers the second command, reads need no approval
+	assert.Equal(t, []bool{true, true, false, true}, allowed)
+	assert.Equal(t, []sdk.PermissionRequest{shell, write}, approver.requests)
+
+	require.Len(t, approvals, 2)
+	assert.Equal(t, ApprovalAllowAlways, approvals[0].Answer)
+	assert.Equal(t, "go test ./...", approvals[0].Summary)
+	assert.Equal(t, ApprovalDeny, approvals[1].Answer)
+	assert.Equal(t, "write", approvals[1].Tool)
+
+	require.Len(t, mockSDK.Prompts, 2)
+	assert.Contains(t, mockSDK.Prompts[1], "The user denied running write (main.go) in iteration 1")
+}
+
+func TestLoopEngine_ToolDeniedByPolicy(t *testing.T) {
+	mockSDK := NewMockSDKClient()
+	mockSDK.Denied = sdk.NewToolDeniedEvent(
+		sdk.PermissionRequest{Kind: sdk.PermissionWrite, Arguments: map[string]any{"fileName": "go.mod"}},
+		"deny write",
+	)
+
+	config := &LoopConfig{Prompt: "Test task", MaxIterations: 2, PromisePhrase: "done"}
+	engine := NewLoopEngine(config, mockSDK)
+
+	var denials []*ToolDeniedEvent
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		for event := range engine.Events() {
+			if ev, ok := event.(*ToolDeniedEvent); ok {
+				denials = append(denials, ev)
+			}
+		}
+	}()
+
+	_, err := engine.Start(context.Background())
+	<-done
+	require.NoError(t, err)
+
+	require.Len(t, denials, 2)
+	assert.Equal(t, "write", denials[0].Tool)
+	assert.Equal(t, "go.mod", denials[0].Summary)
+	assert.Equal(t, "deny write", denials[0].Rule)
+	assert.Equal(t, 1, denials[0].Iteration)
+
+	require.Len(t, mockSDK.Prompts, 2)
+	assert.Contains(t, mockSDK.Prompts[1], "The tool policy denied running write (go.mod) in iteration 1 (rule: deny write)")
+}
+
+func TestLoopEngine_ReviewerToolPermissions(t *testing.T) {
+	write := sdk.PermissionRequest{Kind: sdk.PermissionWrite, Arguments: map[string]any{"fileName": "main.go"}}
+
+	approver := &scriptedApprover{answers: []ApprovalAnswer{ApprovalDeny}}
+	worker := NewMockSDKClient()
+
+	reviewer := NewMockSDKClient()
+	reviewer.ResponseText = "<verdict>request-changes</verdict>"
+	reviewer.Denied = sdk.NewToolDeniedEvent(
+		sdk.PermissionRequest{Kind: sdk.PermissionShell, Arguments: map[string]any{"fullCommandText": "rm -rf ."}},
+		"deny shell",
+	)
+
+	var allowed []bool
+	reviewer.OnPrompt = func(string) {
+		if len(allowed) == 0 {
+			allowed = append(allowed, reviewer.PermissionHandler(write))
+		}
+	}
+
+	config := &LoopConfig{Prompt: "Test task", MaxIterations: 2, PromisePhrase: "done", WorkingDir: t.TempDir(), Approve: ApproveWrites}
+	engine := NewLoopEngine(config, worker)
+	engine.SetReviewer(reviewer)
+	engine.SetApprover(approver)
+
+	var approvals []*ToolApprovalEvent
+	var denials []*ToolDeniedEvent
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		for event := range engine.Events() {
+			switch ev := event.(type) {
+			case *ToolApprovalEvent:
+				approvals = append(approvals, ev)
+			case *ToolDeniedEvent:
+				denials = append(denials, ev)
+			}
+		}
+	}()
+
+	_, err := engine.Start(context.Background())
+	<-done
+	require.NoError(t, err)
+
+	// The reviewer asks the same approver, its denials are not the worker's business
+	assert.Equal(t, []bool{false}, allowed)
+	assert.Equal(t, []sdk.PermissionRequest{write}, approver.requests)
+	require.Len(t, approvals, 1)
+	assert.Equal(t, ApprovalDeny, approvals[0].Answer)
+
+	require.Len(t, denials, 2)
+	assert.Equal(t, "deny shell", denials[0].Rule)
+	assert.Equal(t, "rm -rf .", denials[0].Summary)
+
+	require.Len(t, worker.Prompts, 2)
+	assert.NotContains(t, worker.Prompts[1], "denied running")
+}
@@ -0,0 +1,161 @@
+// Package core provides checklist driven completion for the loop engine.
+
+package core
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"regexp"
+	"strings"
+)
+
+// ErrChecklistItemRemoved indicates checklist items were deleted instead of checked.
+var ErrChecklistItemRemoved = errors.New("checklist items removed")
+
+// checklistItemPattern matches Markdown task list items such as "- [ ] Add tests".
+var checklistItemPattern = regexp.MustCompile(`^\s*[-*+] \[([ xX])\] (.+?)\s*$`)
+
+// checklistInstructions is the loop prompt of a checklist run without a prompt.
+const checklistInstructions = "Work through the task list in %s. " +
+	"Check off each item (`- [x]`) in the file as soon as it is done. Do not remove or reword items."
+
+// ChecklistItem is a single Markdown task list item.
+type ChecklistItem struct {
+	// Text is the item description without the checkbox.
+	Text string
+	// Done is true when the box is checked.
+	Done bool
+}
+
+// parseChecklist returns the task list items in content.
+func parseChecklist(content string) []ChecklistItem {
+	var items []ChecklistItem
+	for line := range strings.Lines(content) {
+		match := checklistItemPattern.FindStringSubmatch(line)
+		if match == nil {
+			continue
+		}
+
+		items = append(items, ChecklistItem{Text: match[2], Done: match[1] != " "})
+	}
+
+	return items
+}
+
+// readChecklist reads and parses the checklist file.
+func readChecklist(path string) ([]ChecklistItem, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read checklist: %w", err)
+	}
+
+	return parseChecklist(string(data)), nil
+}
+
+// ChecklistPrompt returns the loop prompt for a checklist run without a prompt.
+func ChecklistPrompt(path string) string {
+	return fmt.Sprintf(checklistInstructions, path)
+}
+
+// checklistDone counts the checked items.
+func checklistDone(items []ChecklistItem) int {
+	done := 0
+	for _, item := range items {
+		if item.Done {
+			done++
+		}
+	}
+
+	return done
+}
+
+// removedItems returns the items of previous that no longer exist in current.
+func removedItems(previous, current []ChecklistItem) []string {
+	remaining := make(map[string]int, len(current))
+	for _, item := range current {
+		remaining[item.Text]++
+	}
+
+	var removed []string
+	for _, item := range previous {
+		if remaining[item.Text] == 0 {
+			removed = append(removed, item.Text)
+			continue
+		}
+		remaining[item.Text]--
+	}
+
+	return removed
+}
+
+// loadChecklist records the checklist items before the first iteration.
+func (e *LoopEngine) loadChecklist() error {
+	if e.config.Checklist == "" {
+		return nil
+	}
+
+	items, err := readChecklist(e.config.Checklist)
+	if err != nil {
+		return err
+	}
+
+	if len(items) == 0 {
+		return fmt.Errorf("checklist %s has no task list items (- [ ] ...)", e.config.Checklist)
+	}
+
+	e.mu.Lock()
+	e.checklist = items
+	e.mu.Unlock()
+
+	return nil
+}
+
+// checkChecklist re-reads the checklist after an iteration and reports its progress.
+// It returns true when every item is checked, and an error wrapping
+// ErrChecklistItemRemoved when items were deleted instead of checked.
+func (e *LoopEngine) checkChecklist(iteration int) (bool, error) {
+	if e.config.Checklist == "" {
+		return false, nil
+	}
+
+	items, err := readChecklist(e.config.Checklist)
+	if err != nil {
+		return false, err
+	}
+
+	e.mu.Lock()
+	removed := removedItems(e.checklist, items)
+	if len(removed) == 0 {
+		e.checklist = items
+	}
+	e.mu.Unlock()
+
+	if len(removed) > 0 {
+		return false, fmt.Errorf("%w from %s: %s", ErrChecklistItemRemoved, e.config.Checklist, strings.Join(removed, "; "))
+	}
+
+	done := checklistDone(items)
+	e.emit(NewChecklistProgressEvent(done, len(items), iteration))
+
+	return len(items) > 0 && done == len(items), nil
+}
+
+// checklistSection re-reads the checklist and lists the remaining items for the next prompt.
+func (e *LoopEngine) checklistSection() (string, error) {
+	items, err := readChecklist(e.config.Checklist)
+	if err != nil {
+		return "", err
+	}
+
+	var builder strings.Builder
+	builder.WriteString(fmt.Sprintf("## Checklist (%d/%d done)\n\n", checklistDone(items), len(items)))
+	builder.WriteString(fmt.Sprintf("The run is complete once every item in %s is checked. Remaining items:\n\n", e.config.Checklist))
+	for _, item := range items {
+		if !item.Done {
+			builder.WriteString("- [ ] " + item.Text + "\n")
+		}
+	}
+
+	return builder.String(), nil
+}
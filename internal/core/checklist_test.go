@@ -0,0 +1,108 @@
+package core
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseChecklist(t *testing.T) {
+	content := "# TODO\n\n- [ ] Add tests\n- [x] Fix parser\n  * [X] Nested item\n- not a task\n-[ ] missing space\n"
+
+	assert.Equal(t, []ChecklistItem{
+		{Text: "Add tests"},
+		{Text: "Fix parser", Done: true},
+		{Text: "Nested item", Done: true},
+	}, parseChecklist(content))
+}
+
+func TestRemovedItems(t *testing.T) {
+	previous := []ChecklistItem{{Text: "a"}, {Text: "b"}, {Text: "b"}}
+
+	assert.Empty(t, removedItems(previous, []ChecklistItem{{Text: "b", Done: true}, {Text: "a"}, {Text: "b"}, {Text: "c"}}))
+	assert.Equal(t, []string{"b"}, removedItems(previous, []ChecklistItem{{Text: "a"}, {Text: "b"}}))
+}
+
+func TestLoopEngine_Checklist(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "TODO.md")
+	require.NoError(t, os.WriteFile(path, []byte("- [ ] one\n- [ ] two\n"), 0o644))
+
+	mockSDK := NewMockSDKClient()
+	mockSDK.PromisePhrase = "done"
+	mockSDK.SimulatePromise = true
+	mockSDK.OnPrompt = func(prompt string) {
+		content, err := os.ReadFile(path)
+		require.NoError(t, err)
+		// Check the first open item
+		require.NoError(t, os.WriteFile(path, []byte(strings.Replace(string(content), "- [ ]", "- [x]", 1)), 0o644))
+	}
+
+	config := &LoopConfig{
+		Prompt:        ChecklistPrompt(path),
+		MaxIterations: 5,
+		PromisePhrase: "done",
+		Checklist:     path,
+	}
+	engine := NewLoopEngine(config, mockSDK)
+
+	var progress []*ChecklistProgressEvent
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		for event := range engine.Events() {
+			if ev, ok := event.(*ChecklistProgressEvent); ok {
+				progress = append(progress, ev)
+			}
+		}
+	}()
+
+	result, err := engine.Start(context.Background())
+	<-done
+	require.NoError(t, err)
+
+	// The promise in every response is ignored until all items are checked
+	assert.Equal(t, 2, result.PromiseIteration)
+	assert.Equal(t, 2, result.ChecklistDone)
+	assert.Equal(t, 2, result.ChecklistTotal)
+
+	require.Len(t, progress, 2)
+	assert.Equal(t, 1, progress[0].Done)
+	assert.Equal(t, 2, progress[0].Total)
+
+	require.Len(t, mockSDK.Prompts, 2)
+	assert.Contains(t, mockSDK.Prompts[0], "## Checklist (0/2 done)")
+	assert.Contains(t, mockSDK.Prompts[1], "## Checklist (1/2 done)")
+	assert.Contains(t, mockSDK.Prompts[1], "- [ ] two")
+	assert.NotContains(t, mockSDK.Prompts[1], "- [ ] one")
+}
+
+func TestLoopEngine_ChecklistItemsRemoved(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "TODO.md")
+	require.NoError(t, os.WriteFile(path, []byte("- [ ] one\n- [ ] two\n"), 0o644))
+
+	mockSDK := NewMockSDKClient()
+	mockSDK.OnPrompt = func(string) {
+		require.NoError(t, os.WriteFile(path, []byte("- [x] one\n"), 0o644))
+	}
+
+	config := &LoopConfig{
+		Prompt:        "Work through TODO.md",
+		MaxIterations: 5,
+		PromisePhrase: "done",
+		Checklist:     path,
+	}
+	engine := NewLoopEngine(config, mockSDK)
+	drainEvents(engine)
+
+	result, err := engine.Start(context.Background())
+	require.ErrorIs(t, err, ErrChecklistItemRemoved)
+	assert.Contains(t, err.Error(), "two")
+	assert.Equal(t, StateFailed, result.State)
+	assert.Equal(t, 1, result.Iterations)
+}
@@ -0,0 +1,309 @@
+// Package core provides checkpointing so interrupted loops can be resumed.
+
+package core
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"time"
+)
+
+// StateDirName is the directory in the working directory where Ralph keeps run state.
+const StateDirName = ".ralph"
+
+// MCPConfigFileName is the project MCP configuration in the state directory.
+// Unlike run state it is meant to be committed, so the state directory does not ignore it.
+const MCPConfigFileName = "mcp.json"
+
+// stateDirIgnore is the .gitignore of the state directory, ignoring everything but the MCP configuration.
+const stateDirIgnore = "*\n!" + MCPConfigFileName + "\n"
+
+// checkpointExt is the file extension of checkpoint files.
+const checkpointExt = ".json"
+
+// ErrNoCheckpoint indicates no checkpoint was found to resume from.
+var ErrNoCheckpoint = errors.New("no checkpoint found")
+
+// Checkpoint is the resumable state of a loop, persisted after each iteration.
+type Checkpoint struct {
+	UpdatedAt        time.Time          `json:"updated_at"`
+	Config           *LoopConfig        `json:"config"`
+	Diagnostics      *DiagnosticsReport `json:"diagnostics,omitempty"`
+	RunID            string             `json:"run_id"`
+	State            LoopState          `json:"state"`
+	StartCommit      string             `json:"start_commit,omitempty"`
+	Branch           string             `json:"branch,omitempty"`
+	LastOutcome      string             `json:"last_outcome,omitempty"`
+	PreviousSummary  string             `json:"previous_summary,omitempty"`
+	CarryOver        []string           `json:"carry_over,omitempty"`
+	FailureHistory   []int              `json:"failure_history,omitempty"`
+	IterationUsage   []Usage            `json:"iteration_usage,omitempty"`
+	IterationModels  []string           `json:"iteration_models,omitempty"`
+	Phases           []PhaseResult      `json:"phases,omitempty"`
+	Usage            Usage              `json:"usage"`
+	Elapsed          time.Duration      `json:"elapsed"`
+	Iteration        int                `json:"iteration"`
+	Phase            int                `json:"phase"`
+	Fallback         int                `json:"fallback"`
+	Sessions         int                `json:"sessions"`
+	Commits          int                `json:"commits"`
+	Reverts          int                `json:"reverts"`
+	Approvals        int                `json:"approvals"`
+	PromiseStreak    int                `json:"promise_streak"`
+	PromiseIteration int                `json:"promise_iteration"`
+	FirstPromise     int                `json:"first_promise_iteration"`
+}
+
+// RemainingIterations returns the number of iterations left in the budget.
+func (c *Checkpoint) RemainingIterations() int {
+	return max(c.Config.MaxIterations-c.Iteration, 0)
+}
+
+// RemainingTime returns the time left in the budget.
+func (c *Checkpoint) RemainingTime() time.Duration {
+	return max(c.Config.Timeout-c.Elapsed, 0)
+}
+
+// Resumable reports whether the run can be continued.
+func (c *Checkpoint) Resumable() error {
+	if c.State == StateComplete {
+		return fmt.Errorf("run %s is already complete", c.RunID)
+	}
+
+	if c.Config.MaxIterations > 0 && c.RemainingIterations() == 0 {
+		return fmt.Errorf("run %s has no iterations left (%d/%d)", c.RunID, c.Iteration, c.Config.MaxIterations)
+	}
+
+	if c.Config.Timeout > 0 && c.RemainingTime() == 0 {
+		return fmt.Errorf("run %s has no time left (%s/%s)", c.RunID, c.Elapsed.Round(time.Second), c.Config.Timeout)
+	}
+
+	return nil
+}
+
+// LoadCheckpoint reads the checkpoint of the given run from dir.
+// When runID is empty, the most recently updated checkpoint is returned.
+func LoadCheckpoint(dir, runID string) (*Checkpoint, error) {
+	if runID == "" {
+		return latestCheckpoint(dir)
+	}
+
+	checkpoint, err := readCheckpoint(filepath.Join(dir, runID+checkpointExt))
+	if errors.Is(err, os.ErrNotExist) {
+		return nil, fmt.Errorf("%w for run %s in %s", ErrNoCheckpoint, runID, dir)
+	}
+
+	return checkpoint, err
+}
+
+// latestCheckpoint returns the most recently updated checkpoint in dir.
+func latestCheckpoint(dir string) (*Checkpoint, error) {
+	entries, err := os.ReadDir(dir)
+	if err != nil && !errors.Is(err, os.ErrNotExist) {
+		return nil, fmt.Errorf("failed to read checkpoint directory: %w", err)
+	}
+
+	var latest *Checkpoint
+	for _, entry := range entries {
+		if entry.IsDir() || filepath.Ext(entry.Name()) != checkpointExt {
+			continue
+		}
+
+		checkpoint, err := readCheckpoint(filepath.Join(dir, entry.Name()))
+		if err != nil {
+			return nil, err
+		}
+
+		if latest == nil || checkpoint.UpdatedAt.After(latest.UpdatedAt) {
+			latest = checkpoint
+		}
+	}
+
+	if latest == nil {
+		return nil, fmt.Errorf("%w in %s", ErrNoCheckpoint, dir)
+	}
+
+	return latest, nil
+}
+
+// readCheckpoint decodes a single checkpoint file.
+func readCheckpoint(path string) (*Checkpoint, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+
+	var checkpoint Checkpoint
+	if err := json.Unmarshal(data, &checkpoint); err != nil {
+		return nil, fmt.Errorf("invalid checkpoint %s: %w", path, err)
+	}
+
+	if checkpoint.Config == nil || checkpoint.RunID == "" {
+		return nil, fmt.Errorf("invalid checkpoint %s: missing run ID or config", path)
+	}
+
+	// The checkpoint directory is wherever the checkpoint was found
+	checkpoint.Config.CheckpointDir = filepath.Dir(path)
+
+	return &checkpoint, nil
+}
+
+// writeCheckpoint atomically persists the checkpoint to dir.
+func writeCheckpoint(dir string, checkpoint *Checkpoint) error {
+	if err := ensureStateDir(dir); err != nil {
+		return err
+	}
+
+	data, err := json.MarshalIndent(checkpoint, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to encode checkpoint: %w", err)
+	}
+
+	path := filepath.Join(dir, checkpoint.RunID+checkpointExt)
+	tmp := path + ".tmp"
+	if err := os.WriteFile(tmp, data, 0o644); err != nil {
+		return fmt.Errorf("failed to write checkpoint: %w", err)
+	}
+
+	if err := os.Rename(tmp, path); err != nil {
+		return fmt.Errorf("failed to write checkpoint: %w", err)
+	}
+
+	return nil
+}
+
+// ResumeLoopEngine creates a loop engine that continues the run captured in the checkpoint.
+// The remaining iteration and time budget is derived from the checkpoint.
+func ResumeLoopEngine(checkpoint *Checkpoint, sdk SDKClient) *LoopEngine {
+	engine := newLoopEngine(checkpoint.Config, sdk, checkpoint.RunID)
+	engine.elapsedBefore = checkpoint.Elapsed
+	engine.iteration = checkpoint.Iteration
+	engine.sessions = checkpoint.Sessions
+	engine.startCommit = checkpoint.StartCommit
+	engine.branch = checkpoint.Branch
+	engine.commits = checkpoint.Commits
+	engine.reverts = checkpoint.Reverts
+	engine.approvals = checkpoint.Approvals
+	engine.promiseStreak = checkpoint.PromiseStreak
+	engine.promiseIteration = checkpoint.PromiseIteration
+	engine.firstPromise = checkpoint.FirstPromise
+	engine.lastOutcome = checkpoint.LastOutcome
+	engine.previousSummary = checkpoint.PreviousSummary
+	engine.diagnostics = checkpoint.Diagnostics
+	engine.carryOver = slices.Clone(checkpoint.CarryOver)
+	engine.failureHistory = slices.Clone(checkpoint.FailureHistory)
+	engine.iterationUsage = slices.Clone(checkpoint.IterationUsage)
+	engine.iterationModels = slices.Clone(checkpoint.IterationModels)
+	engine.fallback = checkpoint.Fallback
+	engine.usage = checkpoint.Usage
+	engine.phase = checkpoint.Phase
+	if len(checkpoint.Phases) > 0 {
+		engine.phases = slices.Clone(checkpoint.Phases)
+	}
+	engine.checkpoint = checkpoint
+
+	return engine
+}
+
+// newRunID generates a sortable, unique run identifier.
+func newRunID() string {
+	suffix := make([]byte, 3)
+	_, _ = rand.Read(suffix)
+
+	return time.Now().Format("20060102-150405") + "-" + hex.EncodeToString(suffix)
+}
+
+// snapshot captures the resumable engine state.
+// Must be called with lock held.
+func (e *LoopEngine) snapshot() *Checkpoint {
+	return &Checkpoint{
+		UpdatedAt:        time.Now(),
+		Config:           e.config,
+		Diagnostics:      e.diagnostics,
+		RunID:            e.runID,
+		State:            e.state,
+		LastOutcome:      e.lastOutcome,
+		PreviousSummary:  e.previousSummary,
+		CarryOver:        slices.Clone(e.carryOver),
+		FailureHistory:   slices.Clone(e.failureHistory),
+		IterationUsage:   slices.Clone(e.iterationUsage),
+		IterationModels:  slices.Clone(e.iterationModels),
+		Usage:            e.usage,
+		Phases:           e.phaseResults(),
+		Phase:            e.phase,
+		Fallback:         e.fallback,
+		Elapsed:          e.elapsed(),
+		Iteration:        e.iteration,
+		Sessions:         e.sessions,
+		StartCommit:      e.startCommit,
+		Branch:           e.branch,
+		Commits:          e.commits,
+		Reverts:          e.reverts,
+		Approvals:        e.approvals,
+		PromiseStreak:    e.promiseStreak,
+		PromiseIteration: e.promiseIteration,
+		FirstPromise:     e.firstPromise,
+	}
+}
+
+// saveCheckpoint persists the state after a completed iteration.
+func (e *LoopEngine) saveCheckpoint() {
+	e.mu.Lock()
+	e.checkpoint = e.snapshot()
+	checkpoint := e.checkpoint
+	e.mu.Unlock()
+
+	e.persistCheckpoint(checkpoint)
+}
+
+// finishCheckpoint records the final state on the last completed iteration's checkpoint,
+// so an interrupted iteration is repeated when the run is resumed.
+func (e *LoopEngine) finishCheckpoint(state LoopState) {
+	e.mu.Lock()
+	checkpoint := e.checkpoint
+	if checkpoint == nil {
+		checkpoint = e.snapshot()
+		checkpoint.Iteration = 0
+	}
+	checkpoint.State = state
+	checkpoint.Elapsed = e.elapsed()
+	checkpoint.UpdatedAt = time.Now()
+	e.mu.Unlock()
+
+	e.persistCheckpoint(checkpoint)
+}
+
+// persistCheckpoint writes the checkpoint when checkpointing is enabled.
+// Failures are reported as recoverable errors so they never stop the loop.
+func (e *LoopEngine) persistCheckpoint(checkpoint *Checkpoint) {
+	if strings.TrimSpace(e.config.CheckpointDir) == "" {
+		return
+	}
+
+	if err := writeCheckpoint(e.config.CheckpointDir, checkpoint); err != nil {
+		e.emit(NewErrorEvent(err, checkpoint.Iteration, true))
+	}
+}
+
+// ensureStateDir creates the run state directory and keeps the run state out of version control.
+func ensureStateDir(dir string) error {
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		return fmt.Errorf("failed to create state directory: %w", err)
+	}
+
+	ignore := filepath.Join(dir, ".gitignore")
+	if _, err := os.Stat(ignore); errors.Is(err, os.ErrNotExist) {
+		if err := os.WriteFile(ignore, []byte(stateDirIgnore), 0o644); err != nil {
+			return fmt.Errorf("failed to write state directory .gitignore: %w", err)
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,198 @@
+package core
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// drainEvents consumes engine events so emits never block the test.
+func drainEvents(engine *LoopEngine) {
+	go func() {
+		for range engine.Events() {
+		}
+	}()
+}
+
+func TestLoopEngine_WritesCheckpoint(t *testing.T) {
+	dir := t.TempDir()
+	config := &LoopConfig{
+		Prompt:        "Test task",
+		MaxIterations: 2,
+		Timeout:       time.Minute,
+		PromisePhrase: "done",
+		CheckpointDir: dir,
+	}
+	engine := NewLoopEngine(config, NewMockSDKClient())
+	drainEvents(engine)
+
+	result, err := engine.Start(context.Background())
+	require.NoError(t, err)
+	assert.Equal(t, engine.RunID(), result.RunID)
+
+	checkpoint, err := LoadCheckpoint(dir, engine.RunID())
+	require.NoError(t, err)
+	assert.Equal(t, StateComplete, checkpoint.State)
+	assert.Equal(t, 2, checkpoint.Iteration)
+	assert.Equal(t, "Test task", checkpoint.Config.Prompt)
+	assert.Equal(t, dir, checkpoint.Config.CheckpointDir)
+	assert.Equal(t, "no completion promise", checkpoint.LastOutcome)
+	assert.Error(t, checkpoint.Resumable())
+
+	ignore, err := os.ReadFile(filepath.Join(dir, ".gitignore"))
+	require.NoError(t, err)
+	assert.Equal(t, "*\n!mcp.json\n", string(ignore))
+}
+
+func TestLoopEngine_ResumeFromCheckpoint(t *testing.T) {
+	dir := t.TempDir()
+	config := &LoopConfig{
+		Prompt:        "Test task",
+		MaxIterations: 5,
+		Timeout:       time.Minute,
+		PromisePhrase: "done",
+		CheckpointDir: dir,
+		SummaryLimit:  100,
+	}
+
+	checkpoint := &Checkpoint{
+		Config:          config,
+		RunID:           "20260101-120000-abcdef",
+		State:           StateCancelled,
+		PreviousSummary: "Fixed the parser",
+		CarryOver:       []string{"Remember the lexer"},
+		FailureHistory:  []int{3, 1},
+		Elapsed:         20 * time.Second,
+		Iteration:       2,
+		Sessions:        1,
+	}
+	require.NoError(t, writeCheckpoint(dir, checkpoint))
+
+	loaded, err := LoadCheckpoint(dir, "")
+	require.NoError(t, err)
+	require.NoError(t, loaded.Resumable())
+	assert.Equal(t, 3, loaded.RemainingIterations())
+	assert.Equal(t, 40*time.Second, loaded.RemainingTime())
+
+	mockSDK := NewMockSDKClient()
+	engine := ResumeLoopEngine(loaded, mockSDK)
+	drainEvents(engine)
+
+	result, err := engine.Start(context.Background())
+	require.NoError(t, err)
+
+	assert.Equal(t, "20260101-120000-abcdef", result.RunID)
+	assert.Equal(t, 5, result.Iterations)
+	assert.Equal(t, 2, result.Sessions)
+	assert.GreaterOrEqual(t, result.Duration, 20*time.Second)
+	assert.Equal(t, []int{3, 1}, result.FailureHistory)
+
+	require.Len(t, mockSDK.Prompts, 3)
+	assert.Contains(t, mockSDK.Prompts[0], "[Iteration 3/5]")
+	assert.Contains(t, mockSDK.Prompts[0], "Fixed the parser")
+	assert.Contains(t, mockSDK.Prompts[0], "Remember the lexer")
+
+	saved, err := LoadCheckpoint(dir, "20260101-120000-abcdef")
+	require.NoError(t, err)
+	assert.Equal(t, StateComplete, saved.State)
+	assert.Equal(t, 5, saved.Iteration)
+}
+
+func TestLoopEngine_CancelledCheckpointKeepsCompletedIterations(t *testing.T) {
+	dir := t.TempDir()
+	mockSDK := &SlowMockSDKClient{
+		MockSDKClient: NewMockSDKClient(),
+		delay:         100 * time.Millisecond,
+	}
+	config := &LoopConfig{
+		Prompt:        "Test task",
+		MaxIterations: 100,
+		PromisePhrase: "never found",
+		CheckpointDir: dir,
+	}
+	engine := NewLoopEngine(config, mockSDK)
+	drainEvents(engine)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 250*time.Millisecond)
+	defer cancel()
+
+	result, err := engine.Start(ctx)
+	require.Error(t, err)
+
+	checkpoint, err := LoadCheckpoint(dir, engine.RunID())
+	require.NoError(t, err)
+	assert.Equal(t, result.State, checkpoint.State)
+	assert.Less(t, checkpoint.Iteration, result.Iterations)
+	assert.NoError(t, checkpoint.Resumable())
+}
+
+func TestCheckpointResumable(t *testing.T) {
+	tests := []struct {
+		name       string
+		checkpoint *Checkpoint
+		errorMsg   string
+	}{
+		{
+			name: "cancelled run",
+			checkpoint: &Checkpoint{
+				Config:    &LoopConfig{MaxIterations: 5, Timeout: time.Minute},
+				State:     StateCancelled,
+				Iteration: 2,
+				Elapsed:   time.Second,
+			},
+		},
+		{
+			name: "complete run",
+			checkpoint: &Checkpoint{
+				Config: &LoopConfig{MaxIterations: 5, Timeout: time.Minute},
+				State:  StateComplete,
+			},
+			errorMsg: "already complete",
+		},
+		{
+			name: "no iterations left",
+			checkpoint: &Checkpoint{
+				Config:    &LoopConfig{MaxIterations: 5, Timeout: time.Minute},
+				State:     StateFailed,
+				Iteration: 5,
+			},
+			errorMsg: "no iterations left",
+		},
+		{
+			name: "no time left",
+			checkpoint: &Checkpoint{
+				Config:  &LoopConfig{MaxIterations: 5, Timeout: time.Minute},
+				State:   StateFailed,
+				Elapsed: 2 * time.Minute,
+			},
+			errorMsg: "no time left",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := tt.checkpoint.Resumable()
+
+			if tt.errorMsg != "" {
+				require.Error(t, err)
+				assert.Contains(t, err.Error(), tt.errorMsg)
+				return
+			}
+
+			assert.NoError(t, err)
+		})
+	}
+}
+
+func TestLoadCheckpoint_Missing(t *testing.T) {
+	_, err := LoadCheckpoint(t.TempDir(), "")
+	assert.ErrorIs(t, err, ErrNoCheckpoint)
+
+	_, err = LoadCheckpoint(t.TempDir(), "unknown")
+	assert.ErrorIs(t, err, ErrNoCheckpoint)
+}
@@ -0,0 +1,80 @@
+// Package core provides shell command execution for the loop engine.
+
+package core
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"os/exec"
+	"runtime"
+)
+
+// maxCommandOutput is the maximum number of bytes of command output kept for prompts and events.
+const maxCommandOutput = 8 * 1024
+
+// commandResult holds the outcome of a shell command.
+type commandResult struct {
+	// Output is the complete combined stdout and stderr, used for parsing.
+	// Use Tail for the part shown in prompts and events.
+	Output string
+	// ExitCode is the process exit code.
+	ExitCode int
+}
+
+// Passed reports whether the command exited successfully.
+func (r *commandResult) Passed() bool {
+	return r.ExitCode == 0
+}
+
+// Tail returns the output truncated to its last maxCommandOutput bytes.
+func (r *commandResult) Tail() string {
+	return truncateOutput(r.Output, maxCommandOutput)
+}
+
+// runShellCommand runs command through the platform shell in dir.
+// A non-zero exit code is reported through the result, not as an error.
+// An error is returned when the command could not be run at all.
+func runShellCommand(ctx context.Context, dir, command string) (*commandResult, error) {
+	cmd := exec.CommandContext(ctx, "sh", "-c", command)
+	if runtime.GOOS == "windows" {
+		cmd = exec.CommandContext(ctx, "cmd", "/C", command)
+	}
+
+	cmd.Dir = dir
+
+	var output bytes.Buffer
+	cmd.Stdout = &output
+	cmd.Stderr = &output
+
+	err := cmd.Run()
+	if ctxErr := ctx.Err(); ctxErr != nil {
+		return nil, ctxErr
+	}
+
+	result := &commandResult{
+		Output: output.String(),
+	}
+
+	if err == nil {
+		return result, nil
+	}
+
+	var exitErr *exec.ExitError
+	if errors.As(err, &exitErr) {
+		result.ExitCode = exitErr.ExitCode()
+		return result, nil
+	}
+
+	return nil, fmt.Errorf("failed to run command %q: %w", command, err)
+}
+
+// truncateOutput keeps the last limit bytes of output, where failures usually are.
+func truncateOutput(output string, limit int) string {
+	if len(output) <= limit {
+		return output
+	}
+
+	return "... (output truncated)\n" + output[len(output)-limit:]
+}
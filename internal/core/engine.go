@@ -7,6 +7,7 @@ import (
 	_ "embed"
 	"errors"
 	"fmt"
+	"slices"
 	"strings"
 	"time"
 
@@ -35,40 +36,78 @@ func (e *LoopEngine) Start(ctx context.Context) (*LoopResult, error) {
 		return nil, errors.New("loop already running")
 	}
 
-	// Set up cancellation with timeout if configured
+	// Set up cancellation with timeout if configured, minus the time spent before a resume.
+	// The deadline is a timer rather than a context deadline so it can be held while paused.
+	e.ctx, e.cancel = context.WithCancelCause(ctx)
 	if e.config.Timeout > 0 {
-		e.ctx, e.cancel = context.WithTimeout(ctx, e.config.Timeout)
-	} else {
-		e.ctx, e.cancel = context.WithCancel(ctx)
+		e.deadline = time.AfterFunc(e.config.Timeout-e.elapsedBefore, func() {
+			e.cancel(context.DeadlineExceeded)
+		})
 	}
 	e.state = StateRunning
 	e.startTime = time.Now()
-	e.iteration = 0
 	e.mu.Unlock()
 
-	// Close events channel when engine finishes to unblock any listeners
-	defer func() {
-		e.mu.Lock()
-		e.eventsClosed = true
-		e.mu.Unlock()
-		close(e.events)
-	}()
+	defer e.stopDeadline()
+
+	// Close the event bus when engine finishes to unblock any listeners
+	defer e.bus.Close()
 
 	// Emit loop start event
 	e.emit(NewLoopStartEvent(e.config))
 
+	if err := e.setupGit(); err != nil {
+		return e.fail(fmt.Errorf("failed to set up git: %w", err))
+	}
+
+	if err := e.recordRollbackBaseline(); err != nil {
+		return e.fail(fmt.Errorf("failed to set up rollback: %w", err))
+	}
+
+	if err := e.recordFingerprint(); err != nil {
+		return e.fail(fmt.Errorf("failed to set up stagnation detection: %w", err))
+	}
+
+	if err := e.loadChecklist(); err != nil {
+		return e.fail(fmt.Errorf("failed to set up checklist: %w", err))
+	}
+
 	// Initialize SDK if provided
 	if e.sdk != nil {
 		if err := e.sdk.Start(); err != nil {
 			return e.fail(fmt.Errorf("failed to start SDK: %w", err))
 		}
 
+		if err := e.gateTools(); err != nil {
+			return e.fail(fmt.Errorf("failed to set up tool approval: %w", err))
+		}
+
+		e.registerTools()
+
+		if err := e.usePhaseModel(); err != nil {
+			return e.fail(fmt.Errorf("failed to start phase: %w", err))
+		}
+
+		if err := e.useFallbackModel(); err != nil {
+			return e.fail(fmt.Errorf("failed to restore fallback model: %w", err))
+		}
+
 		err := e.sdk.CreateSession(e.ctx)
 		if err != nil {
 			return e.fail(fmt.Errorf("failed to create SDK session: %w", err))
 		}
+
+		e.mu.Lock()
+		e.sessions++
+		e.mu.Unlock()
+
+		if err := e.startReviewer(); err != nil {
+			return e.fail(fmt.Errorf("failed to start reviewer: %w", err))
+		}
 	}
 
+	e.beginPhase()
+
 	// Run the main loop
 	result, err := e.runLoop()
 
@@ -81,6 +120,7 @@ func (e *LoopEngine) Start(ctx context.Context) (*LoopResult, error) {
 				defer cleanupCancel()
 				_ = e.sdk.DestroySession(cleanupCtx)
 				_ = e.sdk.Stop()
+				e.stopReviewer(1 * time.Second)
 			}()
 		} else {
 			// Normal cleanup - wait for completion
@@ -88,45 +128,162 @@ func (e *LoopEngine) Start(ctx context.Context) (*LoopResult, error) {
 			_ = e.sdk.DestroySession(cleanupCtx)
 			cleanupCancel()
 			_ = e.sdk.Stop()
+			e.stopReviewer(5 * time.Second)
 		}
 	}
 
 	return result, err
 }
 
+// iterationResult captures the outcome of a single iteration.
+type iterationResult struct {
+	// finalMessage is the last assistant message of the iteration.
+	finalMessage string
+	// promiseDetected is true when the promise phrase was found in the AI response.
+	promiseDetected bool
+	// promiseRejected is true when the verification command rejected the promise.
+	promiseRejected bool
+	// reviewRejected is true when the promise was rejected for lack of reviewer approval.
+	reviewRejected bool
+	// reverted is true when the iteration's changes were rolled back.
+	reverted bool
+	// failure is set when the iteration counts against the current model.
+	failure FallbackReason
+}
+
+// describe summarizes the outcome for the next iteration prompt.
+func (r *iterationResult) describe(diagnostics *DiagnosticsReport) string {
+	description := "no completion promise"
+	if r.promiseRejected {
+		description = "completion promise rejected by verification"
+	}
+	if r.reviewRejected {
+		description = "completion promise rejected because the reviewer did not approve"
+	}
+	if r.promiseDetected {
+		description = "completion promise detected"
+	}
+	if r.reverted {
+		description = "changes reverted because they made things worse"
+	}
+
+	if diagnostics != nil {
+		description += fmt.Sprintf(", %d diagnostic failures", diagnostics.Failed())
+	}
+
+	return description
+}
+
 // runLoop executes the main iteration loop.
-// The loop continues until all iterations are completed, timeout is hit, or an error occurs.
-// Promise detection is tracked but does not stop the loop.
+// The loop continues until the completion policy accepts the promise, all iterations
+// are completed, timeout is hit, or an error occurs.
 func (e *LoopEngine) runLoop() (*LoopResult, error) {
 	for {
+		if err := e.waitWhilePaused(); err != nil {
+			return e.iterationFailed(err)
+		}
+
 		if result, err := e.preIterationCheck(); err != nil || result != nil {
 			return result, err
 		}
 
+		if err := e.rotateSession(e.Iteration() + 1); err != nil {
+			return e.iterationFailed(err)
+		}
+
+		e.recordReviewBase()
+
 		// Execute iteration
-		err := e.executeIteration()
+		outcome, err := e.executeIteration()
+		if err != nil {
+			return e.iterationFailed(err)
+		}
+
+		iteration := e.Iteration()
+
+		outcome.reverted, err = e.rollbackIfWorse(iteration)
 		if err != nil {
-			// Check if it's a timeout (context deadline exceeded)
-			if errors.Is(err, context.DeadlineExceeded) {
-				return e.fail(ErrLoopTimeout)
+			return e.iterationFailed(err)
+		}
+
+		// A promise from a reverted attempt no longer describes the working tree
+		if outcome.reverted {
+			outcome.promiseDetected = false
+		}
+
+		if err := e.collectDiagnostics(iteration); err != nil {
+			return e.iterationFailed(err)
+		}
+
+		// A checklist run completes once every item is checked instead of on the promise
+		if e.config.Checklist != "" {
+			outcome.promiseDetected, err = e.checkChecklist(iteration)
+			if err != nil {
+				return e.iterationFailed(err)
 			}
-			// Check if it's a cancellation
-			if errors.Is(err, context.Canceled) {
-				return e.cancelled()
+		}
+
+		// Only hand a promise to the completion policy once it survived verification
+		if outcome.promiseDetected {
+			outcome.promiseDetected, err = e.verifyPromise(iteration)
+			if err != nil {
+				return e.iterationFailed(err)
 			}
-			return e.fail(fmt.Errorf("iteration %d failed: %w", e.iteration, err))
+			outcome.promiseRejected = !outcome.promiseDetected
+		}
+
+		if err := e.applyReview(iteration, outcome); err != nil {
+			return e.iterationFailed(err)
 		}
 
-		// Continue to next iteration (promise does not stop the loop)
+		e.mu.Lock()
+		e.lastOutcome = outcome.describe(e.diagnostics)
+		e.previousSummary = extractSummary(outcome.finalMessage, e.promisePhrase(), e.config.SummaryLimit)
+		e.mu.Unlock()
+
+		e.commitIteration(iteration, outcome.finalMessage)
+
+		accepted := e.acceptPromise(iteration, outcome.promiseDetected)
+		if accepted {
+			// A multi-phase run only completes with the promise of its last phase
+			accepted, err = e.advancePhase(iteration)
+			if err != nil {
+				return e.iterationFailed(err)
+			}
+		}
+		e.saveCheckpoint()
+
+		if accepted {
+			return e.complete()
+		}
+
+		if err := e.checkFallback(iteration, outcome.failure); err != nil {
+			return e.iterationFailed(err)
+		}
+
+		if err := e.checkStagnation(iteration); err != nil {
+			return e.iterationFailed(err)
+		}
 	}
 }
 
+// iterationFailed maps an iteration error to the matching terminal state.
+func (e *LoopEngine) iterationFailed(err error) (*LoopResult, error) {
+	// Check if it's a timeout (context deadline exceeded)
+	if errors.Is(err, context.DeadlineExceeded) || errors.Is(context.Cause(e.ctx), context.DeadlineExceeded) {
+		return e.fail(ErrLoopTimeout)
+	}
+	// Check if it's a cancellation
+	if errors.Is(err, context.Canceled) {
+		return e.cancelled()
+	}
+	return e.fail(fmt.Errorf("iteration %d failed: %w", e.Iteration(), err))
+}
+
 // preIterationCheck evaluates cancellation, state, and limit guards before running an iteration.
 func (e *LoopEngine) preIterationCheck() (*LoopResult, error) {
-	select {
-	case <-e.ctx.Done():
-		return e.cancelled()
-	default:
+	if err := e.ctx.Err(); err != nil {
+		return e.iterationFailed(err)
 	}
 
 	e.mu.RLock()
@@ -137,7 +294,7 @@ func (e *LoopEngine) preIterationCheck() (*LoopResult, error) {
 		return e.cancelled()
 	}
 
-	if e.config.Timeout > 0 && time.Since(e.startTime) > e.config.Timeout {
+	if e.config.Timeout > 0 && e.elapsed() > e.config.Timeout {
 		return e.fail(ErrLoopTimeout)
 	}
 
@@ -146,53 +303,107 @@ func (e *LoopEngine) preIterationCheck() (*LoopResult, error) {
 		return e.complete()
 	}
 
+	if e.phaseExhausted() {
+		return e.complete()
+	}
+
 	return nil, nil
 }
 
 // executeIteration executes a single iteration of the loop.
-// Promise detection is tracked but does not stop execution.
-func (e *LoopEngine) executeIteration() error {
+// It reports whether the promise was detected so runLoop can apply the completion policy.
+func (e *LoopEngine) executeIteration() (*iterationResult, error) {
 	e.mu.Lock()
 	e.iteration++
+	e.countPhaseIteration()
+	e.recordModel()
 	iteration := e.iteration
 	e.mu.Unlock()
 
 	iterationStart := time.Now()
+	outcome := &iterationResult{}
 
 	// Emit iteration start
 	e.emit(NewIterationStartEvent(iteration, e.config.MaxIterations))
 
 	// Build context and send prompt
-	prompt := e.buildIterationPrompt(iteration)
+	prompt, err := e.buildIterationPrompt(iteration)
+	if err != nil {
+		return nil, err
+	}
 
 	// If SDK is available, send prompt
 	if e.sdk != nil {
-		events, err := e.sdk.SendPrompt(e.ctx, prompt)
+		// The iteration context lets the engine cut a single iteration short
+		iterationCtx, cancelIteration := e.iterationContext()
+		defer cancelIteration()
+
+		events, err := e.sdk.SendPrompt(iterationCtx, prompt)
 		if err != nil {
-			return fmt.Errorf("failed to send prompt: %w", err)
+			return nil, fmt.Errorf("failed to send prompt: %w", err)
 		}
 
+		// Steering messages go straight to the session while the prompt is processed
+		e.setPrompting(true)
+		defer e.setPrompting(false)
+
+		// streamed collects text received since the last complete message
+		var streamed strings.Builder
+
 		// Process events - use select to handle both events and cancellation
 	eventLoop:
 		for {
 			select {
 			case <-e.ctx.Done():
-				return e.ctx.Err()
+				return nil, e.ctx.Err()
+			case <-iterationCtx.Done():
+				if e.ctx.Err() != nil {
+					return nil, e.ctx.Err()
+				}
+
+				if errors.Is(iterationCtx.Err(), context.DeadlineExceeded) {
+					e.emit(NewIterationTimeoutEvent(iteration, e.config.IterationTimeout))
+					e.carry(fmt.Sprintf(iterationTimeoutNote, iteration, e.config.IterationTimeout))
+				}
+
+				if err := e.settleAbortedPrompt(events); err != nil {
+					return nil, err
+				}
+
+				break eventLoop
 			case event, ok := <-events:
 				if !ok {
 					// Channel closed, exit loop
 					break eventLoop
 				}
 
+				var repetition *RepetitionDetectedEvent
+
 				switch ev := event.(type) {
 				case *sdk.TextEvent:
-					e.emit(NewAIResponseEvent(ev.Text, iteration))
+					response := NewAIResponseEvent(ev.Text, iteration)
+					e.emit(response)
 
 					// Check for promise in streaming text that's not reasoning
-					if !ev.Reasoning && detectPromise(ev.Text, e.config.PromisePhrase) {
-						e.emit(NewPromiseDetectedEvent(e.config.PromisePhrase, "ai_response", iteration))
+					if !ev.Reasoning {
+						streamed.WriteString(ev.Text)
+						e.checkPromise(ev.Text, iteration, outcome)
+						e.repetition.observe(response)
+					}
+
+				case *sdk.ResponseCompleteEvent:
+					// Without streaming, the complete message is the only copy of the text
+					if streamed.Len() == 0 {
+						response := NewAIResponseEvent(ev.Message.Content, iteration)
+						e.emit(response)
+						e.repetition.observe(response)
 					}
 
+					streamed.Reset()
+					outcome.finalMessage = ev.Message.Content
+					e.checkPromise(ev.Message.Content, iteration, outcome)
+					repetition = e.repetition.endMessage(iteration)
+
 				case *sdk.ToolCallEvent:
 					// Tool execution started - SDK handles it internally
 					// We just log the start for UI purposes
@@ -203,21 +414,59 @@ func (e *LoopEngine) executeIteration() error {
 					))
 
 				case *sdk.ToolResultEvent:
-					e.emit(NewToolExecutionEvent(
+					execution := NewToolExecutionEvent(
 						ev.ToolCall.Name,
 						ev.ToolCall.Parameters,
 						ev.Result,
 						ev.Error,
 						0, // Duration not available from SDK events
 						iteration,
-					))
+					)
+					e.emit(execution)
+					repetition = e.repetition.observe(execution)
+
+				case *sdk.ToolDeniedEvent:
+					e.denyTool(ev, iteration)
+
+				case *sdk.UsageEvent:
+					if err := e.recordUsage(e.sdk, ev, iteration); err != nil {
+						return nil, err
+					}
 
 				case *sdk.ErrorEvent:
 					// SDK errors are typically tool execution failures, which are recoverable
 					e.emit(NewErrorEvent(ev.Err, iteration, true))
+
+					if !errors.Is(ev.Err, context.Canceled) && !errors.Is(ev.Err, context.DeadlineExceeded) {
+						outcome.failure = errorFallbackReason(ev.Err)
+					}
+				}
+
+				if repetition != nil && e.handleRepetition(repetition) {
+					cancelIteration()
+					if err := e.settleAbortedPrompt(events); err != nil {
+						return nil, err
+					}
+
+					break eventLoop
 				}
 			}
 		}
+
+		// Text streamed after the last complete message is the most recent output
+		if streamed.Len() > 0 {
+			outcome.finalMessage = streamed.String()
+		}
+
+		if repetition := e.repetition.endMessage(iteration); repetition != nil {
+			e.handleRepetition(repetition)
+		}
+
+		e.checkSignal(iteration, outcome)
+
+		if outcome.failure == "" && isRefusal(outcome.finalMessage) {
+			outcome.failure = FallbackRefusal
+		}
 	}
 
 	iterationDuration := time.Since(iterationStart)
@@ -225,25 +474,133 @@ func (e *LoopEngine) executeIteration() error {
 	// Emit iteration complete
 	e.emit(NewIterationCompleteEvent(iteration, iterationDuration))
 
-	return nil
+	return outcome, nil
+}
+
+// iterationTimeoutNote tells the model its previous iteration was aborted.
+const iterationTimeoutNote = "Iteration %d was aborted because it did not finish within %s. " +
+	"Work in smaller steps and avoid long-running or interactive commands."
+
+// settleAbortedPrompt waits for the event stream of an aborted prompt to close, so the
+// end of the aborted turn cannot cut short the next prompt sent to the same session.
+// The session is replaced when the aborted turn did not end.
+func (e *LoopEngine) settleAbortedPrompt(events <-chan sdk.Event) error {
+	// Steering messages sent from now on are carried to the next prompt
+	e.setPrompting(false)
+
+	settled := true
+	for event := range events {
+		if ev, ok := event.(*sdk.ErrorEvent); ok && errors.Is(ev.Err, sdk.ErrAbortIncomplete) {
+			settled = false
+		}
+	}
+
+	if settled {
+		return nil
+	}
+
+	return e.replaceSession("")
+}
+
+// iterationContext derives the context for a single iteration from the loop context,
+// bounded by the iteration timeout when one is configured.
+func (e *LoopEngine) iterationContext() (context.Context, context.CancelFunc) {
+	if e.config.IterationTimeout > 0 {
+		return context.WithTimeout(e.ctx, e.config.IterationTimeout)
+	}
+
+	return context.WithCancel(e.ctx)
 }
 
 // buildIterationPrompt builds the prompt for the current iteration.
 // The system prompt template handles the loop context and completion instructions.
-func (e *LoopEngine) buildIterationPrompt(iteration int) string {
+// The task prompt is rendered as a template with the current PromptData.
+// Current diagnostic failures are prepended to the task, the previous iteration
+// summary and notes carried over from the previous iteration are appended after it.
+func (e *LoopEngine) buildIterationPrompt(iteration int) (string, error) {
 	var builder strings.Builder
 
+	e.mu.RLock()
+	failures := failuresSection(e.diagnostics)
+	data := newPromptData(e.config, iteration, e.elapsed())
+	data.PreviousOutcome = e.lastOutcome
+	data.PreviousSummary = e.previousSummary
+	data.Promise = e.promisePhrase()
+	e.mu.RUnlock()
+
+	task, err := renderTemplate("prompt", e.config.Prompt, data)
+	if err != nil {
+		return "", err
+	}
+
+	if phase := e.currentPhase(); phase != nil {
+		data.Phase = phase.Name
+
+		phasePrompt, err := renderTemplate("phase prompt", phase.Prompt, data)
+		if err != nil {
+			return "", err
+		}
+
+		task = phaseTask(task, phasePrompt, phase, e.phase, len(e.config.Phases), data.Promise)
+	}
+
 	// Add iteration context
 	builder.WriteString(fmt.Sprintf("[Iteration %d/%d]\n\n", iteration, e.config.MaxIterations))
 
+	if failures != "" {
+		builder.WriteString(failures)
+		builder.WriteString("\n")
+	}
+
 	// Add original task prompt
-	builder.WriteString(e.config.Prompt)
+	builder.WriteString(task)
+
+	if e.config.Checklist != "" {
+		checklist, err := e.checklistSection()
+		if err != nil {
+			return "", err
+		}
+
+		builder.WriteString("\n\n")
+		builder.WriteString(checklist)
+	}
+
+	if data.PreviousSummary != "" {
+		builder.WriteString("\n\n## Previous iteration summary\n\n")
+		builder.WriteString(data.PreviousSummary)
+	}
+
+	for _, note := range e.takeCarryOver() {
+		builder.WriteString("\n\n")
+		builder.WriteString(note)
+	}
 
-	return builder.String()
+	if e.config.RequestSummary {
+		builder.WriteString("\n\n")
+		builder.WriteString(summaryRequest)
+	}
+
+	return builder.String(), nil
+}
+
+// carry queues a note to be included in the next iteration prompt.
+func (e *LoopEngine) carry(note string) {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	e.carryOver = append(e.carryOver, note)
+}
+
+// takeCarryOver returns and clears the notes queued for the next iteration prompt.
+func (e *LoopEngine) takeCarryOver() []string {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	notes := e.carryOver
+	e.carryOver = nil
+	return notes
 }
 
 // complete transitions to the complete state and returns the result.
-// This is now only called when max iterations are reached without errors.
+// It is called when the promise is accepted or max iterations are reached without errors.
 func (e *LoopEngine) complete() (*LoopResult, error) {
 	e.mu.Lock()
 	e.state = StateComplete
@@ -251,6 +608,7 @@ func (e *LoopEngine) complete() (*LoopResult, error) {
 	result.State = StateComplete
 	e.mu.Unlock()
 
+	e.finishCheckpoint(StateComplete)
 	e.emit(NewLoopCompleteEvent(result))
 
 	return result, nil
@@ -265,6 +623,7 @@ func (e *LoopEngine) fail(err error) (*LoopResult, error) {
 	result.Error = err
 	e.mu.Unlock()
 
+	e.finishCheckpoint(StateFailed)
 	e.emit(NewLoopFailedEvent(err, result))
 
 	return result, err
@@ -279,6 +638,7 @@ func (e *LoopEngine) cancelled() (*LoopResult, error) {
 	result.Error = ErrLoopCancelled
 	e.mu.Unlock()
 
+	e.finishCheckpoint(StateCancelled)
 	e.emit(NewLoopCancelledEvent(result))
 
 	return result, ErrLoopCancelled
@@ -288,25 +648,35 @@ func (e *LoopEngine) cancelled() (*LoopResult, error) {
 // Must be called with lock held.
 func (e *LoopEngine) buildResult() *LoopResult {
 	return &LoopResult{
-		State:      e.state,
-		Iterations: e.iteration,
-		Duration:   time.Since(e.startTime),
+		State:                 e.state,
+		RunID:                 e.runID,
+		Iterations:            e.iteration,
+		PromiseIteration:      e.promiseIteration,
+		FirstPromiseIteration: e.firstPromise,
+		Duration:              e.elapsed(),
+		FailureHistory:        slices.Clone(e.failureHistory),
+		Sessions:              e.sessions,
+		StartCommit:           e.startCommit,
+		Branch:                e.branch,
+		Commits:               e.commits,
+		Reverts:               e.reverts,
+		Approvals:             e.approvals,
+		Usage:                 e.usage,
+		IterationUsage:        slices.Clone(e.iterationUsage),
+		IterationModels:       slices.Clone(e.iterationModels),
+		Phases:                e.phaseResults(),
+		ChecklistDone:         checklistDone(e.checklist),
+		ChecklistTotal:        len(e.checklist),
 	}
 }
 
-// emit sends an event to the events channel.
-func (e *LoopEngine) emit(event any) {
-	e.mu.RLock()
-	closed := e.eventsClosed
-	e.mu.RUnlock()
-
-	if closed {
-		return
-	}
+// elapsed returns the loop runtime including time spent before a resume,
+// excluding time spent paused.
+func (e *LoopEngine) elapsed() time.Duration {
+	return e.elapsedBefore + time.Since(e.startTime) - e.pausedFor
+}
 
-	select {
-	case e.events <- event:
-	default:
-		// Channel full, event dropped - log warning
-	}
+// emit publishes an event to all subscribers.
+func (e *LoopEngine) emit(event Event) {
+	e.bus.Publish(event)
 }
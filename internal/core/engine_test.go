@@ -59,10 +59,7 @@ func TestToolResultTriggersPromiseDetectedEvent(t *testing.T) {
 
 func TestEmitDropsWhenClosed(t *testing.T) {
 	eng := NewLoopEngine(nil, nil)
-	// Close events channel by toggling flag
-	eng.mu.Lock()
-	eng.eventsClosed = true
-	eng.mu.Unlock()
+	eng.bus.Close()
 
 	// Should not panic
 	eng.emit(NewLoopStartEvent(eng.Config()))
@@ -80,3 +77,87 @@ func TestBuildResultTiming(t *testing.T) {
 	assert.Equal(t, 2, res.Iterations)
 	assert.True(t, res.Duration >= 5*time.Second)
 }
+
+func TestIterationTimeoutContinuesLoop(t *testing.T) {
+	mockSDK := NewMockSDKClient()
+	mockSDK.ResponseDelay = time.Second
+
+	config := &LoopConfig{
+		Prompt:           "Test task",
+		MaxIterations:    2,
+		Timeout:          time.Minute,
+		IterationTimeout: 20 * time.Millisecond,
+		PromisePhrase:    "done",
+	}
+	engine := NewLoopEngine(config, mockSDK)
+
+	var timeouts []*IterationTimeoutEvent
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		for event := range engine.Events() {
+			if ev, ok := event.(*IterationTimeoutEvent); ok {
+				timeouts = append(timeouts, ev)
+			}
+		}
+	}()
+
+	result, err := engine.Start(context.Background())
+	<-done
+	require.NoError(t, err)
+
+	assert.Equal(t, StateComplete, result.State)
+	assert.Equal(t, 2, result.Iterations)
+	require.Len(t, timeouts, 2)
+	assert.Equal(t, 1, timeouts[0].Iteration)
+	assert.Equal(t, 20*time.Millisecond, timeouts[0].Timeout)
+
+	require.Len(t, mockSDK.Prompts, 2)
+	assert.Contains(t, mockSDK.Prompts[1], "Iteration 1 was aborted because it did not finish within 20ms")
+}
+
+func TestIterationTimeoutSettlesSession(t *testing.T) {
+	tests := []struct {
+		name            string
+		abortIncomplete bool
+		sessions        int
+	}{
+		{name: "aborted turn ends", sessions: 1},
+		{name: "aborted turn does not end", abortIncomplete: true, sessions: 2},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockSDK := NewMockSDKClient()
+			mockSDK.ResponseDelay = time.Second
+			mockSDK.AbortDelay = 50 * time.Millisecond
+			mockSDK.AbortIncomplete = tt.abortIncomplete
+			mockSDK.OnPrompt = func(string) {
+				// Only the first iteration times out
+				if len(mockSDK.Prompts) > 1 {
+					mockSDK.ResponseDelay = 0
+					mockSDK.ResponseText = "<promise>done</promise>"
+				}
+			}
+
+			config := &LoopConfig{
+				Prompt:           "Test task",
+				MaxIterations:    2,
+				Timeout:          time.Minute,
+				IterationTimeout: 20 * time.Millisecond,
+				PromisePhrase:    "done",
+			}
+			engine := NewLoopEngine(config, mockSDK)
+			drainEvents(engine)
+
+			result, err := engine.Start(context.Background())
+			require.NoError(t, err)
+
+			assert.Equal(t, StateComplete, result.State)
+			assert.Equal(t, 2, result.Iterations)
+			assert.Equal(t, 2, result.PromiseIteration)
+			assert.Zero(t, mockSDK.Overlaps, "the next prompt waits for the aborted turn")
+			assert.Equal(t, tt.sessions, mockSDK.SessionsCreated)
+		})
+	}
+}
@@ -0,0 +1,191 @@
+// Package core provides the event bus delivering loop events to subscribers.
+
+package core
+
+import (
+	"slices"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// DeliveryPolicy decides what happens to an event when a subscriber's buffer is full.
+type DeliveryPolicy string
+
+const (
+	// DeliveryBlock waits until the subscriber has room, so the subscriber never misses an event.
+	// A subscriber that stops reading stalls the loop until it closes its subscription.
+	DeliveryBlock DeliveryPolicy = "block"
+	// DeliveryDrop discards the event for the subscriber and counts it as dropped.
+	DeliveryDrop DeliveryPolicy = "drop"
+)
+
+// String returns the string representation of the policy.
+func (p DeliveryPolicy) String() string {
+	return string(p)
+}
+
+// DefaultEventBuffer is the buffer size of subscriptions created with a buffer size below 1.
+const DefaultEventBuffer = 100
+
+// Subscription receives the events published on an event bus.
+type Subscription struct {
+	bus     *EventBus
+	events  chan Event
+	done    chan struct{}
+	policy  DeliveryPolicy
+	dropped atomic.Uint64
+	once    sync.Once
+	// closed is guarded by the bus lock.
+	closed bool
+}
+
+// Events returns the channel delivering the subscribed events.
+// It is closed when the bus or the subscription is closed.
+func (s *Subscription) Events() <-chan Event {
+	return s.events
+}
+
+// Dropped returns the number of events this subscriber missed because its buffer was full.
+func (s *Subscription) Dropped() uint64 {
+	return s.dropped.Load()
+}
+
+// Close stops the delivery of events and closes the events channel.
+// A publisher blocked on this subscriber is released.
+func (s *Subscription) Close() {
+	s.once.Do(func() {
+		// Release a publisher blocked on this subscriber before taking the bus lock
+		close(s.done)
+		s.bus.unsubscribe(s)
+	})
+}
+
+// send delivers event according to the subscriber's policy. Must be called with the bus lock held.
+func (s *Subscription) send(event Event) bool {
+	if s.policy == DeliveryDrop {
+		select {
+		case s.events <- event:
+			return true
+		default:
+			s.dropped.Add(1)
+			return false
+		}
+	}
+
+	select {
+	case s.events <- event:
+		return true
+	case <-s.done:
+		return true
+	}
+}
+
+// EventBus delivers published events to any number of independent subscribers.
+// Events are stamped with the run ID, a sequence number and the publication time,
+// and every subscriber receives them in publication order.
+type EventBus struct {
+	runID       string
+	subscribers []*Subscription
+	sequence    uint64
+	dropped     atomic.Uint64
+	mu          sync.Mutex
+	closed      bool
+}
+
+// NewEventBus creates an event bus stamping events with runID.
+func NewEventBus(runID string) *EventBus {
+	return &EventBus{runID: runID}
+}
+
+// Subscribe registers a subscriber with its own buffer of the given size and delivery policy.
+// Subscribers only receive events published after they subscribed.
+// Subscribing to a closed bus returns a subscription with a closed events channel.
+func (b *EventBus) Subscribe(buffer int, policy DeliveryPolicy) *Subscription {
+	if buffer < 1 {
+		buffer = DefaultEventBuffer
+	}
+
+	if policy != DeliveryDrop {
+		policy = DeliveryBlock
+	}
+
+	subscription := &Subscription{
+		bus:    b,
+		events: make(chan Event, buffer),
+		done:   make(chan struct{}),
+		policy: policy,
+	}
+
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	if b.closed {
+		subscription.closed = true
+		close(subscription.events)
+		return subscription
+	}
+
+	b.subscribers = append(b.subscribers, subscription)
+
+	return subscription
+}
+
+// Publish stamps event and delivers it to every subscriber.
+// Events published after Close are discarded.
+func (b *EventBus) Publish(event Event) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	if b.closed {
+		return
+	}
+
+	b.sequence++
+	event.stamp(b.runID, b.sequence, time.Now())
+
+	for _, subscriber := range b.subscribers {
+		if !subscriber.send(event) {
+			b.dropped.Add(1)
+		}
+	}
+}
+
+// Dropped returns the number of deliveries dropped across all subscribers.
+func (b *EventBus) Dropped() uint64 {
+	return b.dropped.Load()
+}
+
+// Close closes the events channel of every subscriber. It is safe to call more than once.
+func (b *EventBus) Close() {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	if b.closed {
+		return
+	}
+
+	b.closed = true
+	for _, subscriber := range b.subscribers {
+		subscriber.closed = true
+		close(subscriber.events)
+	}
+	b.subscribers = nil
+}
+
+// unsubscribe removes subscription from the bus and closes its events channel.
+func (b *EventBus) unsubscribe(subscription *Subscription) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	if subscription.closed {
+		return
+	}
+
+	subscription.closed = true
+	close(subscription.events)
+
+	b.subscribers = slices.DeleteFunc(b.subscribers, func(subscriber *Subscription) bool {
+		return subscriber == subscription
+	})
+}
@@ -0,0 +1,169 @@
+package core
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestEventBus_MultipleSubscribers(t *testing.T) {
+	bus := NewEventBus("run-1")
+	first := bus.Subscribe(10, DeliveryBlock)
+	second := bus.Subscribe(10, DeliveryDrop)
+
+	before := time.Now()
+	bus.Publish(NewIterationStartEvent(1, 3))
+	bus.Publish(NewErrorEvent(errors.New("boom"), 1, true))
+	bus.Close()
+
+	for _, subscription := range []*Subscription{first, second} {
+		var received []Event
+		for event := range subscription.Events() {
+			received = append(received, event)
+		}
+
+		require.Len(t, received, 2)
+		assert.Equal(t, EventIterationStart, received[0].Kind())
+		assert.Equal(t, EventError, received[1].Kind())
+
+		for i, event := range received {
+			assert.Equal(t, "run-1", event.RunID())
+			assert.Equal(t, uint64(i+1), event.Sequence())
+			assert.False(t, event.Timestamp().Before(before))
+		}
+	}
+
+	assert.Zero(t, bus.Dropped())
+}
+
+func TestEventBus_DeliveryPolicies(t *testing.T) {
+	tests := []struct {
+		name     string
+		policy   DeliveryPolicy
+		received int
+		dropped  uint64
+	}{
+		{name: "drop discards events beyond the buffer", policy: DeliveryDrop, received: 2, dropped: 3},
+		{name: "block delivers every event", policy: DeliveryBlock, received: 5},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			bus := NewEventBus("run")
+			subscription := bus.Subscribe(2, tt.policy)
+
+			published := make(chan struct{})
+			go func() {
+				defer close(published)
+				for i := 1; i <= 5; i++ {
+					bus.Publish(NewIterationStartEvent(i, 5))
+				}
+				bus.Close()
+			}()
+
+			// Only start reading once a dropping publisher is done
+			if tt.policy == DeliveryDrop {
+				<-published
+			}
+
+			var sequences []uint64
+			for event := range subscription.Events() {
+				sequences = append(sequences, event.Sequence())
+			}
+			<-published
+
+			assert.Len(t, sequences, tt.received)
+			assert.IsIncreasing(t, sequences)
+			assert.Equal(t, tt.dropped, subscription.Dropped())
+			assert.Equal(t, tt.dropped, bus.Dropped())
+		})
+	}
+}
+
+func TestEventBus_SubscriptionCloseReleasesPublisher(t *testing.T) {
+	bus := NewEventBus("run")
+	blocked := bus.Subscribe(1, DeliveryBlock)
+	other := bus.Subscribe(10, DeliveryBlock)
+
+	published := make(chan struct{})
+	go func() {
+		defer close(published)
+		for i := 1; i <= 3; i++ {
+			bus.Publish(NewIterationStartEvent(i, 3))
+		}
+	}()
+
+	// The publisher waits for room in the first subscriber's buffer
+	select {
+	case <-published:
+		t.Fatal("publisher should block on a full subscriber")
+	case <-time.After(50 * time.Millisecond):
+	}
+
+	blocked.Close()
+	<-published
+	blocked.Close()
+
+	bus.Close()
+	count := 0
+	for range other.Events() {
+		count++
+	}
+	assert.Equal(t, 3, count)
+}
+
+func TestEventBus_PublishAfterClose(t *testing.T) {
+	bus := NewEventBus("run")
+	subscription := bus.Subscribe(0, DeliveryBlock)
+
+	var wg sync.WaitGroup
+	for range 4 {
+		wg.Go(func() {
+			for i := range 50 {
+				bus.Publish(NewIterationStartEvent(i, 50))
+			}
+		})
+	}
+	wg.Go(func() {
+		for range subscription.Events() {
+		}
+	})
+
+	bus.Close()
+	wg.Wait()
+
+	// Subscribing after Close returns a closed channel
+	_, ok := <-bus.Subscribe(1, DeliveryDrop).Events()
+	assert.False(t, ok)
+}
+
+func TestLoopEngine_Subscribe(t *testing.T) {
+	config := &LoopConfig{Prompt: "Test task", MaxIterations: 2, PromisePhrase: "done"}
+	engine := NewLoopEngine(config, NewMockSDKClient())
+	subscription := engine.Subscribe(1, DeliveryBlock)
+	drainEvents(engine)
+
+	var kinds []EventKind
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		for event := range subscription.Events() {
+			assert.Equal(t, engine.RunID(), event.RunID())
+			kinds = append(kinds, event.Kind())
+		}
+	}()
+
+	_, err := engine.Start(context.Background())
+	<-done
+	require.NoError(t, err)
+
+	require.NotEmpty(t, kinds)
+	assert.Equal(t, EventLoopStart, kinds[0])
+	assert.Equal(t, EventLoopComplete, kinds[len(kinds)-1])
+	assert.Zero(t, engine.DroppedEvents())
+}
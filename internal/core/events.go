@@ -8,8 +8,133 @@ import (
 	"time"
 )
 
+// EventKind identifies the type of an event.
+type EventKind string
+
+// String returns the string representation of the kind.
+func (k EventKind) String() string {
+	return string(k)
+}
+
+const (
+	// EventLoopStart identifies a LoopStartEvent.
+	EventLoopStart EventKind = "loop_start"
+	// EventLoopComplete identifies a LoopCompleteEvent.
+	EventLoopComplete EventKind = "loop_complete"
+	// EventLoopFailed identifies a LoopFailedEvent.
+	EventLoopFailed EventKind = "loop_failed"
+	// EventLoopCancelled identifies a LoopCancelledEvent.
+	EventLoopCancelled EventKind = "loop_cancelled"
+	// EventIterationStart identifies an IterationStartEvent.
+	EventIterationStart EventKind = "iteration_start"
+	// EventIterationComplete identifies an IterationCompleteEvent.
+	EventIterationComplete EventKind = "iteration_complete"
+	// EventIterationTimeout identifies an IterationTimeoutEvent.
+	EventIterationTimeout EventKind = "iteration_timeout"
+	// EventAIResponse identifies an AIResponseEvent.
+	EventAIResponse EventKind = "ai_response"
+	// EventToolExecution identifies a ToolExecutionEvent.
+	EventToolExecution EventKind = "tool_execution"
+	// EventToolExecutionStart identifies a ToolExecutionStartEvent.
+	EventToolExecutionStart EventKind = "tool_execution_start"
+	// EventPromiseDetected identifies a PromiseDetectedEvent.
+	EventPromiseDetected EventKind = "promise_detected"
+	// EventVerificationFailed identifies a VerificationFailedEvent.
+	EventVerificationFailed EventKind = "verification_failed"
+	// EventDiagnostics identifies a DiagnosticsEvent.
+	EventDiagnostics EventKind = "diagnostics"
+	// EventSessionRotated identifies a SessionRotatedEvent.
+	EventSessionRotated EventKind = "session_rotated"
+	// EventGitCommit identifies a GitCommitEvent.
+	EventGitCommit EventKind = "git_commit"
+	// EventIterationReverted identifies an IterationRevertedEvent.
+	EventIterationReverted EventKind = "iteration_reverted"
+	// EventStagnation identifies a StagnationEvent.
+	EventStagnation EventKind = "stagnation"
+	// EventRepetitionDetected identifies a RepetitionDetectedEvent.
+	EventRepetitionDetected EventKind = "repetition_detected"
+	// EventUsage identifies an UsageEvent.
+	EventUsage EventKind = "usage"
+	// EventChecklistProgress identifies a ChecklistProgressEvent.
+	EventChecklistProgress EventKind = "checklist_progress"
+	// EventPhaseStart identifies a PhaseStartEvent.
+	EventPhaseStart EventKind = "phase_start"
+	// EventPhaseComplete identifies a PhaseCompleteEvent.
+	EventPhaseComplete EventKind = "phase_complete"
+	// EventReviewStart identifies a ReviewStartEvent.
+	EventReviewStart EventKind = "review_start"
+	// EventReviewerResponse identifies a ReviewerResponseEvent.
+	EventReviewerResponse EventKind = "reviewer_response"
+	// EventReviewComplete identifies a ReviewCompleteEvent.
+	EventReviewComplete EventKind = "review_complete"
+	// EventModelSwitched identifies a ModelSwitchedEvent.
+	EventModelSwitched EventKind = "model_switched"
+	// EventLoopPaused identifies a LoopPausedEvent.
+	EventLoopPaused EventKind = "loop_paused"
+	// EventLoopResumed identifies a LoopResumedEvent.
+	EventLoopResumed EventKind = "loop_resumed"
+	// EventSteering identifies a SteeringEvent.
+	EventSteering EventKind = "steering"
+	// EventToolApproval identifies a ToolApprovalEvent.
+	EventToolApproval EventKind = "tool_approval"
+	// EventToolDenied identifies a ToolDeniedEvent.
+	EventToolDenied EventKind = "tool_denied"
+	// EventCompletionSignaled identifies a CompletionSignaledEvent.
+	EventCompletionSignaled EventKind = "completion_signaled"
+	// EventBlocked identifies a BlockedEvent.
+	EventBlocked EventKind = "blocked"
+	// EventNote identifies a NoteEvent.
+	EventNote EventKind = "note"
+	// EventError identifies an ErrorEvent.
+	EventError EventKind = "error"
+)
+
+// Event is implemented by all events the loop engine publishes.
+type Event interface {
+	// Kind identifies the type of the event.
+	Kind() EventKind
+	// Timestamp is the time the event was published.
+	Timestamp() time.Time
+	// Sequence is the position of the event in the run, starting at 1.
+	Sequence() uint64
+	// RunID identifies the run that published the event.
+	RunID() string
+
+	stamp(runID string, sequence uint64, timestamp time.Time)
+}
+
+// eventMeta holds the metadata the event bus stamps on an event when it is published.
+type eventMeta struct {
+	timestamp time.Time
+	runID     string
+	sequence  uint64
+}
+
+// Timestamp returns the time the event was published.
+func (m *eventMeta) Timestamp() time.Time {
+	return m.timestamp
+}
+
+// Sequence returns the position of the event in the run, starting at 1.
+func (m *eventMeta) Sequence() uint64 {
+	return m.sequence
+}
+
+// RunID returns the identifier of the run that published the event.
+func (m *eventMeta) RunID() string {
+	return m.runID
+}
+
+// stamp records the publication metadata of the event.
+func (m *eventMeta) stamp(runID string, sequence uint64, timestamp time.Time) {
+	m.runID = runID
+	m.sequence = sequence
+	m.timestamp = timestamp
+}
+
 // LoopStartEvent indicates the loop has started.
 type LoopStartEvent struct {
+	eventMeta
 	// Config is the loop configuration.
 	Config *LoopConfig
 }
@@ -21,8 +146,14 @@ func NewLoopStartEvent(config *LoopConfig) *LoopStartEvent {
 	}
 }
 
+// Kind returns EventLoopStart.
+func (e *LoopStartEvent) Kind() EventKind {
+	return EventLoopStart
+}
+
 // LoopCompleteEvent indicates the loop completed successfully.
 type LoopCompleteEvent struct {
+	eventMeta
 	// Result contains the loop result.
 	Result *LoopResult
 }
@@ -34,8 +165,14 @@ func NewLoopCompleteEvent(result *LoopResult) *LoopCompleteEvent {
 	}
 }
 
+// Kind returns EventLoopComplete.
+func (e *LoopCompleteEvent) Kind() EventKind {
+	return EventLoopComplete
+}
+
 // LoopFailedEvent indicates the loop failed.
 type LoopFailedEvent struct {
+	eventMeta
 	// Error is the error that caused the failure.
 	Error error
 	// Result contains partial loop result.
@@ -50,8 +187,14 @@ func NewLoopFailedEvent(err error, result *LoopResult) *LoopFailedEvent {
 	}
 }
 
+// Kind returns EventLoopFailed.
+func (e *LoopFailedEvent) Kind() EventKind {
+	return EventLoopFailed
+}
+
 // LoopCancelledEvent indicates the loop was cancelled by the user.
 type LoopCancelledEvent struct {
+	eventMeta
 	// Result contains partial loop result.
 	Result *LoopResult
 }
@@ -63,8 +206,14 @@ func NewLoopCancelledEvent(result *LoopResult) *LoopCancelledEvent {
 	}
 }
 
+// Kind returns EventLoopCancelled.
+func (e *LoopCancelledEvent) Kind() EventKind {
+	return EventLoopCancelled
+}
+
 // IterationStartEvent indicates an iteration has started.
 type IterationStartEvent struct {
+	eventMeta
 	// Iteration is the iteration number (1-based).
 	Iteration int
 	// MaxIterations is the maximum number of iterations.
@@ -79,8 +228,14 @@ func NewIterationStartEvent(iteration, maxIterations int) *IterationStartEvent {
 	}
 }
 
+// Kind returns EventIterationStart.
+func (e *IterationStartEvent) Kind() EventKind {
+	return EventIterationStart
+}
+
 // IterationCompleteEvent indicates an iteration completed.
 type IterationCompleteEvent struct {
+	eventMeta
 	// Iteration is the iteration number (1-based).
 	Iteration int
 	// Duration is how long the iteration took.
@@ -95,8 +250,36 @@ func NewIterationCompleteEvent(iteration int, duration time.Duration) *Iteration
 	}
 }
 
+// Kind returns EventIterationComplete.
+func (e *IterationCompleteEvent) Kind() EventKind {
+	return EventIterationComplete
+}
+
+// IterationTimeoutEvent indicates an iteration was aborted because it exceeded the iteration timeout.
+type IterationTimeoutEvent struct {
+	eventMeta
+	// Iteration is the iteration number (1-based).
+	Iteration int
+	// Timeout is the iteration timeout that was exceeded.
+	Timeout time.Duration
+}
+
+// NewIterationTimeoutEvent creates a new IterationTimeoutEvent.
+func NewIterationTimeoutEvent(iteration int, timeout time.Duration) *IterationTimeoutEvent {
+	return &IterationTimeoutEvent{
+		Iteration: iteration,
+		Timeout:   timeout,
+	}
+}
+
+// Kind returns EventIterationTimeout.
+func (e *IterationTimeoutEvent) Kind() EventKind {
+	return EventIterationTimeout
+}
+
 // AIResponseEvent indicates AI response text was received.
 type AIResponseEvent struct {
+	eventMeta
 	// Text is the AI response text.
 	Text string
 	// Iteration is the current iteration number.
@@ -111,7 +294,13 @@ func NewAIResponseEvent(text string, iteration int) *AIResponseEvent {
 	}
 }
 
+// Kind returns EventAIResponse.
+func (e *AIResponseEvent) Kind() EventKind {
+	return EventAIResponse
+}
+
 type ToolEvent struct {
+	eventMeta
 	Parameters map[string]any
 	ToolName   string
 	Iteration  int
@@ -154,6 +343,11 @@ func NewToolExecutionEvent(toolName string, params map[string]any, result string
 	}
 }
 
+// Kind returns EventToolExecution.
+func (e *ToolExecutionEvent) Kind() EventKind {
+	return EventToolExecution
+}
+
 // ToolExecutionStartEvent indicates a tool execution has started.
 type ToolExecutionStartEvent struct {
 	ToolEvent
@@ -170,11 +364,17 @@ func NewToolExecutionStartEvent(toolName string, params map[string]any, iteratio
 	}
 }
 
+// Kind returns EventToolExecutionStart.
+func (e *ToolExecutionStartEvent) Kind() EventKind {
+	return EventToolExecutionStart
+}
+
 // PromiseDetectedEvent indicates the promise phrase was found.
 type PromiseDetectedEvent struct {
+	eventMeta
 	// Phrase is the promise phrase that was detected.
 	Phrase string
-	// Source is where the promise was found (e.g., "ai_response", "tool_output").
+	// Source is where the promise was found (e.g., "ai_response", or "tool" for ralph_signal_complete).
 	Source string
 	// Iteration is the iteration number where promise was found.
 	Iteration int
@@ -189,8 +389,598 @@ func NewPromiseDetectedEvent(phrase, source string, iteration int) *PromiseDetec
 	}
 }
 
+// Kind returns EventPromiseDetected.
+func (e *PromiseDetectedEvent) Kind() EventKind {
+	return EventPromiseDetected
+}
+
+// VerificationFailedEvent indicates the verification command rejected a promise.
+type VerificationFailedEvent struct {
+	eventMeta
+	// Command is the verification command that was run.
+	Command string
+	// Output is the captured command output.
+	Output string
+	// ExitCode is the exit code of the verification command.
+	ExitCode int
+	// Iteration is the iteration whose promise was rejected.
+	Iteration int
+}
+
+// NewVerificationFailedEvent creates a new VerificationFailedEvent.
+func NewVerificationFailedEvent(command, output string, exitCode, iteration int) *VerificationFailedEvent {
+	return &VerificationFailedEvent{
+		Command:   command,
+		Output:    output,
+		ExitCode:  exitCode,
+		Iteration: iteration,
+	}
+}
+
+// Kind returns EventVerificationFailed.
+func (e *VerificationFailedEvent) Kind() EventKind {
+	return EventVerificationFailed
+}
+
+// DiagnosticsEvent reports the diagnostic results collected after an iteration.
+type DiagnosticsEvent struct {
+	eventMeta
+	// Failures lists the structured failures.
+	Failures []Failure
+	// Passed is the number of passing tests or checks.
+	Passed int
+	// Failed is the number of failures.
+	Failed int
+	// Iteration is the iteration the diagnostics were collected after.
+	Iteration int
+}
+
+// NewDiagnosticsEvent creates a new DiagnosticsEvent from a report.
+func NewDiagnosticsEvent(report *DiagnosticsReport, iteration int) *DiagnosticsEvent {
+	return &DiagnosticsEvent{
+		Failures:  report.Failures,
+		Passed:    report.Passed,
+		Failed:    report.Failed(),
+		Iteration: iteration,
+	}
+}
+
+// Kind returns EventDiagnostics.
+func (e *DiagnosticsEvent) Kind() EventKind {
+	return EventDiagnostics
+}
+
+// SessionRotatedEvent indicates the SDK session was replaced before an iteration.
+type SessionRotatedEvent struct {
+	eventMeta
+	// Strategy is the session strategy that caused the rotation.
+	Strategy SessionStrategy
+	// Iteration is the iteration that starts with the new session.
+	Iteration int
+}
+
+// NewSessionRotatedEvent creates a new SessionRotatedEvent.
+func NewSessionRotatedEvent(strategy SessionStrategy, iteration int) *SessionRotatedEvent {
+	return &SessionRotatedEvent{
+		Strategy:  strategy,
+		Iteration: iteration,
+	}
+}
+
+// Kind returns EventSessionRotated.
+func (e *SessionRotatedEvent) Kind() EventKind {
+	return EventSessionRotated
+}
+
+// GitCommitEvent indicates the changes of an iteration were committed.
+type GitCommitEvent struct {
+	eventMeta
+	// Commit is the hash of the new commit, empty when there were no changes.
+	Commit string
+	// Message is the commit message.
+	Message string
+	// Iteration is the committed iteration.
+	Iteration int
+}
+
+// NewGitCommitEvent creates a new GitCommitEvent.
+func NewGitCommitEvent(commit, message string, iteration int) *GitCommitEvent {
+	return &GitCommitEvent{
+		Commit:    commit,
+		Message:   message,
+		Iteration: iteration,
+	}
+}
+
+// Kind returns EventGitCommit.
+func (e *GitCommitEvent) Kind() EventKind {
+	return EventGitCommit
+}
+
+// IterationRevertedEvent indicates an iteration was rolled back because it made things worse.
+type IterationRevertedEvent struct {
+	eventMeta
+	// Reason describes why the iteration was considered worse.
+	Reason string
+	// FailuresBefore is the failure count before the iteration.
+	FailuresBefore int
+	// FailuresAfter is the failure count the iteration left behind.
+	FailuresAfter int
+	// Iteration is the reverted iteration.
+	Iteration int
+}
+
+// NewIterationRevertedEvent creates a new IterationRevertedEvent.
+func NewIterationRevertedEvent(reason string, failuresBefore, failuresAfter, iteration int) *IterationRevertedEvent {
+	return &IterationRevertedEvent{
+		Reason:         reason,
+		FailuresBefore: failuresBefore,
+		FailuresAfter:  failuresAfter,
+		Iteration:      iteration,
+	}
+}
+
+// Kind returns EventIterationReverted.
+func (e *IterationRevertedEvent) Kind() EventKind {
+	return EventIterationReverted
+}
+
+// StagnationEvent indicates several iterations in a row did not change any files.
+type StagnationEvent struct {
+	eventMeta
+	// Policy is the stagnation policy being applied.
+	Policy StagnationPolicy
+	// Model is the model the loop switches to, empty when the model is unchanged.
+	Model string
+	// Iterations is the number of iterations without changes.
+	Iterations int
+	// Iteration is the iteration that triggered the event.
+	Iteration int
+}
+
+// NewStagnationEvent creates a new StagnationEvent.
+func NewStagnationEvent(policy StagnationPolicy, model string, iterations, iteration int) *StagnationEvent {
+	return &StagnationEvent{
+		Policy:     policy,
+		Model:      model,
+		Iterations: iterations,
+		Iteration:  iteration,
+	}
+}
+
+// Kind returns EventStagnation.
+func (e *StagnationEvent) Kind() EventKind {
+	return EventStagnation
+}
+
+// RepetitionDetectedEvent indicates the model keeps repeating itself.
+type RepetitionDetectedEvent struct {
+	eventMeta
+	// Repetition identifies what was repeated.
+	Repetition RepetitionKind
+	// Detail describes the repeated tool call or the start of the repeated response.
+	Detail string
+	// Count is the number of identical failing tool invocations.
+	Count int
+	// Similarity is the similarity to an earlier response, from 0 to 1.
+	Similarity float64
+	// Iteration is the iteration in which the repetition was detected.
+	Iteration int
+	// Interrupted is true when the iteration was cut short.
+	Interrupted bool
+}
+
+// NewRepetitionDetectedEvent creates a new RepetitionDetectedEvent.
+func NewRepetitionDetectedEvent(kind RepetitionKind, detail string, count int, similarity float64, iteration int) *RepetitionDetectedEvent {
+	return &RepetitionDetectedEvent{
+		Repetition: kind,
+		Detail:     detail,
+		Count:      count,
+		Similarity: similarity,
+		Iteration:  iteration,
+	}
+}
+
+// Kind returns EventRepetitionDetected.
+func (e *RepetitionDetectedEvent) Kind() EventKind {
+	return EventRepetitionDetected
+}
+
+// UsageEvent reports the tokens used by a model call.
+type UsageEvent struct {
+	eventMeta
+	// Usage is the token usage and cost of the model call.
+	Usage Usage
+	// Total is the cumulative usage of the run so far.
+	Total Usage
+	// Iteration is the iteration the model call belongs to.
+	Iteration int
+}
+
+// NewUsageEvent creates a new UsageEvent.
+func NewUsageEvent(usage, total Usage, iteration int) *UsageEvent {
+	return &UsageEvent{
+		Usage:     usage,
+		Total:     total,
+		Iteration: iteration,
+	}
+}
+
+// Kind returns EventUsage.
+func (e *UsageEvent) Kind() EventKind {
+	return EventUsage
+}
+
+// ChecklistProgressEvent reports the checklist progress after an iteration.
+type ChecklistProgressEvent struct {
+	eventMeta
+	// Done is the number of checked items.
+	Done int
+	// Total is the number of items.
+	Total int
+	// Iteration is the iteration after which the checklist was read.
+	Iteration int
+}
+
+// NewChecklistProgressEvent creates a new ChecklistProgressEvent.
+func NewChecklistProgressEvent(done, total, iteration int) *ChecklistProgressEvent {
+	return &ChecklistProgressEvent{
+		Done:      done,
+		Total:     total,
+		Iteration: iteration,
+	}
+}
+
+// Kind returns EventChecklistProgress.
+func (e *ChecklistProgressEvent) Kind() EventKind {
+	return EventChecklistProgress
+}
+
+// PhaseStartEvent indicates a phase of a multi-phase run started.
+type PhaseStartEvent struct {
+	eventMeta
+	// Name is the name of the phase.
+	Name string
+	// Model is the model used during the phase.
+	Model string
+	// Phase is the phase number (1-based).
+	Phase int
+	// Phases is the total number of phases.
+	Phases int
+	// Iteration is the first iteration of the phase.
+	Iteration int
+}
+
+// NewPhaseStartEvent creates a new PhaseStartEvent.
+func NewPhaseStartEvent(name, model string, phase, phases, iteration int) *PhaseStartEvent {
+	return &PhaseStartEvent{
+		Name:      name,
+		Model:     model,
+		Phase:     phase,
+		Phases:    phases,
+		Iteration: iteration,
+	}
+}
+
+// Kind returns EventPhaseStart.
+func (e *PhaseStartEvent) Kind() EventKind {
+	return EventPhaseStart
+}
+
+// PhaseCompleteEvent indicates the promise of a phase was accepted.
+type PhaseCompleteEvent struct {
+	eventMeta
+	// Result is the outcome of the phase.
+	Result PhaseResult
+	// Phase is the phase number (1-based).
+	Phase int
+	// Phases is the total number of phases.
+	Phases int
+}
+
+// NewPhaseCompleteEvent creates a new PhaseCompleteEvent.
+func NewPhaseCompleteEvent(result PhaseResult, phase, phases int) *PhaseCompleteEvent {
+	return &PhaseCompleteEvent{
+		Result: result,
+		Phase:  phase,
+		Phases: phases,
+	}
+}
+
+// Kind returns EventPhaseComplete.
+func (e *PhaseCompleteEvent) Kind() EventKind {
+	return EventPhaseComplete
+}
+
+// ReviewStartEvent indicates the reviewer started reviewing an iteration.
+type ReviewStartEvent struct {
+	eventMeta
+	// Model is the reviewer model.
+	Model string
+	// Iteration is the reviewed iteration.
+	Iteration int
+}
+
+// NewReviewStartEvent creates a new ReviewStartEvent.
+func NewReviewStartEvent(model string, iteration int) *ReviewStartEvent {
+	return &ReviewStartEvent{
+		Model:     model,
+		Iteration: iteration,
+	}
+}
+
+// Kind returns EventReviewStart.
+func (e *ReviewStartEvent) Kind() EventKind {
+	return EventReviewStart
+}
+
+// ReviewerResponseEvent contains text streamed by the reviewer, as opposed to AIResponseEvent from the worker.
+type ReviewerResponseEvent struct {
+	eventMeta
+	// Text is the streamed text.
+	Text string
+	// Iteration is the reviewed iteration.
+	Iteration int
+}
+
+// NewReviewerResponseEvent creates a new ReviewerResponseEvent.
+func NewReviewerResponseEvent(text string, iteration int) *ReviewerResponseEvent {
+	return &ReviewerResponseEvent{
+		Text:      text,
+		Iteration: iteration,
+	}
+}
+
+// Kind returns EventReviewerResponse.
+func (e *ReviewerResponseEvent) Kind() EventKind {
+	return EventReviewerResponse
+}
+
+// ReviewCompleteEvent contains the reviewer's verdict on an iteration.
+type ReviewCompleteEvent struct {
+	eventMeta
+	// Verdict is the reviewer's judgement.
+	Verdict ReviewVerdict
+	// Critique is the review carried into the next worker prompt.
+	Critique string
+	// Iteration is the reviewed iteration.
+	Iteration int
+}
+
+// NewReviewCompleteEvent creates a new ReviewCompleteEvent.
+func NewReviewCompleteEvent(verdict ReviewVerdict, critique string, iteration int) *ReviewCompleteEvent {
+	return &ReviewCompleteEvent{
+		Verdict:   verdict,
+		Critique:  critique,
+		Iteration: iteration,
+	}
+}
+
+// Kind returns EventReviewComplete.
+func (e *ReviewCompleteEvent) Kind() EventKind {
+	return EventReviewComplete
+}
+
+// ModelSwitchedEvent indicates the loop fell back to the next model of the fallback chain.
+type ModelSwitchedEvent struct {
+	eventMeta
+	// From is the model that was replaced.
+	From string
+	// To is the model the loop continues with.
+	To string
+	// Reason describes why the previous model was replaced.
+	Reason FallbackReason
+	// Iteration is the iteration after which the model was replaced.
+	Iteration int
+}
+
+// NewModelSwitchedEvent creates a new ModelSwitchedEvent.
+func NewModelSwitchedEvent(from, to string, reason FallbackReason, iteration int) *ModelSwitchedEvent {
+	return &ModelSwitchedEvent{
+		From:      from,
+		To:        to,
+		Reason:    reason,
+		Iteration: iteration,
+	}
+}
+
+// Kind returns EventModelSwitched.
+func (e *ModelSwitchedEvent) Kind() EventKind {
+	return EventModelSwitched
+}
+
+// LoopPausedEvent indicates the loop is paused between iterations.
+type LoopPausedEvent struct {
+	eventMeta
+	// Iteration is the last completed iteration.
+	Iteration int
+}
+
+// NewLoopPausedEvent creates a new LoopPausedEvent.
+func NewLoopPausedEvent(iteration int) *LoopPausedEvent {
+	return &LoopPausedEvent{
+		Iteration: iteration,
+	}
+}
+
+// Kind returns EventLoopPaused.
+func (e *LoopPausedEvent) Kind() EventKind {
+	return EventLoopPaused
+}
+
+// LoopResumedEvent indicates a paused loop continues with the next iteration.
+type LoopResumedEvent struct {
+	eventMeta
+	// Paused is how long the loop was paused.
+	Paused time.Duration
+	// Iteration is the last completed iteration.
+	Iteration int
+}
+
+// NewLoopResumedEvent creates a new LoopResumedEvent.
+func NewLoopResumedEvent(paused time.Duration, iteration int) *LoopResumedEvent {
+	return &LoopResumedEvent{
+		Paused:    paused,
+		Iteration: iteration,
+	}
+}
+
+// Kind returns EventLoopResumed.
+func (e *LoopResumedEvent) Kind() EventKind {
+	return EventLoopResumed
+}
+
+// SteeringEvent indicates the user steered the loop with a message.
+type SteeringEvent struct {
+	eventMeta
+	// Message is the steering message.
+	Message string
+	// Iteration is the iteration that receives the message.
+	Iteration int
+	// Immediate is true when the message was sent to the running iteration
+	// rather than queued for the next iteration prompt.
+	Immediate bool
+}
+
+// NewSteeringEvent creates a new SteeringEvent.
+func NewSteeringEvent(message string, immediate bool, iteration int) *SteeringEvent {
+	return &SteeringEvent{
+		Message:   message,
+		Immediate: immediate,
+		Iteration: iteration,
+	}
+}
+
+// Kind returns EventSteering.
+func (e *SteeringEvent) Kind() EventKind {
+	return EventSteering
+}
+
+// ToolApprovalEvent indicates the user answered whether a tool may run.
+type ToolApprovalEvent struct {
+	eventMeta
+	// Tool is the name of the tool that asked for approval.
+	Tool string
+	// Summary describes what the tool was about to do.
+	Summary string
+	// Answer is the user's answer.
+	Answer ApprovalAnswer
+	// Iteration is the current iteration number.
+	Iteration int
+}
+
+// NewToolApprovalEvent creates a new ToolApprovalEvent.
+func NewToolApprovalEvent(tool, summary string, answer ApprovalAnswer, iteration int) *ToolApprovalEvent {
+	return &ToolApprovalEvent{
+		Tool:      tool,
+		Summary:   summary,
+		Answer:    answer,
+		Iteration: iteration,
+	}
+}
+
+// Kind returns EventToolApproval.
+func (e *ToolApprovalEvent) Kind() EventKind {
+	return EventToolApproval
+}
+
+// ToolDeniedEvent indicates the tool policy refused to run a tool.
+type ToolDeniedEvent struct {
+	eventMeta
+	// Tool is the name of the refused tool.
+	Tool string
+	// Summary describes what the tool was about to do.
+	Summary string
+	// Rule is the policy rule that matched, e.g. "deny write", or the default action.
+	Rule string
+	// Iteration is the current iteration number.
+	Iteration int
+}
+
+// NewToolDeniedEvent creates a new ToolDeniedEvent.
+func NewToolDeniedEvent(tool, summary, rule string, iteration int) *ToolDeniedEvent {
+	return &ToolDeniedEvent{
+		Tool:      tool,
+		Summary:   summary,
+		Rule:      rule,
+		Iteration: iteration,
+	}
+}
+
+// Kind returns EventToolDenied.
+func (e *ToolDeniedEvent) Kind() EventKind {
+	return EventToolDenied
+}
+
+// CompletionSignaledEvent indicates the model called ralph_signal_complete.
+type CompletionSignaledEvent struct {
+	eventMeta
+	// Summary is the model's summary of the completed work.
+	Summary string
+	// Iteration is the current iteration number.
+	Iteration int
+}
+
+// NewCompletionSignaledEvent creates a new CompletionSignaledEvent.
+func NewCompletionSignaledEvent(summary string, iteration int) *CompletionSignaledEvent {
+	return &CompletionSignaledEvent{
+		Summary:   summary,
+		Iteration: iteration,
+	}
+}
+
+// Kind returns EventCompletionSignaled.
+func (e *CompletionSignaledEvent) Kind() EventKind {
+	return EventCompletionSignaled
+}
+
+// BlockedEvent indicates the model called ralph_report_blocked.
+type BlockedEvent struct {
+	eventMeta
+	// Reason is what blocks the model.
+	Reason string
+	// Iteration is the current iteration number.
+	Iteration int
+}
+
+// NewBlockedEvent creates a new BlockedEvent.
+func NewBlockedEvent(reason string, iteration int) *BlockedEvent {
+	return &BlockedEvent{
+		Reason:    reason,
+		Iteration: iteration,
+	}
+}
+
+// Kind returns EventBlocked.
+func (e *BlockedEvent) Kind() EventKind {
+	return EventBlocked
+}
+
+// NoteEvent indicates the model left a note for the user with ralph_note.
+type NoteEvent struct {
+	eventMeta
+	// Text is the note.
+	Text string
+	// Iteration is the current iteration number.
+	Iteration int
+}
+
+// NewNoteEvent creates a new NoteEvent.
+func NewNoteEvent(text string, iteration int) *NoteEvent {
+	return &NoteEvent{
+		Text:      text,
+		Iteration: iteration,
+	}
+}
+
+// Kind returns EventNote.
+func (e *NoteEvent) Kind() EventKind {
+	return EventNote
+}
+
 // ErrorEvent indicates an error occurred.
 type ErrorEvent struct {
+	eventMeta
 	// Error is the error that occurred.
 	Error error
 	// Iteration is the current iteration number (0 if not in iteration).
@@ -207,3 +997,8 @@ func NewErrorEvent(err error, iteration int, recoverable bool) *ErrorEvent {
 		Recoverable: recoverable,
 	}
 }
+
+// Kind returns EventError.
+func (e *ErrorEvent) Kind() EventKind {
+	return EventError
+}
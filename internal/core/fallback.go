@@ -0,0 +1,162 @@
+// Package core provides automatic model fallback for the loop engine.
+
+package core
+
+import (
+	"errors"
+	"fmt"
+	"regexp"
+	"strings"
+
+	"github.com/JanDeDobbeleer/copilot-ralph/internal/sdk"
+)
+
+// FallbackReason describes why the loop gave up on a model.
+type FallbackReason string
+
+const (
+	// FallbackError is used after iterations that ended with session errors.
+	FallbackError FallbackReason = "error"
+	// FallbackRateLimit is used as soon as the model is rate limited.
+	FallbackRateLimit FallbackReason = "rate-limit"
+	// FallbackRefusal is used after iterations in which the model refused the task.
+	FallbackRefusal FallbackReason = "refusal"
+	// FallbackStagnation is used when iterations stopped changing files.
+	FallbackStagnation FallbackReason = "stagnation"
+)
+
+// String returns the string representation of the reason.
+func (r FallbackReason) String() string {
+	return string(r)
+}
+
+// defaultFallbackAfter is the number of failing iterations in a row before falling back.
+const defaultFallbackAfter = 2
+
+// refusalPattern matches responses that open by declining the task.
+var refusalPattern = regexp.MustCompile(`(?i)^(?:i'm sorry|i am sorry|sorry|i apologi[sz]e)?[,.!]?\s*(?:but\s+)?` +
+	`i\s+(?:can't|cannot|can not|won't|will not|am unable to|'m unable to|am not able to)\s+` +
+	`(?:help|assist|comply|do that|provide|complete)`)
+
+// fallbackNote tells the new model it takes over the task.
+const fallbackNote = "You are taking over this task from %s, which was replaced after %s. " +
+	"Review the working directory for the progress made so far and continue from there."
+
+// isRateLimit reports whether err is a session error the SDK classified as a rate limit or exhausted quota.
+func isRateLimit(err error) bool {
+	var sessionErr *sdk.SessionError
+	return errors.As(err, &sessionErr) && sessionErr.RateLimited()
+}
+
+// isRefusal reports whether the response declines the task.
+func isRefusal(response string) bool {
+	return refusalPattern.MatchString(strings.TrimSpace(response))
+}
+
+// errorFallbackReason classifies an SDK error for the fallback chain.
+func errorFallbackReason(err error) FallbackReason {
+	if isRateLimit(err) {
+		return FallbackRateLimit
+	}
+
+	return FallbackError
+}
+
+// recordModel records the model used for the iteration.
+// Must be called with lock held.
+func (e *LoopEngine) recordModel() {
+	model := e.config.Model
+	if e.sdk != nil {
+		model = e.sdk.Model()
+	}
+
+	e.iterationModels = append(e.iterationModels, model)
+}
+
+// useFallbackModel selects the fallback model a resumed run had switched to.
+func (e *LoopEngine) useFallbackModel() error {
+	if e.fallback == 0 || e.fallback > len(e.config.FallbackModels) {
+		return nil
+	}
+
+	model := e.config.FallbackModels[e.fallback-1]
+	if model == e.sdk.Model() {
+		return nil
+	}
+
+	switcher, ok := e.sdk.(ModelSwitcher)
+	if !ok {
+		return fmt.Errorf("SDK client cannot switch to model %s", model)
+	}
+
+	return switcher.SetModel(model)
+}
+
+// checkFallback counts iterations that failed on the current model and switches to the
+// next fallback model once FallbackAfter iterations in a row failed. Rate limits switch immediately.
+func (e *LoopEngine) checkFallback(iteration int, reason FallbackReason) error {
+	if len(e.config.FallbackModels) == 0 {
+		return nil
+	}
+
+	if reason == "" {
+		e.modelFailures = 0
+		return nil
+	}
+
+	e.modelFailures++
+
+	after := e.config.FallbackAfter
+	if after <= 0 {
+		after = defaultFallbackAfter
+	}
+
+	if reason != FallbackRateLimit && e.modelFailures < after {
+		return nil
+	}
+
+	_, err := e.fallBack(reason, iteration)
+	return err
+}
+
+// fallBack replaces the session with one using the next fallback model.
+// It returns false when the fallback chain is exhausted.
+func (e *LoopEngine) fallBack(reason FallbackReason, iteration int) (bool, error) {
+	if e.sdk == nil {
+		return false, nil
+	}
+
+	e.mu.Lock()
+	if e.fallback >= len(e.config.FallbackModels) {
+		e.mu.Unlock()
+		return false, nil
+	}
+	model := e.config.FallbackModels[e.fallback]
+	e.fallback++
+	e.mu.Unlock()
+
+	from := e.sdk.Model()
+	if err := e.switchModel(model); err != nil {
+		return false, err
+	}
+
+	e.modelFailures = 0
+	e.emit(NewModelSwitchedEvent(from, model, reason, iteration))
+	e.carry(fmt.Sprintf(fallbackNote, from, fallbackDescription(reason)))
+
+	return true, nil
+}
+
+// fallbackDescription describes the reason of a fallback for the next prompt.
+func fallbackDescription(reason FallbackReason) string {
+	switch reason {
+	case FallbackRateLimit:
+		return "it was rate limited"
+	case FallbackRefusal:
+		return "it repeatedly declined the task"
+	case FallbackStagnation:
+		return "several iterations without file changes"
+	default:
+		return "repeated errors"
+	}
+}
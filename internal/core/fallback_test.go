@@ -0,0 +1,244 @@
+package core
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/JanDeDobbeleer/copilot-ralph/internal/sdk"
+)
+
+func TestIsRefusal(t *testing.T) {
+	tests := []struct {
+		response string
+		want     bool
+	}{
+		{response: "I'm sorry, but I can't help with that.", want: true},
+		{response: "I cannot assist with this request.", want: true},
+		{response: "  Sorry, I am unable to comply.", want: true},
+		{response: "I can't find the file, creating it instead.", want: false},
+		{response: "Updated the parser. I cannot help noticing the tests are slow.", want: false},
+		{response: "", want: false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.response, func(t *testing.T) {
+			assert.Equal(t, tt.want, isRefusal(tt.response))
+		})
+	}
+}
+
+func TestErrorFallbackReason(t *testing.T) {
+	tests := []struct {
+		err  error
+		name string
+		want FallbackReason
+	}{
+		{name: "rate limit", err: &sdk.SessionError{Type: sdk.ErrorTypeRateLimit, Message: "Too Many Requests"}, want: FallbackRateLimit},
+		{name: "quota", err: &sdk.SessionError{Type: sdk.ErrorTypeQuota, Message: "Premium request quota exhausted"}, want: FallbackRateLimit},
+		{name: "wrapped rate limit", err: fmt.Errorf("max retries exceeded: %w", &sdk.SessionError{Type: sdk.ErrorTypeRateLimit}), want: FallbackRateLimit},
+		{name: "other session error", err: &sdk.SessionError{Type: "query", Message: "internal server error"}, want: FallbackError},
+		{name: "rate limit words in message", err: &sdk.SessionError{Message: "failed to read quota.go: 429 lines"}, want: FallbackError},
+		{name: "plain error", err: errors.New("SDK error: 429 Too Many Requests"), want: FallbackError},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.want, errorFallbackReason(tt.err))
+		})
+	}
+}
+
+func TestLoopEngine_Fallback(t *testing.T) {
+	tests := []struct {
+		name       string
+		err        error
+		response   string
+		wantIter   int
+		wantModels []string
+		wantReason FallbackReason
+	}{
+		{
+			name:       "repeated errors",
+			err:        errors.New("SDK error: internal server error"),
+			wantIter:   2,
+			wantModels: []string{"mock-model", "mock-model", "fallback-a"},
+			wantReason: FallbackError,
+		},
+		{
+			name:       "rate limit",
+			err:        &sdk.SessionError{Type: sdk.ErrorTypeRateLimit, Message: "Too Many Requests"},
+			wantIter:   1,
+			wantModels: []string{"mock-model", "fallback-a"},
+			wantReason: FallbackRateLimit,
+		},
+		{
+			name:       "refusal",
+			response:   "I'm sorry, but I can't help with that.",
+			wantIter:   2,
+			wantModels: []string{"mock-model", "mock-model", "fallback-a"},
+			wantReason: FallbackRefusal,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// Only the original model fails, the fallback model completes the task
+			mockSDK := NewMockSDKClient()
+			mockSDK.OnPrompt = func(string) {
+				mockSDK.SessionError = nil
+				mockSDK.ResponseText = "Done <promise>done</promise>"
+				if mockSDK.model == "mock-model" {
+					mockSDK.SessionError = tt.err
+					mockSDK.ResponseText = tt.response
+				}
+			}
+
+			config := &LoopConfig{
+				Prompt:         "Test task",
+				MaxIterations:  5,
+				PromisePhrase:  "done",
+				FallbackModels: []string{"fallback-a", "fallback-b"},
+			}
+			engine := NewLoopEngine(config, mockSDK)
+
+			var switches []*ModelSwitchedEvent
+			done := make(chan struct{})
+			go func() {
+				defer close(done)
+				for event := range engine.Events() {
+					if ev, ok := event.(*ModelSwitchedEvent); ok {
+						switches = append(switches, ev)
+					}
+				}
+			}()
+
+			result, err := engine.Start(context.Background())
+			<-done
+			require.NoError(t, err)
+
+			assert.True(t, result.PromiseReached())
+			assert.Equal(t, tt.wantModels, result.IterationModels)
+			assert.Equal(t, 2, result.Sessions)
+
+			require.Len(t, switches, 1)
+			assert.Equal(t, "mock-model", switches[0].From)
+			assert.Equal(t, "fallback-a", switches[0].To)
+			assert.Equal(t, tt.wantReason, switches[0].Reason)
+			assert.Equal(t, tt.wantIter, switches[0].Iteration)
+
+			assert.Contains(t, mockSDK.Prompts[len(mockSDK.Prompts)-1], "You are taking over this task from mock-model")
+		})
+	}
+}
+
+func TestLoopEngine_FallbackOutlastsPhases(t *testing.T) {
+	// The first model is rate limited, the fallback model plans and implements
+	mockSDK := NewMockSDKClient()
+	mockSDK.OnPrompt = func(prompt string) {
+		mockSDK.SessionError = nil
+		mockSDK.ResponseText = "Planned <promise>PLANNED</promise>"
+		if strings.Contains(prompt, "## Phase 2/2: implement") {
+			mockSDK.ResponseText = "Built <promise>BUILT</promise>"
+		}
+		if mockSDK.model == "mock-model" {
+			mockSDK.SessionError = &sdk.SessionError{Type: sdk.ErrorTypeRateLimit, Message: "Too Many Requests"}
+			mockSDK.ResponseText = "Working"
+		}
+	}
+
+	config := &LoopConfig{
+		Prompt:         "Build a parser",
+		Model:          "mock-model",
+		MaxIterations:  5,
+		PromisePhrase:  "done",
+		FallbackModels: []string{"fallback-a"},
+	}
+	plan := &Plan{Phases: []Phase{
+		{Name: "plan", Prompt: "Write PLAN.md", Promise: "PLANNED"},
+		{Name: "implement", Prompt: "Implement", Promise: "BUILT", Model: "big-model"},
+	}}
+	plan.Apply(config)
+
+	engine := NewLoopEngine(config, mockSDK)
+
+	var starts []*PhaseStartEvent
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		for event := range engine.Events() {
+			if ev, ok := event.(*PhaseStartEvent); ok {
+				starts = append(starts, ev)
+			}
+		}
+	}()
+
+	result, err := engine.Start(context.Background())
+	<-done
+	require.NoError(t, err)
+
+	assert.True(t, result.PromiseReached())
+	assert.Equal(t, []string{"mock-model", "fallback-a", "fallback-a"}, result.IterationModels)
+
+	require.Len(t, starts, 2)
+	assert.Equal(t, "fallback-a", starts[1].Model)
+}
+
+func TestLoopEngine_FallbackChainExhausted(t *testing.T) {
+	mockSDK := NewMockSDKClient()
+	mockSDK.SessionError = &sdk.SessionError{Type: sdk.ErrorTypeRateLimit, Message: "rate limit exceeded"}
+
+	config := &LoopConfig{
+		Prompt:         "Test task",
+		MaxIterations:  4,
+		PromisePhrase:  "done",
+		FallbackModels: []string{"fallback-a"},
+	}
+	engine := NewLoopEngine(config, mockSDK)
+	drainEvents(engine)
+
+	result, err := engine.Start(context.Background())
+	require.NoError(t, err)
+
+	assert.Equal(t, 4, result.Iterations)
+	assert.Equal(t, []string{"mock-model", "fallback-a", "fallback-a", "fallback-a"}, result.IterationModels)
+	assert.Equal(t, 2, result.Sessions)
+}
+
+func TestLoopEngine_FallbackOnStagnation(t *testing.T) {
+	dir := t.TempDir()
+
+	// Only the first iteration changes a file
+	mockSDK := NewMockSDKClient()
+	mockSDK.OnPrompt = func(prompt string) {
+		if strings.HasPrefix(prompt, "[Iteration 1/") {
+			require.NoError(t, os.WriteFile(filepath.Join(dir, "work.txt"), []byte("work"), 0o644))
+		}
+	}
+
+	config := &LoopConfig{
+		Prompt:          "Test task",
+		MaxIterations:   10,
+		PromisePhrase:   "done",
+		WorkingDir:      dir,
+		StagnationLimit: 2,
+		FallbackModels:  []string{"fallback-a"},
+	}
+	engine := NewLoopEngine(config, mockSDK)
+	drainEvents(engine)
+
+	// The first stagnation falls back, the second one applies the stop policy
+	result, err := engine.Start(context.Background())
+	require.ErrorIs(t, err, ErrStagnation)
+
+	assert.Equal(t, 5, result.Iterations)
+	assert.Equal(t, []string{"mock-model", "mock-model", "mock-model", "fallback-a", "fallback-a"}, result.IterationModels)
+	assert.Contains(t, mockSDK.Prompts[3], "You are stuck")
+}
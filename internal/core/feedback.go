@@ -0,0 +1,337 @@
+// Package core provides structured diagnostics feedback for the loop engine.
+
+package core
+
+import (
+	"bufio"
+	"encoding/json"
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+// maxPromptFailures is the maximum number of failures listed in an iteration prompt.
+const maxPromptFailures = 20
+
+// genericFailurePattern matches compiler style "file:line[:col]: message" lines.
+var genericFailurePattern = regexp.MustCompile(`^\s*([^\s:]+\.\w+):(\d+)(?::\d+)?:\s*(.+)$`)
+
+// Failure is a single structured diagnostic failure.
+type Failure struct {
+	// File is the file the failure points at, if known.
+	File string
+	// Source identifies what reported the failure (test name, linter, command).
+	Source string
+	// Message describes the failure.
+	Message string
+	// Line is the line number in File, 0 if unknown.
+	Line int
+}
+
+// String returns a compact single-line description of the failure.
+func (f Failure) String() string {
+	var builder strings.Builder
+
+	if f.File != "" {
+		builder.WriteString(f.File)
+		if f.Line > 0 {
+			builder.WriteString(fmt.Sprintf(":%d", f.Line))
+		}
+		builder.WriteString(": ")
+	}
+
+	if f.Source != "" {
+		builder.WriteString(f.Source)
+		builder.WriteString(": ")
+	}
+
+	builder.WriteString(f.Message)
+
+	return builder.String()
+}
+
+// DiagnosticsReport aggregates the parsed output of all diagnostic commands.
+type DiagnosticsReport struct {
+	// Failures lists the structured failures.
+	Failures []Failure
+	// Passed counts passing tests, or passing commands for formats without test results.
+	Passed int
+}
+
+// Failed returns the number of failures.
+func (r *DiagnosticsReport) Failed() int {
+	return len(r.Failures)
+}
+
+// runDiagnostics runs the configured diagnostic commands and parses their output.
+func (e *LoopEngine) runDiagnostics() (*DiagnosticsReport, error) {
+	report := &DiagnosticsReport{}
+
+	for _, command := range e.config.Diagnostics {
+		result, err := runShellCommand(e.ctx, e.config.WorkingDir, command)
+		if err != nil {
+			return nil, fmt.Errorf("diagnostic command failed to run: %w", err)
+		}
+
+		failures, passed := parseDiagnostics(result.Output)
+
+		// A failing command without recognizable output must not look like a pass
+		if len(failures) == 0 && !result.Passed() {
+			failures = append(failures, Failure{
+				Source:  command,
+				Message: fmt.Sprintf("exited with code %d", result.ExitCode),
+			})
+		}
+
+		if len(failures) == 0 && passed == 0 {
+			passed = 1
+		}
+
+		report.Failures = append(report.Failures, failures...)
+		report.Passed += passed
+	}
+
+	return report, nil
+}
+
+// parseDiagnostics extracts failures from diagnostic output.
+// It understands `go test -json` events, golangci-lint JSON reports and generic
+// "file:line: message" lines, which may be mixed in one output.
+// It returns the failures and the number of passing tests.
+func parseDiagnostics(output string) ([]Failure, int) {
+	var failures []Failure
+	passed := 0
+	tests := newGoTestCollector()
+
+	scanner := bufio.NewScanner(strings.NewReader(output))
+	scanner.Buffer(make([]byte, 0, 64*1024), 1024*1024)
+
+	for scanner.Scan() {
+		line := scanner.Text()
+		trimmed := strings.TrimSpace(line)
+
+		if strings.HasPrefix(trimmed, "{") {
+			if tests.add(trimmed) {
+				continue
+			}
+
+			if lint, ok := parseGolangCILint(trimmed); ok {
+				failures = append(failures, lint...)
+				continue
+			}
+		}
+
+		if failure, ok := parseGenericFailure(line); ok {
+			failures = append(failures, failure)
+		}
+	}
+
+	testFailures, testsPassed := tests.result()
+	failures = append(failures, testFailures...)
+	passed += testsPassed
+
+	return failures, passed
+}
+
+// parseGenericFailure parses a "file:line[:col]: message" line.
+func parseGenericFailure(line string) (Failure, bool) {
+	match := genericFailurePattern.FindStringSubmatch(strings.TrimRight(line, "\r\n"))
+	if match == nil {
+		return Failure{}, false
+	}
+
+	lineNumber, _ := strconv.Atoi(match[2])
+
+	return Failure{
+		File:    match[1],
+		Line:    lineNumber,
+		Message: strings.TrimSpace(match[3]),
+	}, true
+}
+
+// goTestEvent is a single `go test -json` event.
+type goTestEvent struct {
+	Action  string `json:"Action"`
+	Package string `json:"Package"`
+	Test    string `json:"Test"`
+	Output  string `json:"Output"`
+}
+
+// goTestCollector accumulates `go test -json` events into test results.
+type goTestCollector struct {
+	output map[string][]string
+	build  []Failure
+	failed []string
+	passed int
+}
+
+func newGoTestCollector() *goTestCollector {
+	return &goTestCollector{output: make(map[string][]string)}
+}
+
+// add consumes a JSON line and reports whether it was a `go test -json` event.
+func (c *goTestCollector) add(line string) bool {
+	var event goTestEvent
+	if err := json.Unmarshal([]byte(line), &event); err != nil || event.Action == "" {
+		return false
+	}
+
+	// Build errors are reported as package level output
+	if event.Action == "build-output" {
+		if failure, ok := parseGenericFailure(event.Output); ok {
+			c.build = append(c.build, failure)
+		}
+		return true
+	}
+
+	if event.Test == "" {
+		return true
+	}
+
+	key := event.Package + "." + event.Test
+
+	switch event.Action {
+	case "output":
+		c.output[key] = append(c.output[key], event.Output)
+	case "pass":
+		c.passed++
+	case "fail":
+		c.failed = append(c.failed, key)
+	}
+
+	return true
+}
+
+// result returns a failure per failed test, pointing at the first reported file location.
+// Parent tests that only failed because of a failing subtest are skipped.
+func (c *goTestCollector) result() ([]Failure, int) {
+	failures := c.build
+
+	for _, key := range c.failed {
+		if c.hasFailedSubtest(key) {
+			continue
+		}
+
+		failure := Failure{
+			Source:  testName(key),
+			Message: "test failed",
+		}
+
+		for _, output := range c.output[key] {
+			if location, ok := parseGenericFailure(output); ok {
+				failure.File = location.File
+				failure.Line = location.Line
+				failure.Message = location.Message
+				break
+			}
+		}
+
+		failures = append(failures, failure)
+	}
+
+	return failures, c.passed
+}
+
+// hasFailedSubtest reports whether a subtest of the given test failed.
+func (c *goTestCollector) hasFailedSubtest(key string) bool {
+	for _, other := range c.failed {
+		if strings.HasPrefix(other, key+"/") {
+			return true
+		}
+	}
+
+	return false
+}
+
+// testName strips the package path from a "package.TestName" key.
+func testName(key string) string {
+	name := key[strings.LastIndex(key, "/")+1:]
+	if index := strings.Index(key, ".Test"); index >= 0 {
+		name = key[index+1:]
+	}
+
+	return name
+}
+
+// golangciReport is the subset of the golangci-lint JSON report Ralph uses.
+type golangciReport struct {
+	Issues []struct {
+		FromLinter string `json:"FromLinter"`
+		Text       string `json:"Text"`
+		Pos        struct {
+			Filename string `json:"Filename"`
+			Line     int    `json:"Line"`
+		} `json:"Pos"`
+	} `json:"Issues"`
+}
+
+// parseGolangCILint parses a golangci-lint JSON report line.
+func parseGolangCILint(line string) ([]Failure, bool) {
+	if !strings.Contains(line, `"Issues"`) {
+		return nil, false
+	}
+
+	var report golangciReport
+	if err := json.Unmarshal([]byte(line), &report); err != nil {
+		return nil, false
+	}
+
+	failures := make([]Failure, 0, len(report.Issues))
+	for _, issue := range report.Issues {
+		failures = append(failures, Failure{
+			File:    issue.Pos.Filename,
+			Line:    issue.Pos.Line,
+			Source:  issue.FromLinter,
+			Message: issue.Text,
+		})
+	}
+
+	return failures, true
+}
+
+// failuresSection renders the compact "current failures" block for the next prompt.
+func failuresSection(report *DiagnosticsReport) string {
+	if report == nil || report.Failed() == 0 {
+		return ""
+	}
+
+	var builder strings.Builder
+
+	builder.WriteString(fmt.Sprintf("## Current failures (%d)\n\n", report.Failed()))
+
+	for i, failure := range report.Failures {
+		if i == maxPromptFailures {
+			builder.WriteString(fmt.Sprintf("- ... and %d more\n", report.Failed()-maxPromptFailures))
+			break
+		}
+
+		builder.WriteString("- ")
+		builder.WriteString(failure.String())
+		builder.WriteString("\n")
+	}
+
+	return builder.String()
+}
+
+// collectDiagnostics runs the diagnostic commands after an iteration, records the
+// failure count and emits a DiagnosticsEvent. The report feeds the next prompt.
+func (e *LoopEngine) collectDiagnostics(iteration int) error {
+	if len(e.config.Diagnostics) == 0 {
+		return nil
+	}
+
+	report, err := e.runDiagnostics()
+	if err != nil {
+		return err
+	}
+
+	e.mu.Lock()
+	e.diagnostics = report
+	e.failureHistory = append(e.failureHistory, report.Failed())
+	e.mu.Unlock()
+
+	e.emit(NewDiagnosticsEvent(report, iteration))
+
+	return nil
+}
@@ -0,0 +1,169 @@
+package core
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseDiagnostics(t *testing.T) {
+	tests := []struct {
+		name         string
+		output       string
+		wantFailures []Failure
+		wantPassed   int
+	}{
+		{
+			name:   "empty output",
+			output: "",
+		},
+		{
+			name:   "generic compiler output",
+			output: "# example\n./parser.go:12:5: undefined: tokenize\nsome noise\n",
+			wantFailures: []Failure{
+				{File: "./parser.go", Line: 12, Message: "undefined: tokenize"},
+			},
+		},
+		{
+			name: "go test json",
+			output: `{"Action":"run","Package":"example/parser","Test":"TestParse"}
+{"Action":"output","Package":"example/parser","Test":"TestParse","Output":"    parser_test.go:42: expected 3, got 2\n"}
+{"Action":"fail","Package":"example/parser","Test":"TestParse"}
+{"Action":"pass","Package":"example/parser","Test":"TestLex"}
+{"Action":"pass","Package":"example/parser","Test":"TestEmpty"}
+{"Action":"fail","Package":"example/parser"}`,
+			wantFailures: []Failure{
+				{File: "parser_test.go", Line: 42, Source: "TestParse", Message: "expected 3, got 2"},
+			},
+			wantPassed: 2,
+		},
+		{
+			name: "go test json skips parents of failed subtests",
+			output: `{"Action":"fail","Package":"example/parser","Test":"TestParse/empty"}
+{"Action":"fail","Package":"example/parser","Test":"TestParse"}`,
+			wantFailures: []Failure{
+				{Source: "TestParse/empty", Message: "test failed"},
+			},
+		},
+		{
+			name:   "go test json build output",
+			output: `{"ImportPath":"example/parser","Action":"build-output","Output":"parser.go:3:2: undefined: x\n"}`,
+			wantFailures: []Failure{
+				{File: "parser.go", Line: 3, Message: "undefined: x"},
+			},
+		},
+		{
+			name:   "golangci-lint json",
+			output: `{"Issues":[{"FromLinter":"errcheck","Text":"Error return value is not checked","Pos":{"Filename":"main.go","Line":7}}],"Report":{}}`,
+			wantFailures: []Failure{
+				{File: "main.go", Line: 7, Source: "errcheck", Message: "Error return value is not checked"},
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			failures, passed := parseDiagnostics(tt.output)
+
+			assert.Equal(t, tt.wantFailures, failures)
+			assert.Equal(t, tt.wantPassed, passed)
+		})
+	}
+}
+
+func TestFailuresSection(t *testing.T) {
+	assert.Empty(t, failuresSection(nil))
+	assert.Empty(t, failuresSection(&DiagnosticsReport{Passed: 3}))
+
+	report := &DiagnosticsReport{}
+	for range maxPromptFailures + 2 {
+		report.Failures = append(report.Failures, Failure{File: "a.go", Line: 1, Message: "broken"})
+	}
+
+	section := failuresSection(report)
+	assert.Contains(t, section, "## Current failures (22)")
+	assert.Contains(t, section, "- a.go:1: broken")
+	assert.Contains(t, section, "... and 2 more")
+}
+
+func TestLoopEngine_Diagnostics(t *testing.T) {
+	mockSDK := NewMockSDKClient()
+
+	config := &LoopConfig{
+		Prompt:        "Test task",
+		MaxIterations: 2,
+		PromisePhrase: "done",
+		WorkingDir:    t.TempDir(),
+		Diagnostics: []string{
+			"echo 'parser.go:3: undefined: tokenize'; exit 1",
+			"exit 2",
+			"true",
+		},
+	}
+	engine := NewLoopEngine(config, mockSDK)
+
+	var events []*DiagnosticsEvent
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		for event := range engine.Events() {
+			if ev, ok := event.(*DiagnosticsEvent); ok {
+				events = append(events, ev)
+			}
+		}
+	}()
+
+	result, err := engine.Start(context.Background())
+	<-done
+
+	require.NoError(t, err)
+	assert.Equal(t, []int{2, 2}, result.FailureHistory)
+
+	require.Len(t, events, 2)
+	assert.Equal(t, 2, events[0].Failed)
+	assert.Equal(t, 1, events[0].Passed)
+
+	require.Len(t, mockSDK.Prompts, 2)
+	assert.NotContains(t, mockSDK.Prompts[0], "Current failures")
+	assert.Contains(t, mockSDK.Prompts[1], "## Current failures (2)")
+	assert.Contains(t, mockSDK.Prompts[1], "parser.go:3: undefined: tokenize")
+	assert.Contains(t, mockSDK.Prompts[1], "exit 2: exited with code 2")
+}
+
+func TestLoopEngine_DiagnosticsParseFullOutput(t *testing.T) {
+	mockSDK := NewMockSDKClient()
+
+	// The failure is followed by more output than is kept for prompts
+	config := &LoopConfig{
+		Prompt:        "Test task",
+		MaxIterations: 1,
+		PromisePhrase: "done",
+		WorkingDir:    t.TempDir(),
+		Diagnostics: []string{
+			"echo 'lexer.go:7: unexpected token'; head -c 20000 /dev/zero | tr '\\0' a; echo; exit 1",
+		},
+	}
+	engine := NewLoopEngine(config, mockSDK)
+
+	var event *DiagnosticsEvent
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		for ev := range engine.Events() {
+			if diagnostics, ok := ev.(*DiagnosticsEvent); ok {
+				event = diagnostics
+			}
+		}
+	}()
+
+	_, err := engine.Start(context.Background())
+	<-done
+
+	require.NoError(t, err)
+	require.NotNil(t, event)
+	require.Len(t, event.Failures, 1)
+	assert.Equal(t, "lexer.go", event.Failures[0].File)
+	assert.Equal(t, "unexpected token", event.Failures[0].Message)
+}
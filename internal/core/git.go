@@ -0,0 +1,247 @@
+// Package core provides local git integration for the loop engine.
+
+package core
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+)
+
+// GitBranchPrefix is the prefix of branches created for a run.
+const GitBranchPrefix = "ralph/"
+
+// commitSummaryLimit is the maximum length of the iteration summary in a commit message.
+const commitSummaryLimit = 2000
+
+// runGit runs git in dir and returns its trimmed standard output.
+func runGit(ctx context.Context, dir string, args ...string) (string, error) {
+	return runGitEnv(ctx, dir, nil, args...)
+}
+
+// runGitEnv runs git in dir with additional environment variables.
+func runGitEnv(ctx context.Context, dir string, env []string, args ...string) (string, error) {
+	cmd := exec.CommandContext(ctx, "git", append([]string{"-C", dir}, args...)...)
+	if len(env) > 0 {
+		cmd.Env = append(os.Environ(), env...)
+	}
+
+	var stdout, stderr bytes.Buffer
+	cmd.Stdout = &stdout
+	cmd.Stderr = &stderr
+
+	if err := cmd.Run(); err != nil {
+		if ctxErr := ctx.Err(); ctxErr != nil {
+			return "", ctxErr
+		}
+		return "", fmt.Errorf("git %s: %w: %s", args[0], err, strings.TrimSpace(stderr.String()))
+	}
+
+	return strings.TrimSpace(stdout.String()), nil
+}
+
+// gitDir returns the directory git commands run in.
+func (e *LoopEngine) gitDir() string {
+	if e.config.WorkingDir == "" {
+		return "."
+	}
+	return e.config.WorkingDir
+}
+
+// setupGit records the starting commit and switches to the run branch when configured.
+// A resumed run keeps its original starting commit and returns to its branch.
+func (e *LoopEngine) setupGit() error {
+	if !e.config.GitCommit {
+		return nil
+	}
+
+	dir := e.gitDir()
+
+	if e.startCommit == "" {
+		head, err := runGit(e.ctx, dir, "rev-parse", "HEAD")
+		if err != nil {
+			return fmt.Errorf("git commits require a repository with at least one commit: %w", err)
+		}
+		e.startCommit = head
+	}
+
+	if !e.config.GitBranch {
+		return nil
+	}
+
+	branch := GitBranchPrefix + e.runID
+	current, err := runGit(e.ctx, dir, "rev-parse", "--abbrev-ref", "HEAD")
+	if err != nil {
+		return err
+	}
+
+	if current != branch {
+		args := []string{"switch", "-c", branch}
+		if _, err := runGit(e.ctx, dir, "rev-parse", "--verify", "--quiet", "refs/heads/"+branch); err == nil {
+			args = []string{"switch", branch}
+		}
+
+		if _, err := runGit(e.ctx, dir, args...); err != nil {
+			return err
+		}
+	}
+
+	e.mu.Lock()
+	e.branch = branch
+	e.mu.Unlock()
+
+	return nil
+}
+
+// commitIteration stages and commits all changes made during the iteration.
+// Commit failures are reported as recoverable errors so they never stop the loop.
+func (e *LoopEngine) commitIteration(iteration int, finalMessage string) {
+	if !e.config.GitCommit {
+		return
+	}
+
+	message := fmt.Sprintf("ralph: iteration %d/%d", iteration, e.config.MaxIterations)
+	if summary := extractSummary(finalMessage, e.promisePhrase(), commitSummaryLimit); summary != "" {
+		message += "\n\n" + summary
+	}
+	message += "\n\nRalph-Run: " + e.runID
+
+	commit, err := e.gitCommit(message)
+	if err != nil {
+		e.emit(NewErrorEvent(fmt.Errorf("failed to commit iteration %d: %w", iteration, err), iteration, true))
+		return
+	}
+
+	if commit != "" {
+		e.mu.Lock()
+		e.commits++
+		e.mu.Unlock()
+	}
+
+	e.emit(NewGitCommitEvent(commit, message, iteration))
+}
+
+// gitCommit commits all changes in the working directory and returns the commit hash,
+// or an empty hash when there was nothing to commit.
+func (e *LoopEngine) gitCommit(message string) (string, error) {
+	dir := e.gitDir()
+
+	if _, err := runGit(e.ctx, dir, "add", "-A"); err != nil {
+		return "", err
+	}
+
+	// diff --quiet exits with 1 when there are staged changes
+	_, err := runGit(e.ctx, dir, "diff", "--cached", "--quiet")
+	if err == nil {
+		return "", nil
+	}
+
+	var exitErr *exec.ExitError
+	if !errors.As(err, &exitErr) || exitErr.ExitCode() != 1 {
+		return "", err
+	}
+
+	if _, err := runGit(e.ctx, dir, "commit", "--quiet", "-m", message); err != nil {
+		return "", err
+	}
+
+	return runGit(e.ctx, dir, "rev-parse", "HEAD")
+}
+
+// withTempIndex runs fn with a private git index so the user's staging area is left untouched.
+// The index is seeded with the real one so unchanged files are not hashed again.
+func withTempIndex(ctx context.Context, dir string, fn func(env []string) error) error {
+	tmp, err := os.MkdirTemp("", "ralph-index-")
+	if err != nil {
+		return fmt.Errorf("failed to create temporary index: %w", err)
+	}
+	defer os.RemoveAll(tmp)
+
+	index := filepath.Join(tmp, "index")
+	if real, err := runGit(ctx, dir, "rev-parse", "--path-format=absolute", "--git-path", "index"); err == nil {
+		if data, err := os.ReadFile(real); err == nil {
+			_ = os.WriteFile(index, data, 0o644)
+		}
+	}
+
+	return fn([]string{"GIT_INDEX_FILE=" + index})
+}
+
+// snapshotTree records the working tree, including untracked but not ignored files,
+// as a git tree object and returns its hash.
+func snapshotTree(ctx context.Context, dir string) (string, error) {
+	var tree string
+	err := withTempIndex(ctx, dir, func(env []string) error {
+		if _, err := runGitEnv(ctx, dir, env, "add", "--all", ":/"); err != nil {
+			return err
+		}
+
+		var err error
+		tree, err = runGitEnv(ctx, dir, env, "write-tree")
+		return err
+	})
+
+	return tree, err
+}
+
+// restoreTree resets the working tree to a snapshot taken by snapshotTree.
+// Files created after the snapshot are removed, ignored files are left alone.
+func restoreTree(ctx context.Context, dir, tree string) error {
+	current, err := snapshotTree(ctx, dir)
+	if err != nil || current == tree {
+		return err
+	}
+
+	root, err := runGit(ctx, dir, "rev-parse", "--show-toplevel")
+	if err != nil {
+		return err
+	}
+
+	added, err := diffPaths(ctx, root, "A", tree, current)
+	if err != nil {
+		return err
+	}
+
+	for _, path := range added {
+		if err := os.Remove(filepath.Join(root, filepath.FromSlash(path))); err != nil && !errors.Is(err, os.ErrNotExist) {
+			return fmt.Errorf("failed to remove %s: %w", path, err)
+		}
+	}
+
+	// Only rewrite files that differ, so untouched files keep their timestamps
+	changed, err := diffPaths(ctx, root, "DMT", tree, current)
+	if err != nil || len(changed) == 0 {
+		return err
+	}
+
+	return withTempIndex(ctx, root, func(env []string) error {
+		if _, err := runGitEnv(ctx, root, env, "read-tree", tree); err != nil {
+			return err
+		}
+
+		_, err := runGitEnv(ctx, root, env, append([]string{"checkout-index", "--force", "--"}, changed...)...)
+		return err
+	})
+}
+
+// diffPaths lists the paths that differ between two trees, filtered by git diff status letters.
+func diffPaths(ctx context.Context, root, filter, from, to string) ([]string, error) {
+	output, err := runGit(ctx, root, "diff", "--name-only", "-z", "--no-renames", "--diff-filter="+filter, from, to)
+	if err != nil {
+		return nil, err
+	}
+
+	var paths []string
+	for _, path := range strings.Split(output, "\x00") {
+		if path != "" {
+			paths = append(paths, path)
+		}
+	}
+
+	return paths, nil
+}
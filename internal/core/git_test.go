@@ -0,0 +1,173 @@
+package core
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// initGitRepo creates a repository with a single commit in a temporary directory.
+func initGitRepo(t *testing.T) string {
+	t.Helper()
+
+	dir := t.TempDir()
+	for _, args := range [][]string{
+		{"init", "--quiet", "--initial-branch=main"},
+		{"config", "user.name", "Ralph Test"},
+		{"config", "user.email", "ralph@example.com"},
+		{"commit", "--quiet", "--allow-empty", "-m", "initial"},
+	} {
+		_, err := runGit(context.Background(), dir, args...)
+		require.NoError(t, err)
+	}
+
+	return dir
+}
+
+func TestLoopEngine_GitCommitPerIteration(t *testing.T) {
+	tests := []struct {
+		name       string
+		branch     bool
+		wantBranch bool
+	}{
+		{
+			name: "current branch",
+		},
+		{
+			name:       "run branch",
+			branch:     true,
+			wantBranch: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			dir := initGitRepo(t)
+			start, err := runGit(context.Background(), dir, "rev-parse", "HEAD")
+			require.NoError(t, err)
+
+			mockSDK := NewMockSDKClient()
+			mockSDK.ResponseText = "<summary>Edited the file</summary>"
+			// Only the first two iterations change files
+			mockSDK.OnPrompt = func(prompt string) {
+				if strings.Contains(prompt, "[Iteration 3/3]") {
+					return
+				}
+				path := filepath.Join(dir, "file.txt")
+				require.NoError(t, os.WriteFile(path, []byte(prompt), 0o644))
+			}
+
+			config := &LoopConfig{
+				Prompt:        "Test task",
+				MaxIterations: 3,
+				PromisePhrase: "done",
+				WorkingDir:    dir,
+				GitCommit:     true,
+				GitBranch:     tt.branch,
+			}
+			engine := NewLoopEngine(config, mockSDK)
+
+			var commits []*GitCommitEvent
+			done := make(chan struct{})
+			go func() {
+				defer close(done)
+				for event := range engine.Events() {
+					if ev, ok := event.(*GitCommitEvent); ok {
+						commits = append(commits, ev)
+					}
+				}
+			}()
+
+			result, err := engine.Start(context.Background())
+			<-done
+			require.NoError(t, err)
+
+			assert.Equal(t, start, result.StartCommit)
+			assert.Equal(t, 2, result.Commits)
+
+			require.Len(t, commits, 3)
+			assert.NotEmpty(t, commits[0].Commit)
+			assert.NotEmpty(t, commits[1].Commit)
+			assert.Empty(t, commits[2].Commit)
+			assert.Contains(t, commits[0].Message, "ralph: iteration 1/3")
+			assert.Contains(t, commits[0].Message, "Edited the file")
+
+			log, err := runGit(context.Background(), dir, "log", "--format=%s", start+"..HEAD")
+			require.NoError(t, err)
+			assert.Equal(t, "ralph: iteration 2/3\nralph: iteration 1/3", log)
+
+			branch, err := runGit(context.Background(), dir, "rev-parse", "--abbrev-ref", "HEAD")
+			require.NoError(t, err)
+
+			if tt.wantBranch {
+				assert.Equal(t, GitBranchPrefix+engine.RunID(), branch)
+				assert.Equal(t, branch, result.Branch)
+				return
+			}
+
+			assert.Equal(t, "main", branch)
+			assert.Empty(t, result.Branch)
+		})
+	}
+}
+
+func TestLoopEngine_GitSetupFailsOutsideRepository(t *testing.T) {
+	config := &LoopConfig{
+		Prompt:        "Test task",
+		MaxIterations: 1,
+		PromisePhrase: "done",
+		WorkingDir:    t.TempDir(),
+		GitCommit:     true,
+	}
+	engine := NewLoopEngine(config, NewMockSDKClient())
+	drainEvents(engine)
+
+	result, err := engine.Start(context.Background())
+
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "failed to set up git")
+	assert.Equal(t, StateFailed, result.State)
+	assert.Equal(t, 0, result.Iterations)
+}
+
+func TestLoopEngine_GitResumeReturnsToRunBranch(t *testing.T) {
+	dir := initGitRepo(t)
+	runID := "20260101-120000-abcdef"
+	branch := GitBranchPrefix + runID
+
+	_, err := runGit(context.Background(), dir, "branch", branch)
+	require.NoError(t, err)
+
+	checkpoint := &Checkpoint{
+		Config: &LoopConfig{
+			Prompt:        "Test task",
+			MaxIterations: 2,
+			PromisePhrase: "done",
+			WorkingDir:    dir,
+			GitCommit:     true,
+			GitBranch:     true,
+		},
+		RunID:       runID,
+		StartCommit: "abc123",
+		Branch:      branch,
+		Iteration:   1,
+		Commits:     1,
+	}
+	engine := ResumeLoopEngine(checkpoint, NewMockSDKClient())
+	drainEvents(engine)
+
+	result, err := engine.Start(context.Background())
+	require.NoError(t, err)
+
+	current, err := runGit(context.Background(), dir, "rev-parse", "--abbrev-ref", "HEAD")
+	require.NoError(t, err)
+	assert.Equal(t, branch, current)
+	assert.Equal(t, "abc123", result.StartCommit)
+	// Nothing changed, so no new commits were added
+	assert.Equal(t, 1, result.Commits)
+}
@@ -25,3 +25,29 @@ type SDKClient interface {
 	// Model returns the configured AI model name.
 	Model() string
 }
+
+// ModelSwitcher is implemented by SDK clients that can change the model
+// used for sessions created afterwards.
+type ModelSwitcher interface {
+	// SetModel changes the model for new sessions.
+	SetModel(model string) error
+}
+
+// FollowUpSender is implemented by SDK clients that can add a message to the
+// session while a prompt is being processed.
+type FollowUpSender interface {
+	// SendFollowUp queues a message after the prompt being processed.
+	SendFollowUp(message string) error
+}
+
+// ToolRegistry is implemented by SDK clients that can expose Ralph's own tools to the model.
+type ToolRegistry interface {
+	// RegisterTools exposes tools to the model in sessions created afterwards.
+	RegisterTools(tools ...sdk.Tool)
+}
+
+// PermissionGate is implemented by SDK clients that can ask before running tools.
+type PermissionGate interface {
+	// SetPermissionHandler sets the handler deciding whether tools of sessions created afterwards may run.
+	SetPermissionHandler(handler sdk.PermissionHandler)
+}
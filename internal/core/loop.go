@@ -7,7 +7,7 @@
 // The LoopEngine follows a state machine pattern with the following states:
 //   - StateIdle: Initial state, ready to start
 //   - StateRunning: Loop is executing iterations
-//   - StateComplete: Successfully completed (promise detected)
+//   - StateComplete: Completed (promise accepted or max iterations reached)
 //   - StateFailed: Failed due to error, timeout, or max iterations
 //   - StateCancelled: Cancelled by user
 //
@@ -16,7 +16,6 @@ package core
 
 import (
 	"context"
-	"strings"
 	"sync"
 	"time"
 )
@@ -29,6 +28,8 @@ const (
 	StateIdle LoopState = "idle"
 	// StateRunning indicates the loop is executing iterations.
 	StateRunning LoopState = "running"
+	// StatePaused indicates the loop is waiting to be resumed between iterations.
+	StatePaused LoopState = "paused"
 	// StateComplete indicates the loop completed successfully.
 	StateComplete LoopState = "complete"
 	// StateFailed indicates the loop failed.
@@ -42,25 +43,82 @@ func (s LoopState) String() string {
 	return string(s)
 }
 
+// CompletionPolicy determines how a detected promise affects the loop.
+type CompletionPolicy string
+
+const (
+	// CompletionStop stops the loop as soon as the promise is detected.
+	CompletionStop CompletionPolicy = "stop"
+	// CompletionConsecutive stops the loop once the promise was detected
+	// in PromiseStreak consecutive iterations.
+	CompletionConsecutive CompletionPolicy = "consecutive"
+	// CompletionIgnore records the promise but keeps iterating until
+	// the maximum iterations are reached. The promise is never accepted.
+	CompletionIgnore CompletionPolicy = "ignore"
+)
+
+// String returns the string representation of the policy.
+func (p CompletionPolicy) String() string {
+	return string(p)
+}
+
 // LoopConfig contains configuration for loop execution.
 type LoopConfig struct {
-	Prompt        string
-	PromisePhrase string
-	Model         string
-	WorkingDir    string
-	MaxIterations int
-	Timeout       time.Duration
-	DryRun        bool
+	Prompt             string
+	PromisePhrase      string
+	Model              string
+	WorkingDir         string
+	VerifyCommand      string
+	RollbackCommand    string
+	SystemPrompt       string
+	SystemPromptMode   string
+	CheckpointDir      string
+	Checklist          string
+	ToolPolicy         string
+	MCPConfig          string
+	CompletionPolicy   CompletionPolicy
+	SessionStrategy    SessionStrategy
+	StagnationPolicy   StagnationPolicy
+	StagnationModel    string
+	ReviewerModel      string
+	RepetitionAction   RepetitionAction
+	Approve            ApprovalMode
+	Diagnostics        []string
+	FallbackModels     []string
+	Phases             []Phase
+	Vars               map[string]string
+	Prices             map[string]ModelPrice
+	MaxIterations      int
+	PromiseStreak      int
+	SummaryLimit       int
+	SessionRotateEvery int
+	StagnationLimit    int
+	RepetitionLimit    int
+	FallbackAfter      int
+	ResponseSimilarity float64
+	MaxTokens          int64
+	MaxCost            float64
+	Timeout            time.Duration
+	IterationTimeout   time.Duration
+	DryRun             bool
+	RequestSummary     bool
+	GitCommit          bool
+	GitBranch          bool
+	RequireApproval    bool
 }
 
 // DefaultLoopConfig returns a LoopConfig with default values.
 func DefaultLoopConfig() *LoopConfig {
 	return &LoopConfig{
-		MaxIterations: 10,
-		Timeout:       30 * time.Minute,
-		PromisePhrase: "I'm special!",
-		Model:         "gpt-4",
-		WorkingDir:    ".",
+		MaxIterations:    10,
+		Timeout:          30 * time.Minute,
+		PromisePhrase:    "I'm special!",
+		Model:            "gpt-4",
+		WorkingDir:       ".",
+		CompletionPolicy: CompletionStop,
+		PromiseStreak:    2,
+		SummaryLimit:     2000,
+		SessionStrategy:  SessionPersistent,
 	}
 }
 
@@ -68,33 +126,81 @@ func DefaultLoopConfig() *LoopConfig {
 // It coordinates with the Copilot SDK, detects completion promises,
 // and handles state transitions.
 type LoopEngine struct {
-	startTime    time.Time
-	sdk          SDKClient
-	ctx          context.Context
-	config       *LoopConfig
-	events       chan any
-	cancel       context.CancelFunc
-	state        LoopState
-	iteration    int
-	mu           sync.RWMutex
-	eventsClosed bool
+	startTime        time.Time
+	phaseStart       time.Time
+	sdk              SDKClient
+	reviewer         SDKClient
+	approver         ToolApprover
+	ctx              context.Context
+	config           *LoopConfig
+	checkpoint       *Checkpoint
+	bus              *EventBus
+	events           *Subscription
+	cancel           context.CancelCauseFunc
+	deadline         *time.Timer
+	resumed          chan struct{}
+	state            LoopState
+	runID            string
+	startCommit      string
+	branch           string
+	fingerprint      string
+	reviewBase       string
+	lastOutcome      string
+	previousSummary  string
+	diagnostics      *DiagnosticsReport
+	rollback         *rollbackBaseline
+	repetition       *repetitionDetector
+	carryOver        []string
+	alwaysAllowed    map[string]bool
+	failureHistory   []int
+	iterationUsage   []Usage
+	iterationModels  []string
+	phases           []PhaseResult
+	checklist        []ChecklistItem
+	usage            Usage
+	elapsedBefore    time.Duration
+	pausedFor        time.Duration
+	iteration        int
+	phase            int
+	sessions         int
+	commits          int
+	reverts          int
+	stagnant         int
+	fallback         int
+	modelFailures    int
+	approvals        int
+	promiseStreak    int
+	promiseIteration int
+	firstPromise     int
+	paused           bool
+	prompting        bool
+	signaled         bool
+	mu               sync.RWMutex
 }
 
-// eventChannelBufferSize is the buffer size for the events channel.
-const eventChannelBufferSize = 100
-
 // NewLoopEngine creates a new loop engine with the given configuration.
 // If sdk is nil, the engine will run in dry-run mode.
 func NewLoopEngine(config *LoopConfig, sdk SDKClient) *LoopEngine {
+	return newLoopEngine(config, sdk, newRunID())
+}
+
+// newLoopEngine creates a loop engine for the run identified by runID.
+func newLoopEngine(config *LoopConfig, sdk SDKClient, runID string) *LoopEngine {
 	if config == nil {
 		config = DefaultLoopConfig()
 	}
 
+	bus := NewEventBus(runID)
+
 	return &LoopEngine{
-		config: config,
-		sdk:    sdk,
-		state:  StateIdle,
-		events: make(chan any, eventChannelBufferSize),
+		config:     config,
+		sdk:        sdk,
+		state:      StateIdle,
+		runID:      runID,
+		repetition: newRepetitionDetector(config),
+		phases:     newPhaseResults(config.Phases),
+		bus:        bus,
+		events:     bus.Subscribe(DefaultEventBuffer, DeliveryBlock),
 	}
 }
 
@@ -105,12 +211,6 @@ func (e *LoopEngine) State() LoopState {
 	return e.state
 }
 
-// BuildSystemPrompt constructs the system prompt from the embedded template.
-// It replaces {{.Task}} with the actual user task and {{.Promise}} with the completion phrase.
-func BuildSystemPrompt(promisePhrase string) string {
-	return strings.ReplaceAll(systemPromptTemplate, "{{.Promise}}", promisePhrase)
-}
-
 // Iteration returns the current iteration number (1-based).
 // Returns 0 if the loop hasn't started yet.
 func (e *LoopEngine) Iteration() int {
@@ -119,21 +219,77 @@ func (e *LoopEngine) Iteration() int {
 	return e.iteration
 }
 
+// RunID returns the unique identifier of the run, used to resume it.
+func (e *LoopEngine) RunID() string {
+	return e.runID
+}
+
 // Config returns the loop configuration.
 func (e *LoopEngine) Config() *LoopConfig {
 	return e.config
 }
 
 // Events returns a read-only channel for receiving loop events.
-// Subscribers should read from this channel to receive updates.
-func (e *LoopEngine) Events() <-chan any {
-	return e.events
+// The channel is subscribed when the engine is created and never drops events,
+// so it must be read until it is closed or the loop stalls.
+func (e *LoopEngine) Events() <-chan Event {
+	return e.events.Events()
+}
+
+// Subscribe adds an independent subscriber for loop events with its own buffer and delivery policy.
+// Subscribe before Start to receive every event of the run.
+func (e *LoopEngine) Subscribe(buffer int, policy DeliveryPolicy) *Subscription {
+	return e.bus.Subscribe(buffer, policy)
+}
+
+// DroppedEvents returns the number of events dropped for subscribers using DeliveryDrop.
+func (e *LoopEngine) DroppedEvents() uint64 {
+	return e.bus.Dropped()
 }
 
 // LoopResult contains the outcome of loop execution.
 type LoopResult struct {
 	Error      error
 	State      LoopState
+	RunID      string
 	Iterations int
-	Duration   time.Duration
+	// PromiseIteration is the iteration in which the completion promise
+	// was accepted, or 0 when the promise was never accepted.
+	PromiseIteration int
+	// FirstPromiseIteration is the iteration in which the completion promise was
+	// first detected, or 0 when it never was. It is set while PromiseIteration stays 0
+	// when the promise was ignored or never accepted. With phases it covers the last phase.
+	FirstPromiseIteration int
+	Duration              time.Duration
+	// FailureHistory holds the diagnostic failure count after each iteration.
+	FailureHistory []int
+	// Sessions is the number of SDK sessions created during the loop.
+	Sessions int
+	// StartCommit is the HEAD commit before the first iteration when git commits are enabled.
+	StartCommit string
+	// Branch is the branch iterations were committed to when a run branch was created.
+	Branch string
+	// Commits is the number of iteration commits.
+	Commits int
+	// Reverts is the number of iterations reverted because they made things worse.
+	Reverts int
+	// Usage is the cumulative token usage and cost of the run.
+	Usage Usage
+	// IterationUsage holds the token usage and cost of each iteration.
+	IterationUsage []Usage
+	// Approvals is the number of iterations the reviewer approved.
+	Approvals int
+	// IterationModels holds the model used for each iteration.
+	IterationModels []string
+	// Phases holds the outcome of each phase of a multi-phase run.
+	Phases []PhaseResult
+	// ChecklistDone is the number of checked items in a checklist run.
+	ChecklistDone int
+	// ChecklistTotal is the number of items in a checklist run.
+	ChecklistTotal int
+}
+
+// PromiseReached reports whether the completion promise was accepted.
+func (r *LoopResult) PromiseReached() bool {
+	return r.PromiseIteration > 0
 }
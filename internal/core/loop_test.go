@@ -26,10 +26,44 @@ type MockSDKClient struct {
 	model               string
 	PromisePhrase       string
 	ToolCalls           []sdk.ToolCall
+	Prompts             []string
+	SessionsCreated     int
 	mu                  sync.Mutex
 	hasSession          bool
 	started             bool
 	SimulatePromise     bool
+	// NonStreaming sends the response as a complete message instead of text deltas.
+	NonStreaming bool
+	// ToolError is returned as the result error of every tool call.
+	ToolError error
+	// OnPrompt is called with every prompt before the response is sent.
+	OnPrompt func(prompt string)
+	// Usage is reported after every response when set.
+	Usage *sdk.UsageEvent
+	// Denied is reported after every response when set, as if the tool policy refused a tool.
+	Denied *sdk.ToolDeniedEvent
+	// ResponseDelay delays every response, unless the prompt context is done first.
+	ResponseDelay time.Duration
+	// SessionError is sent as an error event after every response when set.
+	SessionError error
+	// FollowUps holds the messages sent while a prompt was processed.
+	FollowUps []string
+	// OnFollowUp is called with every follow-up after it was recorded.
+	OnFollowUp func(message string)
+	// PermissionHandler is the handler set by the engine to approve tools.
+	PermissionHandler sdk.PermissionHandler
+	// Tools holds the tools registered by the engine.
+	Tools []sdk.Tool
+	// AbortDelay keeps the event stream of a cancelled prompt open this long,
+	// as a session finishing an aborted turn.
+	AbortDelay time.Duration
+	// HoldTurn keeps the event stream open after the response until the prompt is cancelled.
+	HoldTurn bool
+	// AbortIncomplete reports cancelled prompts as aborted without the session finishing its turn.
+	AbortIncomplete bool
+	// Overlaps counts prompts sent while the event stream of an earlier prompt was still open.
+	Overlaps    int
+	openPrompts int
 }
 
 // NewMockSDKClient creates a new mock SDK client.
@@ -72,6 +106,7 @@ func (m *MockSDKClient) CreateSession(ctx context.Context) error {
 		return m.CreateSessionError
 	}
 	m.hasSession = true
+	m.SessionsCreated++
 	return nil
 }
 
@@ -87,6 +122,39 @@ func (m *MockSDKClient) DestroySession(ctx context.Context) error {
 	return nil
 }
 
+// SetPermissionHandler implements PermissionGate.
+func (m *MockSDKClient) SetPermissionHandler(handler sdk.PermissionHandler) {
+	m.PermissionHandler = handler
+}
+
+// RegisterTools implements ToolRegistry.
+func (m *MockSDKClient) RegisterTools(tools ...sdk.Tool) {
+	m.Tools = append(m.Tools, tools...)
+}
+
+// Tool returns the registered tool with the given name.
+func (m *MockSDKClient) Tool(name string) sdk.Tool {
+	for _, tool := range m.Tools {
+		if tool.Name == name {
+			return tool
+		}
+	}
+
+	return sdk.Tool{}
+}
+
+// SendFollowUp implements FollowUpSender.
+func (m *MockSDKClient) SendFollowUp(message string) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	m.FollowUps = append(m.FollowUps, message)
+	if m.OnFollowUp != nil {
+		m.OnFollowUp(message)
+	}
+	return nil
+}
+
 // SendPrompt implements SDKClient.
 func (m *MockSDKClient) SendPrompt(ctx context.Context, prompt string) (<-chan sdk.Event, error) {
 	m.mu.Lock()
@@ -96,19 +164,53 @@ func (m *MockSDKClient) SendPrompt(ctx context.Context, prompt string) (<-chan s
 		return nil, m.SendPromptError
 	}
 
+	m.Prompts = append(m.Prompts, prompt)
+	if m.OnPrompt != nil {
+		m.OnPrompt(prompt)
+	}
+
 	events := make(chan sdk.Event, 10)
 
+	if m.openPrompts > 0 {
+		m.Overlaps++
+	}
+	m.openPrompts++
+
+	delay, hold, abortDelay, abortIncomplete := m.ResponseDelay, m.HoldTurn, m.AbortDelay, m.AbortIncomplete
+	aborted := func() {
+		time.Sleep(abortDelay)
+		if abortIncomplete {
+			events <- sdk.NewErrorEvent(sdk.ErrAbortIncomplete)
+			return
+		}
+		events <- sdk.NewErrorEvent(ctx.Err())
+	}
+
 	go func() {
 		defer close(events)
+		defer func() {
+			m.mu.Lock()
+			m.openPrompts--
+			m.mu.Unlock()
+		}()
 
 		// Check for cancellation
 		select {
 		case <-ctx.Done():
-			events <- sdk.NewErrorEvent(ctx.Err())
+			aborted()
 			return
 		default:
 		}
 
+		if delay > 0 {
+			select {
+			case <-ctx.Done():
+				aborted()
+				return
+			case <-time.After(delay):
+			}
+		}
+
 		// Send text response
 		responseText := m.ResponseText
 		if responseText == "" {
@@ -120,13 +222,34 @@ func (m *MockSDKClient) SendPrompt(ctx context.Context, prompt string) (<-chan s
 			responseText = fmt.Sprintf("%s <promise>%s</promise>", responseText, m.PromisePhrase)
 		}
 
-		events <- sdk.NewTextEvent(responseText, false)
+		if m.NonStreaming {
+			events <- sdk.NewResponseCompleteEvent(sdk.Message{Content: responseText, Timestamp: time.Now()})
+		} else {
+			events <- sdk.NewTextEvent(responseText, false)
+		}
 
 		// Send any tool calls
 		for _, tc := range m.ToolCalls {
 			events <- sdk.NewToolCallEvent(tc)
 			// Also send a tool result event to simulate completed tool execution
-			events <- sdk.NewToolResultEvent(tc, "Mock tool result", nil)
+			events <- sdk.NewToolResultEvent(tc, "Mock tool result", m.ToolError)
+		}
+
+		if m.Usage != nil {
+			events <- m.Usage
+		}
+
+		if m.Denied != nil {
+			events <- m.Denied
+		}
+
+		if m.SessionError != nil {
+			events <- sdk.NewErrorEvent(m.SessionError)
+		}
+
+		if hold {
+			<-ctx.Done()
+			aborted()
 		}
 	}()
 
@@ -135,9 +258,19 @@ func (m *MockSDKClient) SendPrompt(ctx context.Context, prompt string) (<-chan s
 
 // Model implements SDKClient.
 func (m *MockSDKClient) Model() string {
+	m.mu.Lock()
+	defer m.mu.Unlock()
 	return m.model
 }
 
+// SetModel implements ModelSwitcher.
+func (m *MockSDKClient) SetModel(model string) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.model = model
+	return nil
+}
+
 // SlowMockSDKClient wraps MockSDKClient to add delays for testing cancellation.
 type SlowMockSDKClient struct {
 	*MockSDKClient
@@ -430,6 +563,7 @@ func TestLoopEngine_MaxIterations(t *testing.T) {
 	assert.Equal(t, StateComplete, engine.State())
 	assert.Equal(t, StateComplete, result.State)
 	assert.Equal(t, 3, result.Iterations)
+	assert.False(t, result.PromiseReached())
 }
 
 // TestLoopEngine_Timeout tests timeout handling.
@@ -540,6 +674,8 @@ func TestDefaultLoopConfig(t *testing.T) {
 	assert.Equal(t, "I'm special!", config.PromisePhrase)
 	assert.Equal(t, "gpt-4", config.Model)
 	assert.Equal(t, ".", config.WorkingDir)
+	assert.Equal(t, CompletionStop, config.CompletionPolicy)
+	assert.Equal(t, 2, config.PromiseStreak)
 }
 
 // TestLoopEventTypes tests event type creation.
@@ -615,6 +751,15 @@ func TestLoopEventTypes(t *testing.T) {
 		assert.Equal(t, 5, event.Iteration)
 	})
 
+	t.Run("VerificationFailedEvent", func(t *testing.T) {
+		event := NewVerificationFailedEvent("go test ./...", "FAIL", 1, 3)
+
+		assert.Equal(t, "go test ./...", event.Command)
+		assert.Equal(t, "FAIL", event.Output)
+		assert.Equal(t, 1, event.ExitCode)
+		assert.Equal(t, 3, event.Iteration)
+	})
+
 	t.Run("ErrorEvent", func(t *testing.T) {
 		err := errors.New("test error")
 		event := NewErrorEvent(err, 2, true)
@@ -631,3 +776,88 @@ func TestLoopEngine_Iteration(t *testing.T) {
 
 	assert.Equal(t, 0, engine.Iteration())
 }
+
+// TestLoopEngine_CompletionPolicy tests how the completion policy reacts to promises.
+func TestLoopEngine_CompletionPolicy(t *testing.T) {
+	tests := []struct {
+		name                 string
+		policy               CompletionPolicy
+		streak               int
+		wantIterations       int
+		wantPromiseIteration int
+		wantFirstPromise     int
+	}{
+		{
+			name:                 "stop on first promise",
+			policy:               CompletionStop,
+			wantIterations:       1,
+			wantPromiseIteration: 1,
+			wantFirstPromise:     1,
+		},
+		{
+			name:                 "empty policy behaves like stop",
+			policy:               "",
+			wantIterations:       1,
+			wantPromiseIteration: 1,
+			wantFirstPromise:     1,
+		},
+		{
+			name:                 "consecutive promises",
+			policy:               CompletionConsecutive,
+			streak:               3,
+			wantIterations:       3,
+			wantPromiseIteration: 3,
+			wantFirstPromise:     1,
+		},
+		{
+			name:             "consecutive streak never reached",
+			policy:           CompletionConsecutive,
+			streak:           6,
+			wantIterations:   5,
+			wantFirstPromise: 1,
+		},
+		{
+			name:             "ignore keeps iterating without accepting",
+			policy:           CompletionIgnore,
+			wantIterations:   5,
+			wantFirstPromise: 1,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockSDK := NewMockSDKClient()
+			mockSDK.SimulatePromise = true
+			mockSDK.PromisePhrase = "done"
+
+			config := &LoopConfig{
+				Prompt:           "Test task",
+				MaxIterations:    5,
+				PromisePhrase:    "done",
+				CompletionPolicy: tt.policy,
+				PromiseStreak:    tt.streak,
+			}
+			engine := NewLoopEngine(config, mockSDK)
+
+			result, err := engine.Start(context.Background())
+
+			require.NoError(t, err)
+			assert.Equal(t, StateComplete, result.State)
+			assert.Equal(t, tt.wantIterations, result.Iterations)
+			assert.Equal(t, tt.wantPromiseIteration, result.PromiseIteration)
+			assert.Equal(t, tt.wantFirstPromise, result.FirstPromiseIteration)
+			assert.Equal(t, tt.wantPromiseIteration > 0, result.PromiseReached())
+		})
+	}
+}
+
+// TestAcceptPromise tests that the consecutive streak resets on a missing promise.
+func TestAcceptPromise(t *testing.T) {
+	engine := NewLoopEngine(&LoopConfig{CompletionPolicy: CompletionConsecutive, PromiseStreak: 2}, nil)
+
+	assert.False(t, engine.acceptPromise(1, true))
+	assert.False(t, engine.acceptPromise(2, false))
+	assert.False(t, engine.acceptPromise(3, true))
+	assert.True(t, engine.acceptPromise(4, true))
+	assert.Equal(t, 4, engine.promiseIteration)
+}
@@ -0,0 +1,290 @@
+// Package core provides parallel best-of-N runs in separate git worktrees.
+
+package core
+
+import (
+	"cmp"
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"slices"
+	"strconv"
+	"strings"
+)
+
+// worktreesDirName is the directory below the state directory holding the worktrees of parallel runs.
+const worktreesDirName = "worktrees"
+
+// Candidate is one of the runs of a parallel best-of-N run.
+type Candidate struct {
+	// Config is the loop configuration of the candidate, pointing at its worktree.
+	Config *LoopConfig
+	// Result is the outcome of the candidate's loop, nil when it did not run.
+	Result *LoopResult
+	// Name identifies the candidate in output, e.g. "run 2".
+	Name string
+	// Worktree is the path of the candidate's git worktree.
+	Worktree string
+	// Branch is the branch the candidate's iterations are committed to.
+	Branch string
+	// Verification is the output of the verification command.
+	Verification string
+	// DiffLines is the number of lines added and removed since the start commit.
+	DiffLines int
+	// Verified is true when the verification command passed in the worktree,
+	// or when the promise was reached if there is no verification command.
+	Verified bool
+}
+
+// ParallelRun runs the same loop in several git worktrees created from HEAD
+// and keeps the branch of the best candidate.
+type ParallelRun struct {
+	// ID identifies the parallel run, it prefixes the candidate branches.
+	ID string
+	// Repository is the root of the git repository.
+	Repository string
+	// WorkingDir is the loop working directory the winner is checked out in.
+	WorkingDir string
+	// StartCommit is the commit all worktrees were created from.
+	StartCommit string
+	// Candidates are ordered best first after Rank.
+	Candidates []*Candidate
+}
+
+// NewParallelRun creates a worktree and branch from HEAD for each of n candidates.
+// Candidate configurations commit every iteration to their own branch and
+// have paths inside the repository rebased onto their worktree.
+func NewParallelRun(ctx context.Context, cfg *LoopConfig, n int) (*ParallelRun, error) {
+	workingDir, err := filepath.Abs(cmp.Or(cfg.WorkingDir, "."))
+	if err != nil {
+		return nil, fmt.Errorf("invalid working directory: %w", err)
+	}
+
+	// git reports the repository root with symlinks resolved
+	workingDir, err = filepath.EvalSymlinks(workingDir)
+	if err != nil {
+		return nil, fmt.Errorf("invalid working directory: %w", err)
+	}
+
+	repository, err := runGit(ctx, workingDir, "rev-parse", "--show-toplevel")
+	if err != nil {
+		return nil, fmt.Errorf("parallel runs require a git repository: %w", err)
+	}
+
+	head, err := runGit(ctx, workingDir, "rev-parse", "HEAD")
+	if err != nil {
+		return nil, fmt.Errorf("parallel runs require a repository with at least one commit: %w", err)
+	}
+
+	run := &ParallelRun{
+		ID:          newRunID(),
+		Repository:  repository,
+		WorkingDir:  workingDir,
+		StartCommit: head,
+	}
+
+	stateDir := filepath.Join(repository, StateDirName)
+	if err := ensureStateDir(stateDir); err != nil {
+		return nil, err
+	}
+
+	for i := 1; i <= n; i++ {
+		name := run.ID + "-" + strconv.Itoa(i)
+		candidate := &Candidate{
+			Name:     fmt.Sprintf("run %d", i),
+			Worktree: filepath.Join(run.WorktreesDir(), name),
+			Branch:   GitBranchPrefix + name,
+		}
+
+		if _, err := runGit(ctx, repository, "worktree", "add", "-b", candidate.Branch, candidate.Worktree, head); err != nil {
+			_ = run.removeWorktrees(context.WithoutCancel(ctx))
+			return nil, fmt.Errorf("failed to create worktree for %s: %w", candidate.Name, err)
+		}
+
+		candidate.Config = run.candidateConfig(cfg, candidate.Worktree)
+		run.Candidates = append(run.Candidates, candidate)
+
+		// Empty or untracked working directories are not part of the worktree
+		if err := os.MkdirAll(candidate.Config.WorkingDir, 0o755); err != nil {
+			_ = run.removeWorktrees(context.WithoutCancel(ctx))
+			return nil, fmt.Errorf("failed to create working directory for %s: %w", candidate.Name, err)
+		}
+	}
+
+	return run, nil
+}
+
+// candidateConfig copies the loop configuration for a candidate running in worktree.
+func (p *ParallelRun) candidateConfig(cfg *LoopConfig, worktree string) *LoopConfig {
+	candidate := *cfg
+	candidate.WorkingDir = p.rebase(p.WorkingDir, worktree)
+	candidate.GitCommit = true
+	candidate.GitBranch = false
+	// Worktrees are removed when the run finishes, so candidates cannot be resumed
+	candidate.CheckpointDir = ""
+
+	if cfg.Checklist != "" {
+		candidate.Checklist = p.rebase(cfg.Checklist, worktree)
+	}
+
+	return &candidate
+}
+
+// rebase maps a path inside the repository onto the same path inside worktree.
+// Paths outside the repository are returned unchanged.
+func (p *ParallelRun) rebase(path, worktree string) string {
+	rel, err := filepath.Rel(p.Repository, path)
+	if err != nil || rel == ".." || strings.HasPrefix(rel, ".."+string(filepath.Separator)) {
+		return path
+	}
+
+	return filepath.Join(worktree, rel)
+}
+
+// Rank runs the verification command in every worktree, measures the diff of each
+// candidate and orders the candidates best first: verified candidates before others,
+// then candidates that reached their promise, then, when preferSmallDiff is set,
+// smaller diffs, and finally fewer iterations.
+func (p *ParallelRun) Rank(ctx context.Context, verifyCommand string, preferSmallDiff bool) error {
+	for _, candidate := range p.Candidates {
+		if err := p.evaluate(ctx, candidate, verifyCommand); err != nil {
+			return fmt.Errorf("failed to evaluate %s: %w", candidate.Name, err)
+		}
+	}
+
+	slices.SortStableFunc(p.Candidates, func(a, b *Candidate) int {
+		if a.Verified != b.Verified {
+			return compareBool(a.Verified, b.Verified)
+		}
+
+		if a.promiseReached() != b.promiseReached() {
+			return compareBool(a.promiseReached(), b.promiseReached())
+		}
+
+		if preferSmallDiff && a.DiffLines != b.DiffLines {
+			return cmp.Compare(a.DiffLines, b.DiffLines)
+		}
+
+		return cmp.Compare(a.iterations(), b.iterations())
+	})
+
+	return nil
+}
+
+// evaluate verifies a candidate and measures its diff.
+func (p *ParallelRun) evaluate(ctx context.Context, candidate *Candidate, verifyCommand string) error {
+	candidate.Verified = candidate.promiseReached()
+
+	if verifyCommand != "" {
+		result, err := runShellCommand(ctx, candidate.Config.WorkingDir, verifyCommand)
+		if err != nil {
+			return err
+		}
+
+		candidate.Verified = result.Passed()
+		candidate.Verification = result.Tail()
+	}
+
+	numstat, err := runGit(ctx, candidate.Worktree, "diff", "--numstat", p.StartCommit, candidate.Branch)
+	if err != nil {
+		return err
+	}
+
+	candidate.DiffLines = countDiffLines(numstat)
+
+	return nil
+}
+
+// WorktreesDir returns the directory holding the candidate worktrees.
+func (p *ParallelRun) WorktreesDir() string {
+	return filepath.Join(p.Repository, StateDirName, worktreesDirName)
+}
+
+// Winner returns the best candidate, or nil when there are none.
+func (p *ParallelRun) Winner() *Candidate {
+	if len(p.Candidates) == 0 {
+		return nil
+	}
+
+	return p.Candidates[0]
+}
+
+// Finish removes all worktrees and checks out the winner's branch in the working directory.
+// The checkout is left alone when no candidate is verified, as the winner is then only a guess.
+// The candidate branches are kept so all runs can still be inspected.
+func (p *ParallelRun) Finish(ctx context.Context) error {
+	// A branch cannot be checked out while a worktree still uses it
+	if err := p.removeWorktrees(ctx); err != nil {
+		return err
+	}
+
+	winner := p.Winner()
+	if winner == nil || !winner.Verified {
+		return nil
+	}
+
+	if _, err := runGit(ctx, p.WorkingDir, "switch", winner.Branch); err != nil {
+		return fmt.Errorf("failed to check out %s: %w", winner.Branch, err)
+	}
+
+	return nil
+}
+
+// removeWorktrees removes the worktrees of all candidates.
+func (p *ParallelRun) removeWorktrees(ctx context.Context) error {
+	var errs []error
+	for _, candidate := range p.Candidates {
+		if _, err := runGit(ctx, p.Repository, "worktree", "remove", "--force", candidate.Worktree); err != nil {
+			errs = append(errs, fmt.Errorf("failed to remove worktree of %s: %w", candidate.Name, err))
+		}
+	}
+
+	return errors.Join(errs...)
+}
+
+// promiseReached reports whether the candidate's loop accepted its promise.
+func (c *Candidate) promiseReached() bool {
+	return c.Result != nil && c.Result.PromiseReached()
+}
+
+// iterations returns the number of iterations the candidate ran.
+func (c *Candidate) iterations() int {
+	if c.Result == nil {
+		return 0
+	}
+
+	return c.Result.Iterations
+}
+
+// compareBool orders true before false.
+func compareBool(a, b bool) int {
+	if a {
+		return -1
+	}
+
+	if b {
+		return 1
+	}
+
+	return 0
+}
+
+// countDiffLines sums the added and removed lines of git diff --numstat output.
+// Binary files, reported as "-", are not counted.
+func countDiffLines(numstat string) int {
+	total := 0
+	for line := range strings.Lines(numstat) {
+		fields := strings.Fields(line)
+		if len(fields) < 2 {
+			continue
+		}
+
+		added, _ := strconv.Atoi(fields[0])
+		removed, _ := strconv.Atoi(fields[1])
+		total += added + removed
+	}
+
+	return total
+}
@@ -0,0 +1,123 @@
+package core
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCountDiffLines(t *testing.T) {
+	assert.Equal(t, 0, countDiffLines(""))
+	assert.Equal(t, 7, countDiffLines("3\t1\tmain.go\n-\t-\tlogo.png\n2\t1\tREADME.md\n"))
+}
+
+func TestParallelRun(t *testing.T) {
+	dir := initGitRepo(t)
+	sub := filepath.Join(dir, "app")
+	require.NoError(t, os.MkdirAll(sub, 0o755))
+
+	ctx := context.Background()
+	config := &LoopConfig{Prompt: "Create done.txt", MaxIterations: 2, PromisePhrase: "done", WorkingDir: sub}
+
+	run, err := NewParallelRun(ctx, config, 3)
+	require.NoError(t, err)
+	require.Len(t, run.Candidates, 3)
+
+	for i, candidate := range run.Candidates {
+		assert.DirExists(t, candidate.Worktree)
+		assert.True(t, strings.HasPrefix(candidate.Branch, GitBranchPrefix+run.ID))
+		assert.Equal(t, filepath.Join(candidate.Worktree, "app"), candidate.Config.WorkingDir)
+		assert.True(t, candidate.Config.GitCommit)
+		assert.Empty(t, candidate.Config.CheckpointDir)
+
+		// Run 1 never finishes, run 2 writes a large file and run 3 a small one
+		mockSDK := NewMockSDKClient()
+		workingDir := candidate.Config.WorkingDir
+		switch i {
+		case 1:
+			mockSDK.ResponseText = "<promise>done</promise>"
+			mockSDK.OnPrompt = func(string) {
+				require.NoError(t, os.WriteFile(filepath.Join(workingDir, "done.txt"), []byte("a\nb\nc\n"), 0o644))
+			}
+		case 2:
+			mockSDK.ResponseText = "<promise>done</promise>"
+			mockSDK.OnPrompt = func(string) {
+				require.NoError(t, os.WriteFile(filepath.Join(workingDir, "done.txt"), []byte("a\n"), 0o644))
+			}
+		}
+
+		engine := NewLoopEngine(candidate.Config, mockSDK)
+		drainEvents(engine)
+		candidate.Result, err = engine.Start(ctx)
+		require.NoError(t, err)
+	}
+
+	require.NoError(t, run.Rank(ctx, "test -f done.txt", true))
+
+	assert.Equal(t, "run 3", run.Winner().Name)
+	assert.True(t, run.Candidates[0].Verified)
+	assert.Equal(t, 1, run.Candidates[0].DiffLines)
+	assert.Equal(t, "run 2", run.Candidates[1].Name)
+	assert.Equal(t, 3, run.Candidates[1].DiffLines)
+	assert.Equal(t, "run 1", run.Candidates[2].Name)
+	assert.False(t, run.Candidates[2].Verified)
+
+	require.NoError(t, run.Finish(ctx))
+
+	for _, candidate := range run.Candidates {
+		assert.NoDirExists(t, candidate.Worktree)
+	}
+
+	branch, err := runGit(ctx, dir, "branch", "--show-current")
+	require.NoError(t, err)
+	assert.Equal(t, run.Winner().Branch, branch)
+	assert.FileExists(t, filepath.Join(sub, "done.txt"))
+
+	// Losing branches are kept for inspection
+	_, err = runGit(ctx, dir, "rev-parse", "--verify", run.Candidates[1].Branch)
+	assert.NoError(t, err)
+}
+
+func TestParallelRunUnverifiedWinner(t *testing.T) {
+	dir := initGitRepo(t)
+	ctx := context.Background()
+	config := &LoopConfig{Prompt: "Create done.txt", MaxIterations: 1, PromisePhrase: "done", WorkingDir: dir}
+
+	before, err := runGit(ctx, dir, "branch", "--show-current")
+	require.NoError(t, err)
+
+	run, err := NewParallelRun(ctx, config, 2)
+	require.NoError(t, err)
+
+	for _, candidate := range run.Candidates {
+		engine := NewLoopEngine(candidate.Config, NewMockSDKClient())
+		drainEvents(engine)
+		candidate.Result, err = engine.Start(ctx)
+		require.NoError(t, err)
+	}
+
+	require.NoError(t, run.Rank(ctx, "test -f done.txt", false))
+	assert.False(t, run.Winner().Verified)
+
+	require.NoError(t, run.Finish(ctx))
+
+	branch, err := runGit(ctx, dir, "branch", "--show-current")
+	require.NoError(t, err)
+	assert.Equal(t, before, branch)
+
+	for _, candidate := range run.Candidates {
+		assert.NoDirExists(t, candidate.Worktree)
+		_, err = runGit(ctx, dir, "rev-parse", "--verify", candidate.Branch)
+		assert.NoError(t, err)
+	}
+}
+
+func TestNewParallelRunRequiresGit(t *testing.T) {
+	_, err := NewParallelRun(context.Background(), &LoopConfig{WorkingDir: t.TempDir()}, 2)
+	assert.ErrorContains(t, err, "parallel runs require a git repository")
+}
@@ -0,0 +1,135 @@
+// Package core provides pausing and resuming a running loop between iterations.
+
+package core
+
+import (
+	"errors"
+	"time"
+)
+
+// ErrLoopFinished is returned when pausing a loop that already finished.
+var ErrLoopFinished = errors.New("loop already finished")
+
+// Pause asks the loop to wait once the current iteration finishes, until Resume is called.
+// Time spent paused does not count against the loop timeout.
+func (e *LoopEngine) Pause() error {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	return e.pause()
+}
+
+// Resume lets a paused loop continue with the next iteration.
+func (e *LoopEngine) Resume() {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	e.resume()
+}
+
+// TogglePause pauses a running loop or resumes a paused one.
+// It reports whether the loop is paused afterwards.
+func (e *LoopEngine) TogglePause() (bool, error) {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	if e.paused {
+		e.resume()
+		return false, nil
+	}
+
+	if err := e.pause(); err != nil {
+		return false, err
+	}
+
+	return true, nil
+}
+
+// Paused reports whether the loop is paused or pauses after the current iteration.
+func (e *LoopEngine) Paused() bool {
+	e.mu.RLock()
+	defer e.mu.RUnlock()
+	return e.paused
+}
+
+// pause requests a pause. Must be called with lock held.
+func (e *LoopEngine) pause() error {
+	switch e.state {
+	case StateComplete, StateFailed, StateCancelled:
+		return ErrLoopFinished
+	}
+
+	if e.paused {
+		return nil
+	}
+
+	e.paused = true
+	e.resumed = make(chan struct{})
+
+	return nil
+}
+
+// resume releases a pause. Must be called with lock held.
+func (e *LoopEngine) resume() {
+	if !e.paused {
+		return
+	}
+
+	e.paused = false
+	close(e.resumed)
+}
+
+// waitWhilePaused blocks between iterations while the loop is paused.
+// The timeout deadline is held while waiting.
+func (e *LoopEngine) waitWhilePaused() error {
+	e.mu.Lock()
+	if !e.paused {
+		e.mu.Unlock()
+		return nil
+	}
+	resumed := e.resumed
+	iteration := e.iteration
+	e.state = StatePaused
+	e.mu.Unlock()
+
+	pausedAt := time.Now()
+	// The timeout does not run out while paused
+	e.stopDeadline()
+	e.emit(NewLoopPausedEvent(iteration))
+
+	var err error
+	select {
+	case <-resumed:
+	case <-e.ctx.Done():
+		err = e.ctx.Err()
+	}
+
+	paused := time.Since(pausedAt)
+	e.mu.Lock()
+	e.pausedFor += paused
+	e.state = StateRunning
+	e.mu.Unlock()
+
+	if err != nil {
+		return err
+	}
+
+	e.releaseDeadline()
+	e.emit(NewLoopResumedEvent(paused, iteration))
+
+	return nil
+}
+
+// releaseDeadline restarts the loop timeout with the remaining time budget.
+func (e *LoopEngine) releaseDeadline() {
+	if e.deadline != nil {
+		e.deadline.Reset(e.config.Timeout - e.elapsed())
+	}
+}
+
+// stopDeadline stops the loop timeout.
+func (e *LoopEngine) stopDeadline() {
+	if e.deadline != nil {
+		e.deadline.Stop()
+	}
+}
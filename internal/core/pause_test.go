@@ -0,0 +1,94 @@
+package core
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestLoopEngine_PauseHoldsTimeout(t *testing.T) {
+	config := &LoopConfig{
+		Prompt:        "Test task",
+		MaxIterations: 2,
+		Timeout:       300 * time.Millisecond,
+		PromisePhrase: "done",
+	}
+	mockSDK := NewMockSDKClient()
+	engine := NewLoopEngine(config, mockSDK)
+	mockSDK.OnPrompt = func(string) {
+		require.NoError(t, engine.Pause())
+	}
+
+	var kinds []EventKind
+	var pausedState LoopState
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		for event := range engine.Events() {
+			kinds = append(kinds, event.Kind())
+			if _, ok := event.(*LoopPausedEvent); ok {
+				pausedState = engine.State()
+				// Both pauses together outlast the timeout
+				time.Sleep(200 * time.Millisecond)
+				engine.Resume()
+			}
+		}
+	}()
+
+	result, err := engine.Start(context.Background())
+	<-done
+	require.NoError(t, err)
+
+	assert.Equal(t, StateComplete, result.State)
+	assert.Equal(t, 2, result.Iterations)
+	assert.Less(t, result.Duration, 300*time.Millisecond)
+	assert.Equal(t, StatePaused, pausedState)
+	assert.Contains(t, kinds, EventLoopPaused)
+	assert.Contains(t, kinds, EventLoopResumed)
+}
+
+func TestLoopEngine_CancelWhilePaused(t *testing.T) {
+	config := &LoopConfig{Prompt: "Test task", MaxIterations: 3, PromisePhrase: "done"}
+	engine := NewLoopEngine(config, NewMockSDKClient())
+	require.NoError(t, engine.Pause())
+
+	ctx, cancel := context.WithCancel(context.Background())
+	go func() {
+		for event := range engine.Events() {
+			if _, ok := event.(*LoopPausedEvent); ok {
+				cancel()
+			}
+		}
+	}()
+
+	result, err := engine.Start(ctx)
+	require.ErrorIs(t, err, ErrLoopCancelled)
+	assert.Equal(t, 0, result.Iterations)
+
+	assert.ErrorIs(t, engine.Pause(), ErrLoopFinished)
+}
+
+func TestLoopEngine_TogglePause(t *testing.T) {
+	engine := NewLoopEngine(nil, nil)
+
+	tests := []struct {
+		name   string
+		paused bool
+	}{
+		{name: "pauses a running loop", paused: true},
+		{name: "resumes a paused loop", paused: false},
+		{name: "pauses again", paused: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			paused, err := engine.TogglePause()
+			require.NoError(t, err)
+			assert.Equal(t, tt.paused, paused)
+			assert.Equal(t, tt.paused, engine.Paused())
+		})
+	}
+}
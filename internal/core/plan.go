@@ -0,0 +1,353 @@
+// Package core provides multi-phase runs defined in a plan file.
+
+package core
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"gopkg.in/yaml.v3"
+)
+
+// Phase is one step of a multi-phase run with its own task and completion promise.
+type Phase struct {
+	// Name identifies the phase, e.g. "plan" or "implement".
+	Name string `yaml:"name"`
+	// Prompt is the task of the phase, rendered as a template like the loop prompt.
+	Prompt string `yaml:"prompt"`
+	// Promise is the completion promise of the phase, the loop promise when empty.
+	Promise string `yaml:"promise"`
+	// Model is the model used during the phase, the loop model when empty.
+	Model string `yaml:"model"`
+	// MaxIterations is the iteration budget of the phase.
+	MaxIterations int `yaml:"max_iterations"`
+}
+
+// Plan describes a run as ordered phases.
+type Plan struct {
+	// Prompt is shared context included in the prompt of every phase.
+	Prompt string `yaml:"prompt"`
+	// Phases are run in order, each one starts when the previous promise is accepted.
+	Phases []Phase `yaml:"phases"`
+}
+
+// PhaseResult contains the outcome of a single phase.
+type PhaseResult struct {
+	// Name is the name of the phase.
+	Name string `json:"name"`
+	// Iterations is the number of iterations run in the phase.
+	Iterations int `json:"iterations"`
+	// PromiseIteration is the iteration in which the phase promise was accepted,
+	// or 0 when the phase did not complete.
+	PromiseIteration int `json:"promise_iteration,omitempty"`
+	// Duration is the time spent in the phase.
+	Duration time.Duration `json:"duration"`
+}
+
+// Complete reports whether the phase promise was accepted.
+func (r *PhaseResult) Complete() bool {
+	return r.PromiseIteration > 0
+}
+
+// LoadPlan reads a plan from a YAML file, or from the YAML frontmatter of a Markdown file.
+// The body of a Markdown plan is used as the shared prompt.
+func LoadPlan(path string) (*Plan, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read plan file %s: %w", path, err)
+	}
+
+	ext := strings.ToLower(filepath.Ext(path))
+	if ext == ".md" || ext == ".markdown" {
+		return ParseMarkdownPlan(data)
+	}
+
+	return ParsePlan(data)
+}
+
+// ParsePlan parses a YAML plan.
+func ParsePlan(data []byte) (*Plan, error) {
+	decoder := yaml.NewDecoder(bytes.NewReader(data))
+	decoder.KnownFields(true)
+
+	var plan Plan
+	if err := decoder.Decode(&plan); err != nil && !errors.Is(err, io.EOF) {
+		return nil, fmt.Errorf("invalid plan: %w", err)
+	}
+
+	if err := plan.validate(); err != nil {
+		return nil, err
+	}
+
+	return &plan, nil
+}
+
+// ParseMarkdownPlan parses a Markdown plan whose phases are defined in the YAML frontmatter.
+// The Markdown body is used as the shared prompt unless the frontmatter defines one.
+func ParseMarkdownPlan(data []byte) (*Plan, error) {
+	text := strings.ReplaceAll(string(data), "\r\n", "\n")
+
+	rest, ok := strings.CutPrefix(text, "---\n")
+	if !ok {
+		return nil, errors.New("invalid plan: Markdown plans must start with YAML frontmatter")
+	}
+
+	frontmatter, body, ok := strings.Cut(rest, "\n---")
+	if !ok {
+		return nil, errors.New("invalid plan: unterminated YAML frontmatter")
+	}
+
+	plan, err := ParsePlan([]byte(frontmatter))
+	if err != nil {
+		return nil, err
+	}
+
+	if plan.Prompt == "" {
+		plan.Prompt = strings.TrimSpace(body)
+	}
+
+	return plan, nil
+}
+
+// validate checks that the plan has uniquely named phases with a prompt.
+func (p *Plan) validate() error {
+	if len(p.Phases) == 0 {
+		return errors.New("invalid plan: no phases defined")
+	}
+
+	names := make(map[string]bool, len(p.Phases))
+	for i, phase := range p.Phases {
+		if phase.Name == "" {
+			return fmt.Errorf("invalid plan: phase %d has no name", i+1)
+		}
+
+		if names[phase.Name] {
+			return fmt.Errorf("invalid plan: duplicate phase %q", phase.Name)
+		}
+		names[phase.Name] = true
+
+		if strings.TrimSpace(phase.Prompt) == "" {
+			return fmt.Errorf("invalid plan: phase %q has no prompt", phase.Name)
+		}
+
+		if phase.MaxIterations < 0 {
+			return fmt.Errorf("invalid plan: phase %q max_iterations cannot be negative (got: %d)", phase.Name, phase.MaxIterations)
+		}
+	}
+
+	return nil
+}
+
+// Apply configures the loop to run the plan. Phases without an iteration budget get
+// the loop's MaxIterations, and the loop budget becomes the sum of all phase budgets.
+func (p *Plan) Apply(cfg *LoopConfig) {
+	if p.Prompt != "" {
+		cfg.Prompt = p.Prompt
+	}
+
+	cfg.Phases = make([]Phase, len(p.Phases))
+	total := 0
+	for i, phase := range p.Phases {
+		if phase.MaxIterations == 0 {
+			phase.MaxIterations = cfg.MaxIterations
+		}
+
+		cfg.Phases[i] = phase
+		total += phase.MaxIterations
+	}
+
+	cfg.MaxIterations = total
+}
+
+// phaseInstructions tells the model which phase it is working on and how to finish it.
+const phaseInstructions = "Only work on this phase. When it is complete, end your response with " +
+	"<promise>%s</promise> instead of any other completion phrase."
+
+// currentPhase returns the active phase, or nil when the run has no phases.
+func (e *LoopEngine) currentPhase() *Phase {
+	if e.phase >= len(e.config.Phases) {
+		return nil
+	}
+
+	return &e.config.Phases[e.phase]
+}
+
+// promisePhrase returns the completion promise of the active phase.
+func (e *LoopEngine) promisePhrase() string {
+	if phase := e.currentPhase(); phase != nil && phase.Promise != "" {
+		return phase.Promise
+	}
+
+	return e.config.PromisePhrase
+}
+
+// phaseModel returns the model of the active phase, falling back to the loop model.
+func (e *LoopEngine) phaseModel() string {
+	if phase := e.currentPhase(); phase != nil && phase.Model != "" {
+		return phase.Model
+	}
+
+	return e.config.Model
+}
+
+// sessionModel returns the model new sessions use: the active fallback model once the loop
+// fell back, otherwise the model of the active phase. A fallback outlasts phase changes,
+// the model it replaced would likely keep failing in the next phase.
+func (e *LoopEngine) sessionModel() string {
+	e.mu.RLock()
+	fallback := e.fallback
+	e.mu.RUnlock()
+
+	if fallback > 0 && fallback <= len(e.config.FallbackModels) {
+		return e.config.FallbackModels[fallback-1]
+	}
+
+	return e.phaseModel()
+}
+
+// newPhaseResults creates empty results for the configured phases.
+func newPhaseResults(phases []Phase) []PhaseResult {
+	if len(phases) == 0 {
+		return nil
+	}
+
+	results := make([]PhaseResult, len(phases))
+	for i, phase := range phases {
+		results[i].Name = phase.Name
+	}
+
+	return results
+}
+
+// phaseResults returns the phase results including the running time of the active phase.
+// Must be called with lock held.
+func (e *LoopEngine) phaseResults() []PhaseResult {
+	if len(e.phases) == 0 {
+		return nil
+	}
+
+	results := make([]PhaseResult, len(e.phases))
+	copy(results, e.phases)
+
+	if !e.phaseStart.IsZero() && e.phase < len(results) {
+		results[e.phase].Duration += time.Since(e.phaseStart)
+	}
+
+	return results
+}
+
+// countPhaseIteration records an iteration of the active phase.
+// Must be called with lock held.
+func (e *LoopEngine) countPhaseIteration() {
+	if e.phase < len(e.phases) {
+		e.phases[e.phase].Iterations++
+	}
+}
+
+// phaseExhausted reports whether the active phase used its iteration budget.
+func (e *LoopEngine) phaseExhausted() bool {
+	phase := e.currentPhase()
+	if phase == nil || phase.MaxIterations <= 0 {
+		return false
+	}
+
+	e.mu.RLock()
+	defer e.mu.RUnlock()
+
+	return e.phases[e.phase].Iterations >= phase.MaxIterations
+}
+
+// usePhaseModel selects the model of the active phase before the first session is created.
+func (e *LoopEngine) usePhaseModel() error {
+	model := e.phaseModel()
+	if e.currentPhase() == nil || model == e.sdk.Model() {
+		return nil
+	}
+
+	switcher, ok := e.sdk.(ModelSwitcher)
+	if !ok {
+		return fmt.Errorf("SDK client cannot switch to model %s", model)
+	}
+
+	return switcher.SetModel(model)
+}
+
+// beginPhase starts timing the active phase and announces it when no iteration ran in it yet.
+func (e *LoopEngine) beginPhase() {
+	phase := e.currentPhase()
+	if phase == nil {
+		return
+	}
+
+	e.mu.Lock()
+	e.phaseStart = time.Now()
+	fresh := e.phases[e.phase].Iterations == 0
+	index := e.phase
+	e.mu.Unlock()
+
+	if fresh {
+		e.emit(NewPhaseStartEvent(phase.Name, e.sessionModel(), index+1, len(e.config.Phases), e.Iteration()+1))
+	}
+}
+
+// advancePhase completes the active phase after its promise was accepted and starts
+// the next one in a new session. It returns true when the whole run is complete.
+func (e *LoopEngine) advancePhase(iteration int) (bool, error) {
+	phase := e.currentPhase()
+	if phase == nil {
+		return true, nil
+	}
+
+	e.mu.Lock()
+	result := &e.phases[e.phase]
+	result.PromiseIteration = iteration
+	result.Duration += time.Since(e.phaseStart)
+	e.phaseStart = time.Time{}
+	completed := *result
+	index := e.phase
+	last := e.phase == len(e.config.Phases)-1
+	if !last {
+		e.phase++
+		e.promiseIteration = 0
+		e.promiseStreak = 0
+		e.firstPromise = 0
+	}
+	e.mu.Unlock()
+
+	e.emit(NewPhaseCompleteEvent(completed, index+1, len(e.config.Phases)))
+
+	if last {
+		return true, nil
+	}
+
+	if err := e.replaceSession(e.sessionModel()); err != nil {
+		return false, err
+	}
+
+	e.beginPhase()
+
+	return false, nil
+}
+
+// phaseTask adds the phase prompt and instructions to the shared task.
+func phaseTask(task, phasePrompt string, phase *Phase, index, total int, promise string) string {
+	var builder strings.Builder
+
+	if strings.TrimSpace(task) != "" {
+		builder.WriteString(task)
+		builder.WriteString("\n\n")
+	}
+
+	builder.WriteString(fmt.Sprintf("## Phase %d/%d: %s\n\n", index+1, total, phase.Name))
+	builder.WriteString(phasePrompt)
+	builder.WriteString("\n\n")
+	builder.WriteString(fmt.Sprintf(phaseInstructions, promise))
+
+	return builder.String()
+}
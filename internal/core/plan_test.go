@@ -0,0 +1,227 @@
+package core
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParsePlan(t *testing.T) {
+	tests := []struct {
+		name     string
+		data     string
+		errorMsg string
+		phases   int
+	}{
+		{
+			name: "valid plan",
+			data: `prompt: Build a parser
+phases:
+  - name: plan
+    prompt: Write PLAN.md
+    promise: PLANNED
+    max_iterations: 2
+  - name: implement
+    prompt: Implement PLAN.md
+    model: gpt-5
+`,
+			phases: 2,
+		},
+		{
+			name:     "no phases",
+			data:     "prompt: Build a parser\n",
+			errorMsg: "no phases defined",
+		},
+		{
+			name:     "unknown field",
+			data:     "phases:\n  - name: plan\n    prompt: Plan\n    max_iteration: 2\n",
+			errorMsg: "field max_iteration not found",
+		},
+		{
+			name:     "missing prompt",
+			data:     "phases:\n  - name: plan\n",
+			errorMsg: `phase "plan" has no prompt`,
+		},
+		{
+			name:     "duplicate phase",
+			data:     "phases:\n  - name: plan\n    prompt: a\n  - name: plan\n    prompt: b\n",
+			errorMsg: `duplicate phase "plan"`,
+		},
+		{
+			name:     "negative budget",
+			data:     "phases:\n  - name: plan\n    prompt: a\n    max_iterations: -1\n",
+			errorMsg: "max_iterations cannot be negative",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			plan, err := ParsePlan([]byte(tt.data))
+			if tt.errorMsg != "" {
+				require.Error(t, err)
+				assert.Contains(t, err.Error(), tt.errorMsg)
+				return
+			}
+
+			require.NoError(t, err)
+			assert.Len(t, plan.Phases, tt.phases)
+		})
+	}
+}
+
+func TestLoadPlanMarkdown(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "plan.md")
+	content := "---\nphases:\n  - name: plan\n    prompt: Write PLAN.md\n---\n\n# Parser\n\nBuild a parser.\n"
+	require.NoError(t, os.WriteFile(path, []byte(content), 0o644))
+
+	plan, err := LoadPlan(path)
+	require.NoError(t, err)
+
+	assert.Equal(t, "# Parser\n\nBuild a parser.", plan.Prompt)
+	require.Len(t, plan.Phases, 1)
+	assert.Equal(t, "Write PLAN.md", plan.Phases[0].Prompt)
+
+	_, err = ParseMarkdownPlan([]byte("# No frontmatter"))
+	assert.ErrorContains(t, err, "must start with YAML frontmatter")
+}
+
+func TestPlanApply(t *testing.T) {
+	plan := &Plan{
+		Prompt: "Shared",
+		Phases: []Phase{
+			{Name: "plan", Prompt: "Plan", MaxIterations: 2},
+			{Name: "implement", Prompt: "Implement"},
+		},
+	}
+
+	config := &LoopConfig{Prompt: "Ignored", MaxIterations: 5}
+	plan.Apply(config)
+
+	assert.Equal(t, "Shared", config.Prompt)
+	assert.Equal(t, 7, config.MaxIterations)
+	assert.Equal(t, 2, config.Phases[0].MaxIterations)
+	assert.Equal(t, 5, config.Phases[1].MaxIterations)
+}
+
+func TestLoopEngine_Phases(t *testing.T) {
+	mockSDK := NewMockSDKClient()
+	mockSDK.OnPrompt = func(prompt string) {
+		mockSDK.ResponseText = "Working"
+		switch {
+		case strings.HasPrefix(prompt, "[Iteration 2/"):
+			mockSDK.ResponseText = "Planned <promise>PLANNED</promise>"
+		case strings.Contains(prompt, "## Phase 2/2: implement"):
+			mockSDK.ResponseText = "Built <promise>BUILT</promise>"
+		}
+	}
+
+	config := &LoopConfig{Prompt: "Build a parser", MaxIterations: 3, PromisePhrase: "done"}
+	plan := &Plan{Phases: []Phase{
+		{Name: "plan", Prompt: "Write PLAN.md", Promise: "PLANNED"},
+		{Name: "implement", Prompt: "Implement {{.Phase}}", Promise: "BUILT", Model: "big-model"},
+	}}
+	plan.Apply(config)
+
+	engine := NewLoopEngine(config, mockSDK)
+
+	var starts []*PhaseStartEvent
+	var completes []*PhaseCompleteEvent
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		for event := range engine.Events() {
+			switch ev := event.(type) {
+			case *PhaseStartEvent:
+				starts = append(starts, ev)
+			case *PhaseCompleteEvent:
+				completes = append(completes, ev)
+			}
+		}
+	}()
+
+	result, err := engine.Start(context.Background())
+	<-done
+	require.NoError(t, err)
+
+	assert.Equal(t, StateComplete, result.State)
+	assert.Equal(t, 3, result.Iterations)
+	assert.Equal(t, 3, result.PromiseIteration)
+
+	require.Len(t, result.Phases, 2)
+	assert.Equal(t, 2, result.Phases[0].Iterations)
+	assert.Equal(t, 2, result.Phases[0].PromiseIteration)
+	assert.Equal(t, 1, result.Phases[1].Iterations)
+	assert.True(t, result.Phases[1].Complete())
+
+	require.Len(t, starts, 2)
+	assert.Equal(t, "plan", starts[0].Name)
+	assert.Equal(t, 1, starts[0].Iteration)
+	assert.Equal(t, "big-model", starts[1].Model)
+	assert.Equal(t, 3, starts[1].Iteration)
+	require.Len(t, completes, 2)
+	assert.Equal(t, "implement", completes[1].Result.Name)
+
+	assert.Equal(t, "big-model", mockSDK.Model())
+	assert.Equal(t, 2, result.Sessions)
+
+	require.Len(t, mockSDK.Prompts, 3)
+	assert.Contains(t, mockSDK.Prompts[0], "Build a parser\n\n## Phase 1/2: plan\n\nWrite PLAN.md")
+	assert.Contains(t, mockSDK.Prompts[0], "<promise>PLANNED</promise>")
+	assert.Contains(t, mockSDK.Prompts[2], "Implement implement")
+}
+
+func TestLoopEngine_PhaseBudgetExhausted(t *testing.T) {
+	mockSDK := NewMockSDKClient()
+
+	config := &LoopConfig{Prompt: "Build a parser", MaxIterations: 2, PromisePhrase: "done"}
+	plan := &Plan{Phases: []Phase{
+		{Name: "plan", Prompt: "Write PLAN.md"},
+		{Name: "implement", Prompt: "Implement"},
+	}}
+	plan.Apply(config)
+
+	engine := NewLoopEngine(config, mockSDK)
+	drainEvents(engine)
+
+	result, err := engine.Start(context.Background())
+	require.NoError(t, err)
+
+	assert.Equal(t, StateComplete, result.State)
+	assert.False(t, result.PromiseReached())
+	assert.Equal(t, 2, result.Iterations)
+	assert.Equal(t, 2, result.Phases[0].Iterations)
+	assert.False(t, result.Phases[0].Complete())
+	assert.Zero(t, result.Phases[1].Iterations)
+}
+
+func TestLoopEngine_PhaseResetsFirstPromise(t *testing.T) {
+	mockSDK := NewMockSDKClient()
+	mockSDK.OnPrompt = func(prompt string) {
+		mockSDK.ResponseText = "Working"
+		if strings.Contains(prompt, "## Phase 1/2: plan") {
+			mockSDK.ResponseText = "Planned <promise>PLANNED</promise>"
+		}
+	}
+
+	config := &LoopConfig{Prompt: "Build a parser", MaxIterations: 3, PromisePhrase: "done"}
+	plan := &Plan{Phases: []Phase{
+		{Name: "plan", Prompt: "Write PLAN.md", Promise: "PLANNED"},
+		{Name: "implement", Prompt: "Implement", Promise: "BUILT"},
+	}}
+	plan.Apply(config)
+
+	engine := NewLoopEngine(config, mockSDK)
+	drainEvents(engine)
+
+	result, err := engine.Start(context.Background())
+	require.NoError(t, err)
+
+	assert.Equal(t, 1, result.Phases[0].PromiseIteration)
+	assert.False(t, result.PromiseReached())
+	assert.Zero(t, result.FirstPromiseIteration, "the promise of an earlier phase is not reported for the last one")
+}
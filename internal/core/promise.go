@@ -19,3 +19,46 @@ func detectPromise(text, promisePhrase string) bool {
 
 	return strings.Contains(text, promisePhrase)
 }
+
+// checkPromise marks the iteration outcome when text contains the promise phrase.
+// A single PromiseDetectedEvent is emitted per iteration.
+func (e *LoopEngine) checkPromise(text string, iteration int, outcome *iterationResult) {
+	// Checklist runs complete on checked items, not on the promise
+	promise := e.promisePhrase()
+	if e.config.Checklist != "" || outcome.promiseDetected || !detectPromise(text, promise) {
+		return
+	}
+
+	outcome.promiseDetected = true
+	e.emit(NewPromiseDetectedEvent(promise, "ai_response", iteration))
+}
+
+// acceptPromise applies the configured completion policy to the outcome of an iteration.
+// It tracks consecutive detections and records the iterations in which the promise
+// was first detected and accepted. It returns true when the loop should stop.
+func (e *LoopEngine) acceptPromise(iteration int, detected bool) bool {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	if !detected {
+		e.promiseStreak = 0
+		return false
+	}
+
+	e.promiseStreak++
+	if e.firstPromise == 0 {
+		e.firstPromise = iteration
+	}
+
+	switch e.config.CompletionPolicy {
+	case CompletionIgnore:
+		return false
+	case CompletionConsecutive:
+		if e.promiseStreak < max(e.config.PromiseStreak, 1) {
+			return false
+		}
+	}
+
+	e.promiseIteration = iteration
+	return true
+}
@@ -0,0 +1,190 @@
+// Package core provides detection of repetitive model behavior for the loop engine.
+
+package core
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+)
+
+// RepetitionAction determines what happens when repetitive behavior is detected.
+type RepetitionAction string
+
+const (
+	// RepetitionHint adds a corrective hint to the next iteration prompt.
+	RepetitionHint RepetitionAction = "hint"
+	// RepetitionInterrupt cuts the current iteration short and adds the hint.
+	RepetitionInterrupt RepetitionAction = "interrupt"
+)
+
+// String returns the string representation of the action.
+func (a RepetitionAction) String() string {
+	return string(a)
+}
+
+// RepetitionKind identifies what was repeated.
+type RepetitionKind string
+
+const (
+	// RepetitionTool is an identical failing tool invocation.
+	RepetitionTool RepetitionKind = "tool"
+	// RepetitionResponse is a response nearly identical to an earlier one.
+	RepetitionResponse RepetitionKind = "response"
+)
+
+// maxRememberedResponses is the number of earlier responses new ones are compared with.
+const maxRememberedResponses = 20
+
+// minSimilarityWords is the minimum response length in words for similarity checks,
+// so short acknowledgements are not reported as repetition.
+const minSimilarityWords = 20
+
+// shingleSize is the number of consecutive words compared by the similarity check.
+const shingleSize = 3
+
+// repetitionDetector tracks identical failing tool invocations and near-identical
+// responses across and within iterations.
+type repetitionDetector struct {
+	toolFailures map[string]int
+	reported     map[string]int
+	responses    [][]string
+	current      strings.Builder
+	similarity   float64
+	toolLimit    int
+}
+
+// newRepetitionDetector creates a detector for the configured thresholds.
+func newRepetitionDetector(config *LoopConfig) *repetitionDetector {
+	return &repetitionDetector{
+		toolFailures: make(map[string]int),
+		reported:     make(map[string]int),
+		similarity:   config.ResponseSimilarity,
+		toolLimit:    config.RepetitionLimit,
+	}
+}
+
+// observe feeds a loop event to the detector. AI responses are collected until
+// endMessage is called, failing tool executions are checked immediately.
+func (d *repetitionDetector) observe(event any) *RepetitionDetectedEvent {
+	switch ev := event.(type) {
+	case *AIResponseEvent:
+		d.current.WriteString(ev.Text)
+	case *ToolExecutionEvent:
+		return d.observeTool(ev)
+	}
+
+	return nil
+}
+
+// observeTool counts identical failing tool invocations.
+func (d *repetitionDetector) observeTool(ev *ToolExecutionEvent) *RepetitionDetectedEvent {
+	if d.toolLimit <= 0 || ev.Error == nil {
+		return nil
+	}
+
+	signature := toolSignature(ev)
+	d.toolFailures[signature]++
+
+	count := d.toolFailures[signature]
+	if count < d.toolLimit || !d.report(signature, ev.Iteration) {
+		return nil
+	}
+
+	return NewRepetitionDetectedEvent(RepetitionTool, ev.ToolName+": "+ev.Error.Error(), count, 0, ev.Iteration)
+}
+
+// endMessage compares the response collected since the last call with earlier responses.
+func (d *repetitionDetector) endMessage(iteration int) *RepetitionDetectedEvent {
+	words := strings.Fields(strings.ToLower(d.current.String()))
+	d.current.Reset()
+
+	if d.similarity <= 0 || len(words) < minSimilarityWords {
+		return nil
+	}
+
+	var best float64
+	for _, previous := range d.responses {
+		best = max(best, similarity(previous, words))
+	}
+
+	d.responses = append(d.responses, words)
+	if len(d.responses) > maxRememberedResponses {
+		d.responses = d.responses[1:]
+	}
+
+	if best < d.similarity || !d.report(string(RepetitionResponse), iteration) {
+		return nil
+	}
+
+	return NewRepetitionDetectedEvent(RepetitionResponse, strings.Join(words[:minSimilarityWords], " ")+" …", 0, best, iteration)
+}
+
+// report returns true the first time a repetition is seen in an iteration.
+func (d *repetitionDetector) report(key string, iteration int) bool {
+	if d.reported[key] == iteration {
+		return false
+	}
+
+	d.reported[key] = iteration
+	return true
+}
+
+// toolSignature identifies a tool invocation by name, parameters, and error.
+func toolSignature(ev *ToolExecutionEvent) string {
+	// Map keys are sorted by json.Marshal, so equal parameters encode equally
+	params, _ := json.Marshal(ev.Parameters)
+
+	return ev.ToolName + "\x00" + string(params) + "\x00" + ev.Error.Error()
+}
+
+// similarity returns the Jaccard similarity of the word shingles of two texts.
+func similarity(a, b []string) float64 {
+	shinglesA := shingles(a)
+	shinglesB := shingles(b)
+
+	var shared int
+	for shingle := range shinglesA {
+		if shinglesB[shingle] {
+			shared++
+		}
+	}
+
+	union := len(shinglesA) + len(shinglesB) - shared
+	if union == 0 {
+		return 0
+	}
+
+	return float64(shared) / float64(union)
+}
+
+// shingles returns the set of consecutive word sequences in words.
+func shingles(words []string) map[string]bool {
+	set := make(map[string]bool)
+	for i := 0; i+shingleSize <= len(words); i++ {
+		set[strings.Join(words[i:i+shingleSize], " ")] = true
+	}
+
+	return set
+}
+
+// handleRepetition reports a detected repetition and queues a corrective hint.
+// It returns true when the current iteration should be cut short.
+func (e *LoopEngine) handleRepetition(event *RepetitionDetectedEvent) bool {
+	event.Interrupted = e.config.RepetitionAction == RepetitionInterrupt
+	e.emit(event)
+	e.carry(repetitionHint(event))
+
+	return event.Interrupted
+}
+
+// repetitionHint tells the model what it keeps repeating.
+func repetitionHint(event *RepetitionDetectedEvent) string {
+	if event.Repetition == RepetitionTool {
+		return fmt.Sprintf("You ran the same tool call %d times and it failed the same way every time (%s). "+
+			"Do not run it again unchanged; investigate the cause or try a different approach.", event.Count, event.Detail)
+	}
+
+	return fmt.Sprintf("Your response was %.0f%% identical to an earlier one. "+
+		"Do not repeat yourself; make concrete progress with a different approach.", event.Similarity*100)
+}
@@ -0,0 +1,166 @@
+package core
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/JanDeDobbeleer/copilot-ralph/internal/sdk"
+)
+
+// longResponse is a response long enough for similarity checks.
+const longResponse = "I looked at the parser and the failing test again. The tokenizer does not handle " +
+	"escaped quotes, so the test for string literals keeps failing. I will fix the tokenizer next."
+
+func TestSimilarity(t *testing.T) {
+	words := strings.Fields(longResponse)
+
+	assert.InDelta(t, 1.0, similarity(words, words), 0.001)
+	assert.InDelta(t, 0.0, similarity(words, strings.Fields("completely unrelated text about something else entirely")), 0.001)
+	assert.InDelta(t, 0.0, similarity(nil, nil), 0.001)
+
+	changed := append(strings.Fields(longResponse), "Then", "I", "will", "run", "the", "tests.")
+	assert.Greater(t, similarity(words, changed), 0.7)
+}
+
+func TestRepetitionDetector_Tools(t *testing.T) {
+	detector := newRepetitionDetector(&LoopConfig{RepetitionLimit: 3})
+	failure := errors.New("exit status 1")
+	params := map[string]any{"command": "go test ./..."}
+
+	// Successful and different invocations are not counted
+	assert.Nil(t, detector.observe(NewToolExecutionEvent("bash", params, "ok", nil, 0, 1)))
+	assert.Nil(t, detector.observe(NewToolExecutionEvent("bash", map[string]any{"command": "go vet"}, "", failure, 0, 1)))
+
+	assert.Nil(t, detector.observe(NewToolExecutionEvent("bash", params, "", failure, 0, 1)))
+	assert.Nil(t, detector.observe(NewToolExecutionEvent("bash", params, "", failure, 0, 2)))
+
+	event := detector.observe(NewToolExecutionEvent("bash", params, "", failure, 0, 2))
+	require.NotNil(t, event)
+	assert.Equal(t, RepetitionTool, event.Repetition)
+	assert.Equal(t, 3, event.Count)
+	assert.Equal(t, 2, event.Iteration)
+	assert.Contains(t, event.Detail, "exit status 1")
+
+	// Reported once per iteration
+	assert.Nil(t, detector.observe(NewToolExecutionEvent("bash", params, "", failure, 0, 2)))
+	assert.NotNil(t, detector.observe(NewToolExecutionEvent("bash", params, "", failure, 0, 3)))
+}
+
+func TestRepetitionDetector_Responses(t *testing.T) {
+	detector := newRepetitionDetector(&LoopConfig{ResponseSimilarity: 0.8})
+
+	detector.observe(NewAIResponseEvent(longResponse, 1))
+	assert.Nil(t, detector.endMessage(1))
+
+	// Short responses are never compared
+	detector.observe(NewAIResponseEvent("Done.", 2))
+	assert.Nil(t, detector.endMessage(2))
+	detector.observe(NewAIResponseEvent("Done.", 2))
+	assert.Nil(t, detector.endMessage(2))
+
+	detector.observe(NewAIResponseEvent(strings.ToUpper(longResponse[:40]), 2))
+	detector.observe(NewAIResponseEvent(longResponse[40:], 2))
+	event := detector.endMessage(2)
+	require.NotNil(t, event)
+	assert.Equal(t, RepetitionResponse, event.Repetition)
+	assert.InDelta(t, 1.0, event.Similarity, 0.001)
+
+	disabled := newRepetitionDetector(&LoopConfig{})
+	disabled.observe(NewAIResponseEvent(longResponse, 1))
+	assert.Nil(t, disabled.endMessage(1))
+	disabled.observe(NewAIResponseEvent(longResponse, 2))
+	assert.Nil(t, disabled.endMessage(2))
+}
+
+func TestLoopEngine_RepetitionActions(t *testing.T) {
+	tests := []struct {
+		name            string
+		action          RepetitionAction
+		wantInterrupted bool
+	}{
+		{
+			name:   "hint",
+			action: RepetitionHint,
+		},
+		{
+			name:            "interrupt",
+			action:          RepetitionInterrupt,
+			wantInterrupted: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockSDK := NewMockSDKClient()
+			mockSDK.ToolCalls = []sdk.ToolCall{{Name: "bash", Parameters: map[string]any{"command": "make"}}}
+			mockSDK.ToolError = errors.New("make: *** no rule")
+
+			config := &LoopConfig{
+				Prompt:           "Test task",
+				MaxIterations:    3,
+				PromisePhrase:    "done",
+				RepetitionLimit:  2,
+				RepetitionAction: tt.action,
+			}
+			engine := NewLoopEngine(config, mockSDK)
+
+			var detected []*RepetitionDetectedEvent
+			done := make(chan struct{})
+			go func() {
+				defer close(done)
+				for event := range engine.Events() {
+					if ev, ok := event.(*RepetitionDetectedEvent); ok {
+						detected = append(detected, ev)
+					}
+				}
+			}()
+
+			_, err := engine.Start(context.Background())
+			<-done
+			require.NoError(t, err)
+
+			require.Len(t, detected, 2)
+			assert.Equal(t, 2, detected[0].Iteration)
+			assert.Equal(t, tt.wantInterrupted, detected[0].Interrupted)
+
+			require.Len(t, mockSDK.Prompts, 3)
+			assert.NotContains(t, mockSDK.Prompts[1], "You ran the same tool call")
+			assert.Contains(t, mockSDK.Prompts[2], "You ran the same tool call 2 times")
+			assert.Contains(t, mockSDK.Prompts[2], "make: *** no rule")
+		})
+	}
+}
+
+func TestLoopEngine_RepetitionInterruptSettlesSession(t *testing.T) {
+	mockSDK := NewMockSDKClient()
+	mockSDK.ToolCalls = []sdk.ToolCall{{Name: "bash", Parameters: map[string]any{"command": "make"}}}
+	mockSDK.ToolError = errors.New("make: *** no rule")
+	mockSDK.HoldTurn = true
+	mockSDK.AbortDelay = 50 * time.Millisecond
+
+	config := &LoopConfig{
+		Prompt:           "Test task",
+		MaxIterations:    3,
+		Timeout:          time.Minute,
+		IterationTimeout: 100 * time.Millisecond,
+		PromisePhrase:    "done",
+		RepetitionLimit:  2,
+		RepetitionAction: RepetitionInterrupt,
+	}
+	engine := NewLoopEngine(config, mockSDK)
+	drainEvents(engine)
+
+	_, err := engine.Start(context.Background())
+	require.NoError(t, err)
+
+	require.Len(t, mockSDK.Prompts, 3)
+	assert.Contains(t, mockSDK.Prompts[2], "You ran the same tool call 2 times")
+	assert.Zero(t, mockSDK.Overlaps, "the steering prompt waits for the interrupted turn")
+	assert.Equal(t, 1, mockSDK.SessionsCreated)
+}
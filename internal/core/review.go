@@ -0,0 +1,294 @@
+// Package core provides reviews of each iteration by a second model.
+
+package core
+
+import (
+	"context"
+	"fmt"
+	"regexp"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	"github.com/JanDeDobbeleer/copilot-ralph/internal/sdk"
+)
+
+// ReviewVerdict is the reviewer's judgement of an iteration.
+type ReviewVerdict string
+
+const (
+	// ReviewApprove means the reviewer considers the task complete and correct.
+	ReviewApprove ReviewVerdict = "approve"
+	// ReviewRequestChanges means the reviewer wants the worker to keep going.
+	ReviewRequestChanges ReviewVerdict = "request-changes"
+)
+
+// String returns the string representation of the verdict.
+func (v ReviewVerdict) String() string {
+	return string(v)
+}
+
+// maxReviewDiff is the maximum number of bytes of the iteration diff sent to the reviewer.
+const maxReviewDiff = 32 * 1024
+
+// maxCritique is the maximum number of bytes of the critique carried into the next prompt.
+const maxCritique = 4000
+
+// verdictPattern matches the verdict tag at the end of a review.
+var verdictPattern = regexp.MustCompile(`(?i)<verdict>\s*(approve|request[- ]changes)\s*</verdict>`)
+
+// reviewInstructions tells the reviewer what to assess and how to answer.
+const reviewInstructions = "You are reviewing iteration %d of an autonomous coding agent working on the task below. " +
+	"Only review: do not modify files or run commands that change the working directory.\n\n" +
+	"Assess whether the changes are correct, complete and move the task forward. Point out bugs, " +
+	"missing pieces and risky shortcuts as concrete instructions the agent can act on in its next iteration.\n\n" +
+	"End your response with <verdict>approve</verdict> when the task is complete and correct, " +
+	"or <verdict>request-changes</verdict> otherwise."
+
+// Review is the outcome of a reviewer pass over an iteration.
+type Review struct {
+	// Verdict is the reviewer's judgement, request-changes when it gave none.
+	Verdict ReviewVerdict
+	// Critique is the review without the verdict tag.
+	Critique string
+}
+
+// Approved reports whether the reviewer approved the iteration.
+func (r *Review) Approved() bool {
+	return r.Verdict == ReviewApprove
+}
+
+// parseReview extracts the verdict and critique from a reviewer response.
+// The last verdict tag wins, a response without one requests changes.
+func parseReview(response string) *Review {
+	review := &Review{Verdict: ReviewRequestChanges}
+
+	matches := verdictPattern.FindAllStringSubmatch(response, -1)
+	if len(matches) > 0 && strings.EqualFold(matches[len(matches)-1][1], string(ReviewApprove)) {
+		review.Verdict = ReviewApprove
+	}
+
+	critique := strings.TrimSpace(verdictPattern.ReplaceAllString(response, ""))
+	if len(critique) > maxCritique {
+		critique = truncateText(critique, maxCritique) + "\n... (critique truncated)"
+	}
+	review.Critique = critique
+
+	return review
+}
+
+// reviewFeedback builds the note carrying the review into the next worker prompt.
+func reviewFeedback(iteration int, review *Review, promiseRejected bool) string {
+	var builder strings.Builder
+
+	builder.WriteString(fmt.Sprintf("## Reviewer feedback on iteration %d (verdict: %s)\n\n", iteration, review.Verdict))
+	if promiseRejected {
+		builder.WriteString("Your promise was not accepted because the reviewer did not approve. ")
+		builder.WriteString("Address the review before outputting the completion phrase again.\n\n")
+	}
+
+	critique := review.Critique
+	if critique == "" {
+		critique = "(no critique given)"
+	}
+	builder.WriteString(critique)
+
+	return builder.String()
+}
+
+// SetReviewer configures a second SDK client that reviews every iteration.
+// It must be called before Start.
+func (e *LoopEngine) SetReviewer(reviewer SDKClient) {
+	e.reviewer = reviewer
+}
+
+// startReviewer starts the reviewer client and creates its session.
+func (e *LoopEngine) startReviewer() error {
+	if e.reviewer == nil {
+		return nil
+	}
+
+	if err := e.reviewer.Start(); err != nil {
+		return err
+	}
+
+	return e.reviewer.CreateSession(e.ctx)
+}
+
+// stopReviewer destroys the reviewer session and stops its client.
+func (e *LoopEngine) stopReviewer(timeout time.Duration) {
+	if e.reviewer == nil {
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), timeout)
+	defer cancel()
+
+	_ = e.reviewer.DestroySession(ctx)
+	_ = e.reviewer.Stop()
+}
+
+// recordReviewBase snapshots the working tree before an iteration so its diff can be reviewed.
+func (e *LoopEngine) recordReviewBase() {
+	if e.reviewer == nil {
+		return
+	}
+
+	// Outside a git repository the reviewer only sees the final message
+	e.reviewBase, _ = snapshotTree(e.ctx, e.gitDir())
+}
+
+// iterationDiff returns the changes made since recordReviewBase.
+func (e *LoopEngine) iterationDiff() string {
+	unavailable := "(no diff available, the working directory is not a git repository)"
+	if e.reviewBase == "" {
+		return unavailable
+	}
+
+	current, err := snapshotTree(e.ctx, e.gitDir())
+	if err != nil {
+		return unavailable
+	}
+
+	diff, err := runGit(e.ctx, e.gitDir(), "diff", "--no-color", e.reviewBase, current)
+	if err != nil {
+		return unavailable
+	}
+
+	if diff == "" {
+		return "(no changes)"
+	}
+
+	if len(diff) > maxReviewDiff {
+		diff = truncateText(diff, maxReviewDiff) + "\n... (diff truncated)"
+	}
+
+	return diff
+}
+
+// truncateText keeps at most the first limit bytes of text without splitting a UTF-8 character.
+func truncateText(text string, limit int) string {
+	if len(text) <= limit {
+		return text
+	}
+
+	for limit > 0 && !utf8.RuneStart(text[limit]) {
+		limit--
+	}
+
+	return text[:limit]
+}
+
+// reviewPrompt builds the prompt asking the reviewer to judge an iteration.
+func (e *LoopEngine) reviewPrompt(iteration int, diff, finalMessage string) string {
+	var builder strings.Builder
+
+	builder.WriteString(fmt.Sprintf(reviewInstructions, iteration))
+	builder.WriteString("\n\n## Task\n\n")
+	builder.WriteString(strings.TrimSpace(e.config.Prompt))
+	if phase := e.currentPhase(); phase != nil {
+		builder.WriteString(fmt.Sprintf("\n\nCurrent phase: %s\n\n%s", phase.Name, strings.TrimSpace(phase.Prompt)))
+	}
+
+	builder.WriteString("\n\n## Changes in this iteration\n\n```diff\n")
+	builder.WriteString(diff)
+	builder.WriteString("\n```\n\n## Agent's final message\n\n")
+
+	message := strings.TrimSpace(finalMessage)
+	if message == "" {
+		message = "(no message)"
+	}
+	builder.WriteString(message)
+
+	return builder.String()
+}
+
+// applyReview reviews the iteration, carries the critique into the next prompt and,
+// when approval is required, withholds a detected promise the reviewer did not approve.
+func (e *LoopEngine) applyReview(iteration int, outcome *iterationResult) error {
+	review, err := e.reviewIteration(iteration, outcome.finalMessage)
+	if err != nil || review == nil {
+		return err
+	}
+
+	if review.Approved() {
+		e.mu.Lock()
+		e.approvals++
+		e.mu.Unlock()
+	}
+
+	if e.config.RequireApproval && outcome.promiseDetected && !review.Approved() {
+		outcome.promiseDetected = false
+		outcome.reviewRejected = true
+	}
+
+	e.carry(reviewFeedback(iteration, review, outcome.reviewRejected))
+
+	return nil
+}
+
+// reviewIteration asks the reviewer for a verdict on the iteration.
+// It returns nil when no reviewer is configured.
+func (e *LoopEngine) reviewIteration(iteration int, finalMessage string) (*Review, error) {
+	if e.reviewer == nil {
+		return nil, nil
+	}
+
+	e.emit(NewReviewStartEvent(e.reviewer.Model(), iteration))
+
+	events, err := e.reviewer.SendPrompt(e.ctx, e.reviewPrompt(iteration, e.iterationDiff(), finalMessage))
+	if err != nil {
+		return nil, fmt.Errorf("failed to send review prompt: %w", err)
+	}
+
+	var streamed strings.Builder
+	var response string
+
+reviewLoop:
+	for {
+		select {
+		case <-e.ctx.Done():
+			return nil, e.ctx.Err()
+		case event, ok := <-events:
+			if !ok {
+				break reviewLoop
+			}
+
+			switch ev := event.(type) {
+			case *sdk.TextEvent:
+				if !ev.Reasoning {
+					e.emit(NewReviewerResponseEvent(ev.Text, iteration))
+					streamed.WriteString(ev.Text)
+				}
+
+			case *sdk.ResponseCompleteEvent:
+				if streamed.Len() == 0 {
+					e.emit(NewReviewerResponseEvent(ev.Message.Content, iteration))
+				}
+
+				streamed.Reset()
+				response = ev.Message.Content
+
+			case *sdk.UsageEvent:
+				if err := e.recordUsage(e.reviewer, ev, iteration); err != nil {
+					return nil, err
+				}
+
+			case *sdk.ToolDeniedEvent:
+				e.emit(NewToolDeniedEvent(ev.Request.Tool(), ev.Request.Summary(), ev.Rule, iteration))
+
+			case *sdk.ErrorEvent:
+				e.emit(NewErrorEvent(fmt.Errorf("reviewer: %w", ev.Err), iteration, true))
+			}
+		}
+	}
+
+	if streamed.Len() > 0 {
+		response = streamed.String()
+	}
+
+	review := parseReview(response)
+	e.emit(NewReviewCompleteEvent(review.Verdict, review.Critique, iteration))
+
+	return review, nil
+}
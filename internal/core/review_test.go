@@ -0,0 +1,144 @@
+package core
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"unicode/utf8"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseReview(t *testing.T) {
+	tests := []struct {
+		name     string
+		response string
+		verdict  ReviewVerdict
+		critique string
+	}{
+		{
+			name:     "approve",
+			response: "Looks good.\n<verdict>approve</verdict>",
+			verdict:  ReviewApprove,
+			critique: "Looks good.",
+		},
+		{
+			name:     "request changes",
+			response: "The parser ignores comments.\n\n<verdict> Request-Changes </verdict>",
+			verdict:  ReviewRequestChanges,
+			critique: "The parser ignores comments.",
+		},
+		{
+			name:     "last verdict wins",
+			response: "Use <verdict>approve</verdict> once fixed. <verdict>request-changes</verdict>",
+			verdict:  ReviewRequestChanges,
+			critique: "Use  once fixed.",
+		},
+		{
+			name:     "missing verdict",
+			response: "Not sure yet.",
+			verdict:  ReviewRequestChanges,
+			critique: "Not sure yet.",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			review := parseReview(tt.response)
+			assert.Equal(t, tt.verdict, review.Verdict)
+			assert.Equal(t, tt.critique, review.Critique)
+		})
+	}
+}
+
+func TestParseReviewTruncatesOnRuneBoundary(t *testing.T) {
+	// A three byte character straddles the critique limit
+	response := strings.Repeat("a", maxCritique-1) + "€ more\n<verdict>approve</verdict>"
+
+	review := parseReview(response)
+	assert.True(t, utf8.ValidString(review.Critique))
+	assert.Equal(t, strings.Repeat("a", maxCritique-1)+"\n... (critique truncated)", review.Critique)
+}
+
+func TestTruncateText(t *testing.T) {
+	assert.Equal(t, "short", truncateText("short", 10))
+	assert.Equal(t, "ab", truncateText("abc", 2))
+	assert.Equal(t, "a", truncateText("aé", 2))
+	assert.Equal(t, "", truncateText("€", 2))
+}
+
+func TestLoopEngine_Reviewer(t *testing.T) {
+	dir := initGitRepo(t)
+
+	worker := NewMockSDKClient()
+	worker.ResponseText = "Added the parser <promise>done</promise>"
+	worker.OnPrompt = func(prompt string) {
+		name := "parser.go"
+		if strings.HasPrefix(prompt, "[Iteration 2/") {
+			name = "parser_test.go"
+		}
+		require.NoError(t, os.WriteFile(filepath.Join(dir, name), []byte("package parser\n"), 0o644))
+	}
+
+	// The reviewer only approves once there are tests
+	reviewer := NewMockSDKClient()
+	require.NoError(t, reviewer.SetModel("reviewer-model"))
+	reviewer.OnPrompt = func(prompt string) {
+		reviewer.ResponseText = "Add tests for the parser.\n<verdict>request-changes</verdict>"
+		if strings.Contains(prompt, "parser_test.go") {
+			reviewer.ResponseText = "Tests added.\n<verdict>approve</verdict>"
+		}
+	}
+
+	config := &LoopConfig{
+		Prompt:          "Write a parser",
+		MaxIterations:   5,
+		PromisePhrase:   "done",
+		WorkingDir:      dir,
+		RequireApproval: true,
+	}
+	engine := NewLoopEngine(config, worker)
+	engine.SetReviewer(reviewer)
+
+	var reviews []*ReviewCompleteEvent
+	var reviewerText strings.Builder
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		for event := range engine.Events() {
+			switch ev := event.(type) {
+			case *ReviewCompleteEvent:
+				reviews = append(reviews, ev)
+			case *ReviewerResponseEvent:
+				reviewerText.WriteString(ev.Text)
+			}
+		}
+	}()
+
+	result, err := engine.Start(context.Background())
+	<-done
+	require.NoError(t, err)
+
+	// The worker promised in iteration 1, but the reviewer only approved iteration 2
+	assert.Equal(t, 2, result.PromiseIteration)
+	assert.Equal(t, 1, result.Approvals)
+
+	require.Len(t, reviews, 2)
+	assert.Equal(t, ReviewRequestChanges, reviews[0].Verdict)
+	assert.Equal(t, ReviewApprove, reviews[1].Verdict)
+	assert.Contains(t, reviewerText.String(), "Add tests for the parser.")
+
+	require.Len(t, reviewer.Prompts, 2)
+	assert.Contains(t, reviewer.Prompts[0], "Write a parser")
+	assert.Contains(t, reviewer.Prompts[0], "+++ b/parser.go")
+	assert.NotContains(t, reviewer.Prompts[1], "+++ b/parser.go")
+	assert.Contains(t, reviewer.Prompts[1], "Added the parser")
+
+	require.Len(t, worker.Prompts, 2)
+	assert.Contains(t, worker.Prompts[1], "## Reviewer feedback on iteration 1 (verdict: request-changes)")
+	assert.Contains(t, worker.Prompts[1], "Your promise was not accepted because the reviewer did not approve.")
+	assert.Contains(t, worker.Prompts[1], "Add tests for the parser.")
+}
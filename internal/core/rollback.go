@@ -0,0 +1,116 @@
+// Package core provides rollback of iterations that make the working tree worse.
+
+package core
+
+import (
+	"fmt"
+)
+
+// rollbackBaseline is the last known good state of the working tree.
+type rollbackBaseline struct {
+	// tree is the git tree snapshot of the working directory.
+	tree string
+	// failures is the number of failures reported by the rollback command.
+	failures int
+	// passed is true when the rollback command exited with code 0.
+	passed bool
+}
+
+// checkRollbackCommand runs the rollback command and returns the resulting baseline,
+// together with the command result for feedback. Failures are counted in the complete
+// output, so failures before the part kept for feedback are not lost.
+func (e *LoopEngine) checkRollbackCommand() (*rollbackBaseline, *commandResult, error) {
+	result, err := runShellCommand(e.ctx, e.config.WorkingDir, e.config.RollbackCommand)
+	if err != nil {
+		return nil, nil, fmt.Errorf("rollback command failed to run: %w", err)
+	}
+
+	failures, _ := parseDiagnostics(result.Output)
+	count := len(failures)
+	if !result.Passed() && count == 0 {
+		count = 1
+	}
+
+	return &rollbackBaseline{failures: count, passed: result.Passed()}, result, nil
+}
+
+// recordRollbackBaseline snapshots the working tree before the first iteration.
+func (e *LoopEngine) recordRollbackBaseline() error {
+	if e.config.RollbackCommand == "" {
+		return nil
+	}
+
+	baseline, _, err := e.checkRollbackCommand()
+	if err != nil {
+		return err
+	}
+
+	baseline.tree, err = snapshotTree(e.ctx, e.gitDir())
+	if err != nil {
+		return fmt.Errorf("rollback requires a git repository: %w", err)
+	}
+
+	e.rollback = baseline
+
+	return nil
+}
+
+// regression describes why the state got worse compared to the baseline,
+// or returns an empty string when it did not.
+func (b *rollbackBaseline) regression(current *rollbackBaseline) string {
+	if b.passed && !current.passed {
+		return "the check command started failing"
+	}
+
+	if current.failures > b.failures {
+		return fmt.Sprintf("the failure count increased from %d to %d", b.failures, current.failures)
+	}
+
+	return ""
+}
+
+// rollbackIfWorse runs the rollback command after an iteration and restores the
+// previous snapshot when the iteration made things worse. It reports whether the
+// iteration was reverted.
+func (e *LoopEngine) rollbackIfWorse(iteration int) (bool, error) {
+	if e.rollback == nil {
+		return false, nil
+	}
+
+	current, result, err := e.checkRollbackCommand()
+	if err != nil {
+		return false, err
+	}
+
+	reason := e.rollback.regression(current)
+	if reason == "" {
+		current.tree, err = snapshotTree(e.ctx, e.gitDir())
+		if err != nil {
+			return false, err
+		}
+		e.rollback = current
+
+		return false, nil
+	}
+
+	if err := restoreTree(e.ctx, e.gitDir(), e.rollback.tree); err != nil {
+		return false, fmt.Errorf("failed to revert iteration %d: %w", iteration, err)
+	}
+
+	e.mu.Lock()
+	e.reverts++
+	e.mu.Unlock()
+
+	e.emit(NewIterationRevertedEvent(reason, e.rollback.failures, current.failures, iteration))
+	e.carry(revertFeedback(iteration, reason, e.config.RollbackCommand, result))
+
+	return true, nil
+}
+
+// revertFeedback tells the model that its last attempt was reverted and why.
+func revertFeedback(iteration int, reason, command string, result *commandResult) string {
+	return fmt.Sprintf("Your changes from iteration %d were reverted because %s when running `%s`. "+
+		"The working directory was restored to its state before that iteration. "+
+		"Try a different approach.\n\n```text\n%s\n```",
+		iteration, reason, command, result.Tail())
+}
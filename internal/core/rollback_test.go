@@ -0,0 +1,165 @@
+package core
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRollbackBaselineRegression(t *testing.T) {
+	tests := []struct {
+		name     string
+		baseline rollbackBaseline
+		current  rollbackBaseline
+		want     string
+	}{
+		{
+			name:     "still passing",
+			baseline: rollbackBaseline{passed: true},
+			current:  rollbackBaseline{passed: true},
+		},
+		{
+			name:     "newly failing",
+			baseline: rollbackBaseline{passed: true},
+			current:  rollbackBaseline{failures: 1},
+			want:     "the check command started failing",
+		},
+		{
+			name:     "more failures",
+			baseline: rollbackBaseline{failures: 2},
+			current:  rollbackBaseline{failures: 5},
+			want:     "the failure count increased from 2 to 5",
+		},
+		{
+			name:     "fewer failures",
+			baseline: rollbackBaseline{failures: 5},
+			current:  rollbackBaseline{failures: 2},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.want, tt.baseline.regression(&tt.current))
+		})
+	}
+}
+
+func TestCheckRollbackCommandCountsFullOutput(t *testing.T) {
+	engine := NewLoopEngine(&LoopConfig{
+		Prompt:          "Test task",
+		WorkingDir:      t.TempDir(),
+		RollbackCommand: "printf 'a.go:1: broken\\nb.go:2: broken\\nc.go:3: broken\\n'; head -c 20000 /dev/zero | tr '\\0' a; echo; exit 1",
+	}, NewMockSDKClient())
+	engine.ctx = context.Background()
+
+	baseline, result, err := engine.checkRollbackCommand()
+	require.NoError(t, err)
+
+	assert.Equal(t, 3, baseline.failures)
+	assert.False(t, baseline.passed)
+	assert.Contains(t, result.Output, "a.go:1: broken")
+	assert.NotContains(t, result.Tail(), "a.go:1: broken")
+}
+
+func TestSnapshotAndRestoreTree(t *testing.T) {
+	dir := initGitRepo(t)
+	ctx := context.Background()
+
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "keep.txt"), []byte("original"), 0o644))
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "gone.txt"), []byte("restore me"), 0o644))
+	require.NoError(t, os.WriteFile(filepath.Join(dir, ".gitignore"), []byte("ignored.txt\n"), 0o644))
+
+	tree, err := snapshotTree(ctx, dir)
+	require.NoError(t, err)
+
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "keep.txt"), []byte("changed"), 0o644))
+	require.NoError(t, os.Remove(filepath.Join(dir, "gone.txt")))
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "new.txt"), []byte("new"), 0o644))
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "ignored.txt"), []byte("ignored"), 0o644))
+
+	require.NoError(t, restoreTree(ctx, dir, tree))
+
+	keep, err := os.ReadFile(filepath.Join(dir, "keep.txt"))
+	require.NoError(t, err)
+	assert.Equal(t, "original", string(keep))
+	assert.FileExists(t, filepath.Join(dir, "gone.txt"))
+	assert.NoFileExists(t, filepath.Join(dir, "new.txt"))
+	assert.FileExists(t, filepath.Join(dir, "ignored.txt"))
+
+	// The user's staging area is left untouched
+	staged, err := runGit(ctx, dir, "diff", "--cached", "--name-only")
+	require.NoError(t, err)
+	assert.Empty(t, staged)
+}
+
+func TestLoopEngine_RollbackWorseIterations(t *testing.T) {
+	dir := initGitRepo(t)
+	status := filepath.Join(dir, "status.txt")
+	require.NoError(t, os.WriteFile(status, []byte("a.go:1: broken\n"), 0o644))
+
+	// Iteration 1 adds a failure, 2 fixes everything, 3 breaks it again
+	writes := map[string]string{
+		"[Iteration 1/3]": "a.go:1: broken\nb.go:2: broken\n",
+		"[Iteration 2/3]": "",
+		"[Iteration 3/3]": "c.go:3: broken\n",
+	}
+
+	mockSDK := NewMockSDKClient()
+	mockSDK.OnPrompt = func(prompt string) {
+		for marker, content := range writes {
+			if strings.HasPrefix(prompt, marker) {
+				require.NoError(t, os.WriteFile(status, []byte(content), 0o644))
+				require.NoError(t, os.WriteFile(filepath.Join(dir, marker[1:12]+".txt"), nil, 0o644))
+			}
+		}
+	}
+
+	config := &LoopConfig{
+		Prompt:          "Test task",
+		MaxIterations:   3,
+		PromisePhrase:   "done",
+		WorkingDir:      dir,
+		RollbackCommand: "cat status.txt; test ! -s status.txt",
+	}
+	engine := NewLoopEngine(config, mockSDK)
+
+	var reverted []*IterationRevertedEvent
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		for event := range engine.Events() {
+			if ev, ok := event.(*IterationRevertedEvent); ok {
+				reverted = append(reverted, ev)
+			}
+		}
+	}()
+
+	result, err := engine.Start(context.Background())
+	<-done
+	require.NoError(t, err)
+
+	assert.Equal(t, 2, result.Reverts)
+	require.Len(t, reverted, 2)
+	assert.Equal(t, 1, reverted[0].Iteration)
+	assert.Equal(t, 1, reverted[0].FailuresBefore)
+	assert.Equal(t, 2, reverted[0].FailuresAfter)
+	assert.Equal(t, 3, reverted[1].Iteration)
+	assert.Equal(t, "the check command started failing", reverted[1].Reason)
+
+	content, err := os.ReadFile(status)
+	require.NoError(t, err)
+	assert.Empty(t, string(content))
+	assert.NoFileExists(t, filepath.Join(dir, "Iteration 1.txt"))
+	assert.FileExists(t, filepath.Join(dir, "Iteration 2.txt"))
+	assert.NoFileExists(t, filepath.Join(dir, "Iteration 3.txt"))
+
+	require.Len(t, mockSDK.Prompts, 3)
+	assert.Contains(t, mockSDK.Prompts[1], "Your changes from iteration 1 were reverted because the failure count increased from 1 to 2")
+	assert.Contains(t, mockSDK.Prompts[1], "b.go:2: broken")
+	assert.NotContains(t, mockSDK.Prompts[2], "reverted")
+}
@@ -0,0 +1,110 @@
+// Package core provides session management strategies for the loop engine.
+
+package core
+
+import (
+	"context"
+	"fmt"
+	"time"
+)
+
+// SessionStrategy determines when the engine replaces the SDK session.
+type SessionStrategy string
+
+const (
+	// SessionPersistent reuses a single session for the whole loop.
+	SessionPersistent SessionStrategy = "persistent"
+	// SessionFresh creates a new session for every iteration, so state only lives in files.
+	SessionFresh SessionStrategy = "fresh"
+	// SessionRotate creates a new session every SessionRotateEvery iterations.
+	SessionRotate SessionStrategy = "rotate"
+)
+
+// String returns the string representation of the strategy.
+func (s SessionStrategy) String() string {
+	return string(s)
+}
+
+// shouldRotateSession reports whether a new session is needed before the given iteration.
+func (e *LoopEngine) shouldRotateSession(iteration int) bool {
+	if iteration <= 1 {
+		return false
+	}
+
+	switch e.config.SessionStrategy {
+	case SessionFresh:
+		return true
+	case SessionRotate:
+		return e.config.SessionRotateEvery > 0 && (iteration-1)%e.config.SessionRotateEvery == 0
+	default:
+		return false
+	}
+}
+
+// rotateSession destroys the current session and creates a new one when the
+// session strategy requires it before the given iteration.
+func (e *LoopEngine) rotateSession(iteration int) error {
+	if e.sdk == nil || !e.shouldRotateSession(iteration) {
+		return nil
+	}
+
+	if err := e.replaceSession(""); err != nil {
+		return err
+	}
+
+	e.emit(NewSessionRotatedEvent(e.config.SessionStrategy, iteration))
+
+	return nil
+}
+
+// switchModel replaces the current session with a new one using the given model.
+func (e *LoopEngine) switchModel(model string) error {
+	if e.sdk == nil {
+		return nil
+	}
+
+	if _, ok := e.sdk.(ModelSwitcher); !ok {
+		return fmt.Errorf("SDK client cannot switch to model %s", model)
+	}
+
+	return e.replaceSession(model)
+}
+
+// replaceSession destroys the current session and creates a new one,
+// switching to model first when it is set and differs from the current model.
+func (e *LoopEngine) replaceSession(model string) error {
+	if e.sdk == nil {
+		return nil
+	}
+
+	var switcher ModelSwitcher
+	if model != "" && model != e.sdk.Model() {
+		var ok bool
+		if switcher, ok = e.sdk.(ModelSwitcher); !ok {
+			return fmt.Errorf("SDK client cannot switch to model %s", model)
+		}
+	}
+
+	destroyCtx, cancel := context.WithTimeout(e.ctx, 5*time.Second)
+	err := e.sdk.DestroySession(destroyCtx)
+	cancel()
+	if err != nil {
+		return fmt.Errorf("failed to destroy SDK session: %w", err)
+	}
+
+	if switcher != nil {
+		if err := switcher.SetModel(model); err != nil {
+			return fmt.Errorf("failed to switch model: %w", err)
+		}
+	}
+
+	if err := e.sdk.CreateSession(e.ctx); err != nil {
+		return fmt.Errorf("failed to create SDK session: %w", err)
+	}
+
+	e.mu.Lock()
+	e.sessions++
+	e.mu.Unlock()
+
+	return nil
+}
@@ -0,0 +1,145 @@
+package core
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestShouldRotateSession(t *testing.T) {
+	tests := []struct {
+		name     string
+		strategy SessionStrategy
+		every    int
+		want     []int
+	}{
+		{
+			name:     "persistent never rotates",
+			strategy: SessionPersistent,
+		},
+		{
+			name: "empty strategy is persistent",
+		},
+		{
+			name:     "fresh rotates every iteration",
+			strategy: SessionFresh,
+			want:     []int{2, 3, 4, 5, 6},
+		},
+		{
+			name:     "rotate every two iterations",
+			strategy: SessionRotate,
+			every:    2,
+			want:     []int{3, 5},
+		},
+		{
+			name:     "rotate without interval never rotates",
+			strategy: SessionRotate,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			engine := NewLoopEngine(&LoopConfig{SessionStrategy: tt.strategy, SessionRotateEvery: tt.every}, nil)
+
+			var got []int
+			for iteration := 1; iteration <= 6; iteration++ {
+				if engine.shouldRotateSession(iteration) {
+					got = append(got, iteration)
+				}
+			}
+
+			assert.Equal(t, tt.want, got)
+		})
+	}
+}
+
+func TestLoopEngine_SessionStrategy(t *testing.T) {
+	tests := []struct {
+		name         string
+		strategy     SessionStrategy
+		every        int
+		wantSessions int
+		wantRotated  []int
+	}{
+		{
+			name:         "persistent",
+			strategy:     SessionPersistent,
+			wantSessions: 1,
+		},
+		{
+			name:         "fresh",
+			strategy:     SessionFresh,
+			wantSessions: 4,
+			wantRotated:  []int{2, 3, 4},
+		},
+		{
+			name:         "rotate",
+			strategy:     SessionRotate,
+			every:        2,
+			wantSessions: 2,
+			wantRotated:  []int{3},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockSDK := NewMockSDKClient()
+			config := &LoopConfig{
+				Prompt:             "Test task",
+				MaxIterations:      4,
+				PromisePhrase:      "done",
+				SessionStrategy:    tt.strategy,
+				SessionRotateEvery: tt.every,
+			}
+			engine := NewLoopEngine(config, mockSDK)
+
+			var rotated []int
+			done := make(chan struct{})
+			go func() {
+				defer close(done)
+				for event := range engine.Events() {
+					if ev, ok := event.(*SessionRotatedEvent); ok {
+						assert.Equal(t, tt.strategy, ev.Strategy)
+						rotated = append(rotated, ev.Iteration)
+					}
+				}
+			}()
+
+			result, err := engine.Start(context.Background())
+			<-done
+
+			require.NoError(t, err)
+			assert.Equal(t, 4, result.Iterations)
+			assert.Equal(t, tt.wantSessions, result.Sessions)
+			assert.Equal(t, tt.wantSessions, mockSDK.SessionsCreated)
+			assert.Equal(t, tt.wantRotated, rotated)
+		})
+	}
+}
+
+func TestLoopEngine_SessionRotationFailure(t *testing.T) {
+	mockSDK := NewMockSDKClient()
+	mockSDK.DestroySessionError = errors.New("destroy failed")
+
+	config := &LoopConfig{
+		Prompt:          "Test task",
+		MaxIterations:   3,
+		PromisePhrase:   "done",
+		SessionStrategy: SessionFresh,
+	}
+	engine := NewLoopEngine(config, mockSDK)
+	go func() {
+		for range engine.Events() {
+		}
+	}()
+
+	result, err := engine.Start(context.Background())
+
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "failed to destroy SDK session")
+	assert.Equal(t, StateFailed, result.State)
+	assert.Equal(t, 1, result.Iterations)
+}
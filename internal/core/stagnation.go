@@ -0,0 +1,175 @@
+// Package core provides stagnation detection for the loop engine.
+
+package core
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"path/filepath"
+)
+
+// StagnationPolicy determines what happens when iterations stop changing files.
+type StagnationPolicy string
+
+const (
+	// StagnationStop fails the loop with ErrStagnation.
+	StagnationStop StagnationPolicy = "stop"
+	// StagnationNudge tells the model it is stuck and should try a different approach.
+	StagnationNudge StagnationPolicy = "nudge"
+	// StagnationSwitchModel continues with StagnationModel in a new session.
+	StagnationSwitchModel StagnationPolicy = "switch-model"
+)
+
+// String returns the string representation of the policy.
+func (p StagnationPolicy) String() string {
+	return string(p)
+}
+
+// ErrStagnation indicates the loop stopped because iterations no longer changed anything.
+var ErrStagnation = errors.New("loop stagnated")
+
+// stagnationNudge is added to the next prompt when the loop is stuck.
+const stagnationNudge = "You are stuck: the last %d iterations did not change any files. " +
+	"Stop re-describing the work and try a different approach."
+
+// fingerprintWorkingDir returns a hash that changes whenever a file in dir changes.
+// Git repositories are fingerprinted with a tree snapshot, other directories by hashing all files.
+func (e *LoopEngine) fingerprintWorkingDir() (string, error) {
+	if tree, err := snapshotTree(e.ctx, e.gitDir()); err == nil {
+		return tree, nil
+	}
+
+	return hashDir(e.gitDir())
+}
+
+// hashDir hashes the paths and contents of all files below dir,
+// skipping the .git and Ralph state directories.
+func hashDir(dir string) (string, error) {
+	hash := sha256.New()
+
+	err := filepath.WalkDir(dir, func(path string, entry fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+
+		if entry.IsDir() && (entry.Name() == ".git" || entry.Name() == StateDirName) {
+			return filepath.SkipDir
+		}
+
+		if !entry.Type().IsRegular() {
+			return nil
+		}
+
+		rel, err := filepath.Rel(dir, path)
+		if err != nil {
+			return err
+		}
+
+		file, err := os.Open(path)
+		if err != nil {
+			return err
+		}
+		defer file.Close()
+
+		hash.Write([]byte(filepath.ToSlash(rel) + "\x00"))
+		if _, err := io.Copy(hash, file); err != nil {
+			return err
+		}
+		hash.Write([]byte{0})
+
+		return nil
+	})
+	if err != nil {
+		return "", fmt.Errorf("failed to fingerprint working directory: %w", err)
+	}
+
+	return hex.EncodeToString(hash.Sum(nil)), nil
+}
+
+// recordFingerprint stores the working directory fingerprint before the first iteration.
+func (e *LoopEngine) recordFingerprint() error {
+	if e.config.StagnationLimit <= 0 {
+		return nil
+	}
+
+	fingerprint, err := e.fingerprintWorkingDir()
+	if err != nil {
+		return err
+	}
+
+	e.fingerprint = fingerprint
+
+	return nil
+}
+
+// checkStagnation compares the working directory with the previous iteration and applies
+// the stagnation policy after StagnationLimit iterations without changes.
+// It returns an error wrapping ErrStagnation when the loop should stop.
+func (e *LoopEngine) checkStagnation(iteration int) error {
+	if e.config.StagnationLimit <= 0 {
+		return nil
+	}
+
+	fingerprint, err := e.fingerprintWorkingDir()
+	if err != nil {
+		return err
+	}
+
+	if fingerprint != e.fingerprint {
+		e.fingerprint = fingerprint
+		e.stagnant = 0
+		return nil
+	}
+
+	e.stagnant++
+	if e.stagnant < e.config.StagnationLimit {
+		return nil
+	}
+
+	stagnant := e.stagnant
+	e.stagnant = 0
+
+	// The fallback chain takes precedence over the stagnation policy until it is exhausted
+	switched, err := e.fallBack(FallbackStagnation, iteration)
+	if err != nil {
+		return err
+	}
+
+	if switched {
+		e.carry(fmt.Sprintf(stagnationNudge, stagnant))
+		return nil
+	}
+
+	policy := e.config.StagnationPolicy
+	if policy == "" {
+		policy = StagnationStop
+	}
+
+	var model string
+	if policy == StagnationSwitchModel && e.sdk != nil && e.sdk.Model() != e.config.StagnationModel {
+		model = e.config.StagnationModel
+	}
+
+	e.emit(NewStagnationEvent(policy, model, stagnant, iteration))
+
+	switch policy {
+	case StagnationNudge:
+	case StagnationSwitchModel:
+		if model != "" {
+			if err := e.switchModel(model); err != nil {
+				return err
+			}
+		}
+	default:
+		return fmt.Errorf("%w: no changes in %d iterations", ErrStagnation, stagnant)
+	}
+
+	e.carry(fmt.Sprintf(stagnationNudge, stagnant))
+
+	return nil
+}
@@ -0,0 +1,129 @@
+package core
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestHashDir(t *testing.T) {
+	dir := t.TempDir()
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "a.txt"), []byte("a"), 0o644))
+
+	first, err := hashDir(dir)
+	require.NoError(t, err)
+
+	// State directories are ignored
+	require.NoError(t, os.MkdirAll(filepath.Join(dir, StateDirName), 0o755))
+	require.NoError(t, os.WriteFile(filepath.Join(dir, StateDirName, "run.json"), []byte("{}"), 0o644))
+
+	unchanged, err := hashDir(dir)
+	require.NoError(t, err)
+	assert.Equal(t, first, unchanged)
+
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "a.txt"), []byte("b"), 0o644))
+
+	changed, err := hashDir(dir)
+	require.NoError(t, err)
+	assert.NotEqual(t, first, changed)
+}
+
+func TestLoopEngine_Stagnation(t *testing.T) {
+	tests := []struct {
+		name          string
+		policy        StagnationPolicy
+		wantErr       bool
+		wantIter      int
+		wantEvents    int
+		wantModel     string
+		wantNudgeIter int
+	}{
+		{
+			name:       "stop",
+			policy:     StagnationStop,
+			wantErr:    true,
+			wantIter:   3,
+			wantEvents: 1,
+			wantModel:  "mock-model",
+		},
+		{
+			name:          "nudge",
+			policy:        StagnationNudge,
+			wantIter:      6,
+			wantEvents:    2,
+			wantModel:     "mock-model",
+			wantNudgeIter: 4,
+		},
+		{
+			name:          "switch model",
+			policy:        StagnationSwitchModel,
+			wantIter:      6,
+			wantEvents:    2,
+			wantModel:     "better-model",
+			wantNudgeIter: 4,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			dir := t.TempDir()
+
+			// Only the first iteration changes a file
+			mockSDK := NewMockSDKClient()
+			mockSDK.OnPrompt = func(prompt string) {
+				if strings.HasPrefix(prompt, "[Iteration 1/") {
+					require.NoError(t, os.WriteFile(filepath.Join(dir, "work.txt"), []byte("work"), 0o644))
+				}
+			}
+
+			config := &LoopConfig{
+				Prompt:           "Test task",
+				MaxIterations:    6,
+				PromisePhrase:    "done",
+				WorkingDir:       dir,
+				StagnationLimit:  2,
+				StagnationPolicy: tt.policy,
+				StagnationModel:  "better-model",
+			}
+			engine := NewLoopEngine(config, mockSDK)
+
+			var events []*StagnationEvent
+			done := make(chan struct{})
+			go func() {
+				defer close(done)
+				for event := range engine.Events() {
+					if ev, ok := event.(*StagnationEvent); ok {
+						events = append(events, ev)
+					}
+				}
+			}()
+
+			result, err := engine.Start(context.Background())
+			<-done
+
+			if tt.wantErr {
+				require.ErrorIs(t, err, ErrStagnation)
+				assert.Equal(t, StateFailed, result.State)
+			} else {
+				require.NoError(t, err)
+			}
+
+			assert.Equal(t, tt.wantIter, result.Iterations)
+			require.Len(t, events, tt.wantEvents)
+			assert.Equal(t, 3, events[0].Iteration)
+			assert.Equal(t, 2, events[0].Iterations)
+			assert.Equal(t, tt.policy, events[0].Policy)
+			assert.Equal(t, tt.wantModel, mockSDK.Model())
+
+			if tt.wantNudgeIter > 0 {
+				assert.Contains(t, mockSDK.Prompts[tt.wantNudgeIter-1], "You are stuck")
+				assert.NotContains(t, mockSDK.Prompts[tt.wantNudgeIter-2], "You are stuck")
+			}
+		})
+	}
+}
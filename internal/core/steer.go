@@ -0,0 +1,71 @@
+// Package core provides steering messages the user sends to a running loop.
+
+package core
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+)
+
+// ErrEmptySteering is returned when steering the loop with an empty message.
+var ErrEmptySteering = errors.New("steering message is empty")
+
+// steeringFollowUp introduces a steering message sent to the running iteration.
+const steeringFollowUp = "Message from the user while you are working, take it into account from now on:\n\n%s"
+
+// steeringNote carries a steering message into the next iteration prompt.
+const steeringNote = "The user sent this message while the loop was running, follow it from now on:\n\n%s"
+
+// Steer corrects the course of a running loop with a message from the user.
+// While the model processes an iteration prompt and the SDK client supports it,
+// the message is sent as a follow-up in the current session. Otherwise it is
+// appended to the next iteration prompt.
+func (e *LoopEngine) Steer(message string) error {
+	message = strings.TrimSpace(message)
+	if message == "" {
+		return ErrEmptySteering
+	}
+
+	e.mu.RLock()
+	state := e.state
+	iteration := e.iteration
+	prompting := e.prompting
+	e.mu.RUnlock()
+
+	switch state {
+	case StateComplete, StateFailed, StateCancelled:
+		return ErrLoopFinished
+	}
+
+	// The lock is not held while the follow-up is sent, so the prompt may end meanwhile.
+	// The message is then carried into the next prompt as well, in case the model missed it.
+	sent := false
+	if sender, ok := e.sdk.(FollowUpSender); ok && prompting {
+		sent = sender.SendFollowUp(fmt.Sprintf(steeringFollowUp, message)) == nil && e.stillPrompting(iteration)
+	}
+
+	if sent {
+		e.emit(NewSteeringEvent(message, true, iteration))
+		return nil
+	}
+
+	e.carry(fmt.Sprintf(steeringNote, message))
+	e.emit(NewSteeringEvent(message, false, iteration+1))
+
+	return nil
+}
+
+// stillPrompting reports whether the model is still processing the prompt of the given iteration.
+func (e *LoopEngine) stillPrompting(iteration int) bool {
+	e.mu.RLock()
+	defer e.mu.RUnlock()
+	return e.prompting && e.iteration == iteration
+}
+
+// setPrompting records whether the model is processing an iteration prompt.
+func (e *LoopEngine) setPrompting(prompting bool) {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	e.prompting = prompting
+}
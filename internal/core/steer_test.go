@@ -0,0 +1,131 @@
+package core
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestLoopEngine_SteerRunningIteration(t *testing.T) {
+	mockSDK := NewMockSDKClient()
+	mockSDK.ResponseDelay = 200 * time.Millisecond
+
+	config := &LoopConfig{Prompt: "Test task", MaxIterations: 1, PromisePhrase: "done"}
+	engine := NewLoopEngine(config, mockSDK)
+
+	var steering []*SteeringEvent
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		for event := range engine.Events() {
+			if ev, ok := event.(*SteeringEvent); ok {
+				steering = append(steering, ev)
+			}
+		}
+	}()
+
+	go func() {
+		prompting := func() bool {
+			engine.mu.RLock()
+			defer engine.mu.RUnlock()
+			return engine.prompting
+		}
+		assert.Eventually(t, prompting, time.Second, time.Millisecond)
+		assert.NoError(t, engine.Steer("  Stop refactoring, fix the failing test  "))
+	}()
+
+	_, err := engine.Start(context.Background())
+	<-done
+	require.NoError(t, err)
+
+	require.Len(t, mockSDK.FollowUps, 1)
+	assert.Contains(t, mockSDK.FollowUps[0], "Stop refactoring, fix the failing test")
+
+	require.Len(t, steering, 1)
+	assert.True(t, steering[0].Immediate)
+	assert.Equal(t, 1, steering[0].Iteration)
+	assert.Equal(t, "Stop refactoring, fix the failing test", steering[0].Message)
+
+	assert.ErrorIs(t, engine.Steer("too late"), ErrLoopFinished)
+}
+
+func TestLoopEngine_SteerPromptEndsDuringFollowUp(t *testing.T) {
+	mockSDK := NewMockSDKClient()
+	mockSDK.ResponseDelay = 200 * time.Millisecond
+
+	config := &LoopConfig{Prompt: "Test task", MaxIterations: 2, PromisePhrase: "done"}
+	engine := NewLoopEngine(config, mockSDK)
+
+	// The prompt finishes while the follow-up is on its way, which needs the engine lock
+	mockSDK.OnFollowUp = func(string) {
+		engine.setPrompting(false)
+	}
+
+	var steering []*SteeringEvent
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		for event := range engine.Events() {
+			if ev, ok := event.(*SteeringEvent); ok {
+				steering = append(steering, ev)
+			}
+		}
+	}()
+
+	go func() {
+		prompting := func() bool {
+			engine.mu.RLock()
+			defer engine.mu.RUnlock()
+			return engine.prompting
+		}
+		assert.Eventually(t, prompting, time.Second, time.Millisecond)
+		assert.NoError(t, engine.Steer("Keep the public API"))
+	}()
+
+	_, err := engine.Start(context.Background())
+	<-done
+	require.NoError(t, err)
+
+	require.Len(t, mockSDK.FollowUps, 1)
+	require.Len(t, steering, 1)
+	assert.False(t, steering[0].Immediate)
+	assert.Equal(t, 2, steering[0].Iteration)
+
+	require.Len(t, mockSDK.Prompts, 2)
+	assert.Contains(t, mockSDK.Prompts[1], "Keep the public API")
+}
+
+func TestLoopEngine_SteerNextIteration(t *testing.T) {
+	mockSDK := NewMockSDKClient()
+	config := &LoopConfig{Prompt: "Test task", MaxIterations: 1, PromisePhrase: "done"}
+	engine := NewLoopEngine(config, mockSDK)
+
+	assert.ErrorIs(t, engine.Steer(" "), ErrEmptySteering)
+	require.NoError(t, engine.Steer("Use the existing parser"))
+
+	var steering []*SteeringEvent
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		for event := range engine.Events() {
+			if ev, ok := event.(*SteeringEvent); ok {
+				steering = append(steering, ev)
+			}
+		}
+	}()
+
+	_, err := engine.Start(context.Background())
+	<-done
+	require.NoError(t, err)
+
+	assert.Empty(t, mockSDK.FollowUps)
+	require.Len(t, mockSDK.Prompts, 1)
+	assert.Contains(t, mockSDK.Prompts[0], "Use the existing parser")
+
+	require.Len(t, steering, 1)
+	assert.False(t, steering[0].Immediate)
+	assert.Equal(t, 1, steering[0].Iteration)
+}
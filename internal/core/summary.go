@@ -0,0 +1,47 @@
+// Package core provides iteration summaries for the loop engine.
+
+package core
+
+import (
+	"fmt"
+	"strings"
+)
+
+// summaryRequest asks the model to end its response with a summary Ralph can carry over.
+const summaryRequest = "Before the completion phrase (if any), end your response with a short summary of what you changed and what remains, wrapped in <summary></summary> tags."
+
+// extractSummary derives the summary carried into the next iteration from the final
+// assistant message. A <summary> block is preferred; otherwise the message itself is used.
+// The promise tag is stripped and the result is bounded to limit characters.
+// A limit of 0 or less disables summaries.
+func extractSummary(message, promisePhrase string, limit int) string {
+	if limit <= 0 {
+		return ""
+	}
+
+	summary := message
+	tagged := false
+
+	if _, rest, found := strings.Cut(message, "<summary>"); found {
+		if content, _, closed := strings.Cut(rest, "</summary>"); closed {
+			summary = content
+			tagged = true
+		}
+	}
+
+	if promisePhrase != "" {
+		summary = strings.ReplaceAll(summary, fmt.Sprintf("<promise>%s</promise>", promisePhrase), "")
+	}
+
+	runes := []rune(strings.TrimSpace(summary))
+	if len(runes) <= limit {
+		return string(runes)
+	}
+
+	// A tagged summary starts with the essentials, a raw message usually ends with them
+	if tagged {
+		return strings.TrimSpace(string(runes[:limit])) + " …"
+	}
+
+	return "… " + strings.TrimSpace(string(runes[len(runes)-limit:]))
+}
@@ -0,0 +1,119 @@
+package core
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestExtractSummary(t *testing.T) {
+	tests := []struct {
+		name     string
+		message  string
+		expected string
+		limit    int
+	}{
+		{
+			name:     "disabled",
+			message:  "Did things",
+			limit:    0,
+			expected: "",
+		},
+		{
+			name:     "raw message",
+			message:  "  Added the parser tests.  ",
+			limit:    100,
+			expected: "Added the parser tests.",
+		},
+		{
+			name:     "summary block preferred",
+			message:  "Lots of chatter\n<summary>Fixed lexer, parser remains</summary>\nmore",
+			limit:    100,
+			expected: "Fixed lexer, parser remains",
+		},
+		{
+			name:     "promise stripped",
+			message:  "All tests pass. <promise>done</promise>",
+			limit:    100,
+			expected: "All tests pass.",
+		},
+		{
+			name:     "raw message keeps the tail",
+			message:  "preamble that is long, summary at the end",
+			limit:    10,
+			expected: "… at the end",
+		},
+		{
+			name:     "summary block keeps the head",
+			message:  "<summary>first things first, details later</summary>",
+			limit:    12,
+			expected: "first things …",
+		},
+		{
+			name:     "unclosed summary tag uses message",
+			message:  "<summary>never closed",
+			limit:    100,
+			expected: "<summary>never closed",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.expected, extractSummary(tt.message, "done", tt.limit))
+		})
+	}
+}
+
+func TestLoopEngine_PreviousSummary(t *testing.T) {
+	mockSDK := NewMockSDKClient()
+	mockSDK.NonStreaming = true
+	mockSDK.ResponseText = "<summary>Wrote the lexer</summary>"
+
+	config := &LoopConfig{
+		Prompt:         "Build a parser",
+		MaxIterations:  2,
+		PromisePhrase:  "done",
+		SummaryLimit:   100,
+		RequestSummary: true,
+	}
+	engine := NewLoopEngine(config, mockSDK)
+
+	var responses []string
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		for event := range engine.Events() {
+			if ev, ok := event.(*AIResponseEvent); ok {
+				responses = append(responses, ev.Text)
+			}
+		}
+	}()
+
+	_, err := engine.Start(context.Background())
+	<-done
+	require.NoError(t, err)
+
+	// Complete messages are displayed when nothing was streamed
+	assert.Len(t, responses, 2)
+
+	require.Len(t, mockSDK.Prompts, 2)
+	assert.NotContains(t, mockSDK.Prompts[0], "Previous iteration summary")
+	assert.Contains(t, mockSDK.Prompts[1], "## Previous iteration summary\n\nWrote the lexer")
+	assert.True(t, strings.HasSuffix(mockSDK.Prompts[1], summaryRequest))
+}
+
+func TestLoopEngine_PromiseInCompleteMessage(t *testing.T) {
+	mockSDK := NewMockSDKClient()
+	mockSDK.NonStreaming = true
+	mockSDK.SimulatePromise = true
+	mockSDK.PromisePhrase = "done"
+
+	engine := NewLoopEngine(&LoopConfig{Prompt: "Task", MaxIterations: 3, PromisePhrase: "done"}, mockSDK)
+
+	result, err := engine.Start(context.Background())
+	require.NoError(t, err)
+	assert.Equal(t, 1, result.PromiseIteration)
+}
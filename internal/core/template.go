@@ -0,0 +1,106 @@
+// Package core provides prompt templating for the loop engine.
+
+package core
+
+import (
+	"fmt"
+	"strings"
+	"text/template"
+	"time"
+)
+
+// PromptData holds the variables available to prompt templates.
+//
+// Both the user prompt and the system prompt are rendered with text/template,
+// so they can reference fields such as {{.Iteration}} or {{.Vars.branch}}.
+type PromptData struct {
+	// Vars holds user supplied --var key=value pairs.
+	Vars map[string]string
+	// Promise is the completion promise phrase.
+	Promise string
+	// Phase is the name of the active phase of a multi-phase run, empty otherwise.
+	Phase string
+	// Checklist is the task list file of a checklist run, which completes on checked items
+	// instead of the promise. It is empty otherwise.
+	Checklist string
+	// WorkingDir is the loop working directory.
+	WorkingDir string
+	// Model is the configured AI model.
+	Model string
+	// PreviousOutcome describes how the previous iteration ended, empty for the first iteration.
+	PreviousOutcome string
+	// PreviousSummary is the summary of the previous iteration's final message, if any.
+	PreviousSummary string
+	// Iteration is the current iteration number (1-based).
+	Iteration int
+	// MaxIterations is the maximum number of iterations.
+	MaxIterations int
+	// Elapsed is the time spent in the loop so far.
+	Elapsed time.Duration
+	// Remaining is the time left before the loop times out, 0 without timeout.
+	Remaining time.Duration
+	// Phased is true in a multi-phase run, where every phase has its own promise.
+	// The system prompt is shared by all phases, so it must not name a single promise.
+	Phased bool
+}
+
+// newPromptData creates prompt data for the given configuration and iteration.
+func newPromptData(cfg *LoopConfig, iteration int, elapsed time.Duration) *PromptData {
+	data := &PromptData{
+		Vars:          cfg.Vars,
+		Promise:       cfg.PromisePhrase,
+		WorkingDir:    cfg.WorkingDir,
+		Checklist:     cfg.Checklist,
+		Model:         cfg.Model,
+		Iteration:     iteration,
+		MaxIterations: cfg.MaxIterations,
+		Elapsed:       elapsed.Round(time.Second),
+		Phased:        len(cfg.Phases) > 0,
+	}
+
+	if data.Vars == nil {
+		data.Vars = map[string]string{}
+	}
+
+	if cfg.Timeout > 0 {
+		data.Remaining = max(cfg.Timeout-elapsed, 0).Round(time.Second)
+	}
+
+	return data
+}
+
+// renderTemplate renders a prompt template with the given data.
+// Referencing an unknown variable is an error.
+func renderTemplate(name, text string, data *PromptData) (string, error) {
+	tmpl, err := template.New(name).Option("missingkey=error").Parse(text)
+	if err != nil {
+		return "", fmt.Errorf("invalid %s template: %w", name, err)
+	}
+
+	var builder strings.Builder
+	if err := tmpl.Execute(&builder, data); err != nil {
+		return "", fmt.Errorf("failed to render %s template: %w", name, err)
+	}
+
+	return builder.String(), nil
+}
+
+// BuildSystemPrompt renders the embedded system prompt template for the given configuration.
+func BuildSystemPrompt(cfg *LoopConfig) (string, error) {
+	return RenderSystemPrompt(systemPromptTemplate, cfg)
+}
+
+// RenderSystemPrompt renders a system prompt template for the given configuration.
+// The system prompt is rendered once for the whole run, so iteration and phase
+// specific variables reflect the start of the loop.
+func RenderSystemPrompt(text string, cfg *LoopConfig) (string, error) {
+	return renderTemplate("system prompt", text, newPromptData(cfg, 0, 0))
+}
+
+// PreviewPrompt renders the first iteration prompt without running the loop.
+// It validates the prompt template and is used for dry-run output.
+func PreviewPrompt(cfg *LoopConfig) (string, error) {
+	engine := NewLoopEngine(cfg, nil)
+	engine.startTime = time.Now()
+	return engine.buildIterationPrompt(1)
+}
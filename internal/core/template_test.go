@@ -0,0 +1,126 @@
+package core
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRenderTemplate(t *testing.T) {
+	data := newPromptData(&LoopConfig{
+		PromisePhrase: "done",
+		Model:         "gpt-4",
+		WorkingDir:    "/src",
+		MaxIterations: 10,
+		Timeout:       10 * time.Minute,
+		Vars:          map[string]string{"pkg": "parser"},
+	}, 3, 4*time.Minute)
+
+	tests := []struct {
+		name     string
+		text     string
+		expected string
+		errorMsg string
+	}{
+		{
+			name:     "plain text",
+			text:     "Fix the bug",
+			expected: "Fix the bug",
+		},
+		{
+			name:     "loop variables",
+			text:     "{{.Iteration}}/{{.MaxIterations}} {{.Elapsed}} elapsed, {{.Remaining}} left in {{.WorkingDir}} with {{.Model}}",
+			expected: "3/10 4m0s elapsed, 6m0s left in /src with gpt-4",
+		},
+		{
+			name:     "user variables",
+			text:     "Add tests for {{.Vars.pkg}}",
+			expected: "Add tests for parser",
+		},
+		{
+			name:     "unknown user variable",
+			text:     "{{.Vars.missing}}",
+			errorMsg: "failed to render prompt template",
+		},
+		{
+			name:     "unknown field",
+			text:     "{{.Task}}",
+			errorMsg: "failed to render prompt template",
+		},
+		{
+			name:     "invalid syntax",
+			text:     "{{.Iteration",
+			errorMsg: "invalid prompt template",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			result, err := renderTemplate("prompt", tt.text, data)
+
+			if tt.errorMsg != "" {
+				require.Error(t, err)
+				assert.Contains(t, err.Error(), tt.errorMsg)
+				return
+			}
+
+			require.NoError(t, err)
+			assert.Equal(t, tt.expected, result)
+		})
+	}
+}
+
+func TestBuildSystemPrompt(t *testing.T) {
+	prompt, err := BuildSystemPrompt(&LoopConfig{PromisePhrase: "All done"})
+
+	require.NoError(t, err)
+	assert.Contains(t, prompt, "<promise>All done</promise>")
+	assert.NotContains(t, prompt, "{{")
+
+	// Phases have their own promise, the shared system prompt must not name the loop promise
+	cfg := &LoopConfig{PromisePhrase: "All done", MaxIterations: 2}
+	plan := &Plan{Phases: []Phase{{Name: "plan", Promise: "PLANNED"}, {Name: "build", Promise: "BUILT"}}}
+	plan.Apply(cfg)
+
+	prompt, err = BuildSystemPrompt(cfg)
+	require.NoError(t, err)
+	assert.NotContains(t, prompt, "All done")
+	assert.Contains(t, prompt, "completion phrase of the current phase")
+
+	// Checklist runs complete on checked items, not on a promise
+	prompt, err = BuildSystemPrompt(&LoopConfig{PromisePhrase: "All done", Checklist: "TODO.md"})
+	require.NoError(t, err)
+	assert.NotContains(t, prompt, "<promise>")
+	assert.Contains(t, prompt, "Check off each item (`- [x]`) in TODO.md")
+	assert.Contains(t, prompt, "There is no completion phrase to output.")
+}
+
+func TestPreviewPrompt(t *testing.T) {
+	prompt, err := PreviewPrompt(&LoopConfig{Prompt: "Work on {{.Vars.area}}", MaxIterations: 4, Vars: map[string]string{"area": "docs"}})
+	require.NoError(t, err)
+	assert.Equal(t, "[Iteration 1/4]\n\nWork on docs", prompt)
+
+	_, err = PreviewPrompt(&LoopConfig{Prompt: "{{.Vars.area}}", MaxIterations: 4})
+	require.Error(t, err)
+}
+
+func TestLoopEngine_PreviousOutcome(t *testing.T) {
+	mockSDK := NewMockSDKClient()
+
+	config := &LoopConfig{
+		Prompt:        "{{if .PreviousOutcome}}Last time: {{.PreviousOutcome}}{{else}}First run{{end}}",
+		MaxIterations: 2,
+		PromisePhrase: "done",
+	}
+	engine := NewLoopEngine(config, mockSDK)
+
+	_, err := engine.Start(context.Background())
+	require.NoError(t, err)
+
+	require.Len(t, mockSDK.Prompts, 2)
+	assert.Contains(t, mockSDK.Prompts[0], "First run")
+	assert.Contains(t, mockSDK.Prompts[1], "Last time: no completion promise")
+}
@@ -0,0 +1,140 @@
+// Package core provides Ralph's own tools, which let the model signal the loop directly.
+
+package core
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/JanDeDobbeleer/copilot-ralph/internal/sdk"
+)
+
+// Names of the tools Ralph exposes to the model.
+const (
+	ToolSignalComplete = "ralph_signal_complete"
+	ToolReportBlocked  = "ralph_report_blocked"
+	ToolNote           = "ralph_note"
+)
+
+// blockedNote reminds the model of the blocker it reported in an earlier iteration.
+const blockedNote = "In iteration %d you reported being blocked: %s. " +
+	"Check whether the blocker is resolved before continuing, or work around it."
+
+// toolSource is the PromiseDetectedEvent source of a completion signaled with a tool.
+const toolSource = "tool"
+
+// registerTools exposes Ralph's own tools to the model when the SDK client supports it.
+// Without them the model completes the loop with the promise phrase only.
+func (e *LoopEngine) registerTools() {
+	registry, ok := e.sdk.(ToolRegistry)
+	if !ok {
+		return
+	}
+
+	registry.RegisterTools(e.tools()...)
+}
+
+// tools returns Ralph's own tools, bound to the engine.
+func (e *LoopEngine) tools() []sdk.Tool {
+	return []sdk.Tool{
+		{
+			Name: ToolSignalComplete,
+			Description: "Signal that the task is complete. Call this once all work is done and verified, " +
+				"instead of or in addition to writing the completion promise.",
+			Parameters: sdk.StringParameters(map[string]string{"summary": "What was done to complete the task"}),
+			Handler:    e.signalComplete,
+		},
+		{
+			Name: ToolReportBlocked,
+			Description: "Report that you cannot make progress without outside help, " +
+				"e.g. missing credentials or an unclear requirement.",
+			Parameters: sdk.StringParameters(map[string]string{"reason": "What blocks progress and what would unblock it"}),
+			Handler:    e.reportBlocked,
+		},
+		{
+			Name:        ToolNote,
+			Description: "Leave a note for the user running the loop, e.g. a decision or something to review later.",
+			Parameters:  sdk.StringParameters(map[string]string{"text": "The note"}),
+			Handler:     e.note,
+		},
+	}
+}
+
+// signalComplete handles ralph_signal_complete. The completion counts as the promise
+// of the current iteration once the iteration ends.
+func (e *LoopEngine) signalComplete(arguments map[string]any) (string, error) {
+	summary, err := stringArgument(arguments, "summary")
+	if err != nil {
+		return "", err
+	}
+
+	e.mu.Lock()
+	e.signaled = true
+	iteration := e.iteration
+	e.mu.Unlock()
+
+	e.emit(NewCompletionSignaledEvent(summary, iteration))
+
+	return "Completion recorded. Ralph checks it when this iteration ends.", nil
+}
+
+// reportBlocked handles ralph_report_blocked. The blocker is carried into the next prompt.
+func (e *LoopEngine) reportBlocked(arguments map[string]any) (string, error) {
+	reason, err := stringArgument(arguments, "reason")
+	if err != nil {
+		return "", err
+	}
+
+	e.mu.RLock()
+	iteration := e.iteration
+	e.mu.RUnlock()
+
+	e.emit(NewBlockedEvent(reason, iteration))
+	e.carry(fmt.Sprintf(blockedNote, iteration, reason))
+
+	return "Blocker reported to the user.", nil
+}
+
+// note handles ralph_note.
+func (e *LoopEngine) note(arguments map[string]any) (string, error) {
+	text, err := stringArgument(arguments, "text")
+	if err != nil {
+		return "", err
+	}
+
+	e.mu.RLock()
+	iteration := e.iteration
+	e.mu.RUnlock()
+
+	e.emit(NewNoteEvent(text, iteration))
+
+	return "Note recorded.", nil
+}
+
+// checkSignal treats a completion signaled with ralph_signal_complete during the
+// iteration like a detected promise.
+func (e *LoopEngine) checkSignal(iteration int, outcome *iterationResult) {
+	e.mu.Lock()
+	signaled := e.signaled
+	e.signaled = false
+	e.mu.Unlock()
+
+	// Checklist runs complete on checked items, not on the promise
+	if !signaled || e.config.Checklist != "" || outcome.promiseDetected {
+		return
+	}
+
+	outcome.promiseDetected = true
+	e.emit(NewPromiseDetectedEvent(e.promisePhrase(), toolSource, iteration))
+}
+
+// stringArgument returns a required, non-empty string argument of a tool.
+func stringArgument(arguments map[string]any, name string) (string, error) {
+	value, _ := arguments[name].(string)
+	value = strings.TrimSpace(value)
+	if value == "" {
+		return "", fmt.Errorf("%s is required", name)
+	}
+
+	return value, nil
+}
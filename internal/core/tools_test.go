@@ -0,0 +1,86 @@
+package core
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestLoopEngine_RalphTools(t *testing.T) {
+	mockSDK := NewMockSDKClient()
+
+	var results []string
+	mockSDK.OnPrompt = func(string) {
+		call := func(name string, arguments map[string]any) {
+			result, err := mockSDK.Tool(name).Handler(arguments)
+			require.NoError(t, err)
+			results = append(results, result)
+		}
+
+		if len(mockSDK.Prompts) == 1 {
+			call(ToolNote, map[string]any{"text": "Kept the old parser for now"})
+			call(ToolReportBlocked, map[string]any{"reason": "the fixtures are missing"})
+			return
+		}
+
+		call(ToolSignalComplete, map[string]any{"summary": "Parser rewritten and tested"})
+	}
+
+	config := &LoopConfig{Prompt: "Test task", MaxIterations: 5, PromisePhrase: "done"}
+	engine := NewLoopEngine(config, mockSDK)
+
+	var kinds []EventKind
+	var promise *PromiseDetectedEvent
+	var signaled *CompletionSignaledEvent
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		for event := range engine.Events() {
+			switch ev := event.(type) {
+			case *NoteEvent, *BlockedEvent:
+				kinds = append(kinds, ev.Kind())
+			case *CompletionSignaledEvent:
+				signaled = ev
+			case *PromiseDetectedEvent:
+				promise = ev
+			}
+		}
+	}()
+
+	result, err := engine.Start(context.Background())
+	<-done
+	require.NoError(t, err)
+
+	assert.Equal(t, StateComplete, result.State)
+	assert.Equal(t, 2, result.Iterations)
+	assert.Equal(t, []EventKind{EventNote, EventBlocked}, kinds)
+	assert.Len(t, results, 3)
+
+	require.NotNil(t, signaled)
+	assert.Equal(t, "Parser rewritten and tested", signaled.Summary)
+	assert.Equal(t, 2, signaled.Iteration)
+
+	require.NotNil(t, promise)
+	assert.Equal(t, "tool", promise.Source)
+	assert.Equal(t, 2, promise.Iteration)
+
+	require.Len(t, mockSDK.Prompts, 2)
+	assert.Contains(t, mockSDK.Prompts[1], "In iteration 1 you reported being blocked: the fixtures are missing")
+}
+
+func TestLoopEngine_RalphToolArguments(t *testing.T) {
+	engine := NewLoopEngine(nil, nil)
+
+	for _, tool := range engine.tools() {
+		t.Run(tool.Name, func(t *testing.T) {
+			required, ok := tool.Parameters["required"].([]string)
+			require.True(t, ok)
+			require.Len(t, required, 1)
+
+			_, err := tool.Handler(map[string]any{required[0]: "  "})
+			assert.ErrorContains(t, err, required[0]+" is required")
+		})
+	}
+}
@@ -0,0 +1,118 @@
+// Package core provides token usage accounting and budgets for the loop engine.
+
+package core
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/JanDeDobbeleer/copilot-ralph/internal/sdk"
+)
+
+// ErrBudgetExceeded indicates the loop stopped because the token or cost budget ran out.
+var ErrBudgetExceeded = errors.New("budget exceeded")
+
+// tokensPerPriceUnit is the number of tokens a ModelPrice refers to.
+const tokensPerPriceUnit = 1_000_000
+
+// Usage holds token counts and cost.
+type Usage struct {
+	InputTokens      int64   `json:"input_tokens"`
+	OutputTokens     int64   `json:"output_tokens"`
+	CacheReadTokens  int64   `json:"cache_read_tokens,omitempty"`
+	CacheWriteTokens int64   `json:"cache_write_tokens,omitempty"`
+	Cost             float64 `json:"cost,omitempty"`
+}
+
+// Tokens returns the number of input and output tokens.
+func (u Usage) Tokens() int64 {
+	return u.InputTokens + u.OutputTokens
+}
+
+// Add returns the sum of both usages.
+func (u Usage) Add(other Usage) Usage {
+	return Usage{
+		InputTokens:      u.InputTokens + other.InputTokens,
+		OutputTokens:     u.OutputTokens + other.OutputTokens,
+		CacheReadTokens:  u.CacheReadTokens + other.CacheReadTokens,
+		CacheWriteTokens: u.CacheWriteTokens + other.CacheWriteTokens,
+		Cost:             u.Cost + other.Cost,
+	}
+}
+
+// ModelPrice is the price of a model per million tokens.
+type ModelPrice struct {
+	Input      float64 `json:"input"`
+	Output     float64 `json:"output"`
+	CacheRead  float64 `json:"cache_read,omitempty"`
+	CacheWrite float64 `json:"cache_write,omitempty"`
+}
+
+// cost returns the price of the given usage.
+func (p ModelPrice) cost(u Usage) float64 {
+	total := float64(u.InputTokens)*p.Input +
+		float64(u.OutputTokens)*p.Output +
+		float64(u.CacheReadTokens)*p.CacheRead +
+		float64(u.CacheWriteTokens)*p.CacheWrite
+
+	return total / tokensPerPriceUnit
+}
+
+// usageFromEvent converts an SDK usage event. The cost comes from the price table
+// when it has an entry for the model, otherwise the cost reported by the SDK is used.
+func usageFromEvent(ev *sdk.UsageEvent, model string, prices map[string]ModelPrice) Usage {
+	usage := Usage{
+		InputTokens:      ev.InputTokens,
+		OutputTokens:     ev.OutputTokens,
+		CacheReadTokens:  ev.CacheReadTokens,
+		CacheWriteTokens: ev.CacheWriteTokens,
+		Cost:             ev.Cost,
+	}
+
+	if ev.Model != "" {
+		model = ev.Model
+	}
+
+	if price, ok := prices[model]; ok {
+		usage.Cost = price.cost(usage)
+	}
+
+	return usage
+}
+
+// recordUsage adds the usage of a model call made by client to the iteration and run
+// totals and returns an error wrapping ErrBudgetExceeded when a budget ran out.
+func (e *LoopEngine) recordUsage(client SDKClient, ev *sdk.UsageEvent, iteration int) error {
+	model := ""
+	if client != nil {
+		model = client.Model()
+	}
+
+	usage := usageFromEvent(ev, model, e.config.Prices)
+
+	e.mu.Lock()
+	e.usage = e.usage.Add(usage)
+	if len(e.iterationUsage) < iteration {
+		e.iterationUsage = append(e.iterationUsage, make([]Usage, iteration-len(e.iterationUsage))...)
+	}
+	e.iterationUsage[iteration-1] = e.iterationUsage[iteration-1].Add(usage)
+	total := e.usage
+	e.mu.Unlock()
+
+	e.emit(NewUsageEvent(usage, total, iteration))
+
+	return e.checkBudget(total)
+}
+
+// checkBudget returns an error wrapping ErrBudgetExceeded when the usage exceeds a budget.
+func (e *LoopEngine) checkBudget(total Usage) error {
+	if e.config.MaxTokens > 0 && total.Tokens() > e.config.MaxTokens {
+		return fmt.Errorf("%w: used %d tokens (limit %d)", ErrBudgetExceeded, total.Tokens(), e.config.MaxTokens)
+	}
+
+	if e.config.MaxCost > 0 && total.Cost > e.config.MaxCost {
+		return fmt.Errorf("%w: cost $%.2f (limit $%.2f)", ErrBudgetExceeded, total.Cost, e.config.MaxCost)
+	}
+
+	return nil
+}
@@ -0,0 +1,125 @@
+package core
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/JanDeDobbeleer/copilot-ralph/internal/sdk"
+)
+
+func TestUsageFromEvent(t *testing.T) {
+	prices := map[string]ModelPrice{
+		"gpt-4": {Input: 2, Output: 8, CacheRead: 0.5},
+	}
+
+	tests := []struct {
+		name     string
+		event    *sdk.UsageEvent
+		model    string
+		wantCost float64
+	}{
+		{
+			name:     "priced model from event",
+			event:    sdk.NewUsageEvent("gpt-4", 1_000_000, 500_000, 2_000_000, 0, 99),
+			wantCost: 2 + 4 + 1,
+		},
+		{
+			name:     "priced session model",
+			event:    sdk.NewUsageEvent("", 500_000, 0, 0, 0, 0),
+			model:    "gpt-4",
+			wantCost: 1,
+		},
+		{
+			name:     "unpriced model uses reported cost",
+			event:    sdk.NewUsageEvent("claude", 1000, 1000, 0, 0, 0.25),
+			wantCost: 0.25,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			usage := usageFromEvent(tt.event, tt.model, prices)
+			assert.Equal(t, tt.event.InputTokens, usage.InputTokens)
+			assert.Equal(t, tt.event.OutputTokens, usage.OutputTokens)
+			assert.InDelta(t, tt.wantCost, usage.Cost, 1e-9)
+		})
+	}
+}
+
+func TestUsageAdd(t *testing.T) {
+	total := Usage{InputTokens: 10, OutputTokens: 5, Cost: 0.5}.Add(Usage{InputTokens: 1, OutputTokens: 2, CacheReadTokens: 3, Cost: 0.25})
+
+	assert.Equal(t, Usage{InputTokens: 11, OutputTokens: 7, CacheReadTokens: 3, Cost: 0.75}, total)
+	assert.Equal(t, int64(18), total.Tokens())
+}
+
+func TestLoopEngine_TracksUsage(t *testing.T) {
+	mockSDK := NewMockSDKClient()
+	mockSDK.Usage = sdk.NewUsageEvent("", 100, 50, 0, 0, 0.01)
+
+	config := &LoopConfig{
+		Prompt:        "Test task",
+		MaxIterations: 3,
+		PromisePhrase: "done",
+	}
+	engine := NewLoopEngine(config, mockSDK)
+	drainEvents(engine)
+
+	result, err := engine.Start(context.Background())
+	require.NoError(t, err)
+
+	assert.Equal(t, int64(300), result.Usage.InputTokens)
+	assert.Equal(t, int64(150), result.Usage.OutputTokens)
+	assert.InDelta(t, 0.03, result.Usage.Cost, 1e-9)
+	require.Len(t, result.IterationUsage, 3)
+	assert.Equal(t, int64(150), result.IterationUsage[1].Tokens())
+}
+
+func TestLoopEngine_BudgetExceeded(t *testing.T) {
+	tests := []struct {
+		name      string
+		maxTokens int64
+		maxCost   float64
+		prices    map[string]ModelPrice
+		want      string
+	}{
+		{
+			name:      "token budget",
+			maxTokens: 400,
+			want:      "used 450 tokens (limit 400)",
+		},
+		{
+			name:    "cost budget from price table",
+			maxCost: 0.5,
+			prices:  map[string]ModelPrice{"mock-model": {Input: 1000, Output: 2000}},
+			want:    "cost $0.60 (limit $0.50)",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockSDK := NewMockSDKClient()
+			mockSDK.Usage = sdk.NewUsageEvent("", 100, 50, 0, 0, 0)
+
+			config := &LoopConfig{
+				Prompt:        "Test task",
+				MaxIterations: 10,
+				PromisePhrase: "done",
+				MaxTokens:     tt.maxTokens,
+				MaxCost:       tt.maxCost,
+				Prices:        tt.prices,
+			}
+			engine := NewLoopEngine(config, mockSDK)
+			drainEvents(engine)
+
+			result, err := engine.Start(context.Background())
+			require.ErrorIs(t, err, ErrBudgetExceeded)
+			assert.Contains(t, err.Error(), tt.want)
+			assert.Equal(t, StateFailed, result.State)
+			assert.Equal(t, 3, result.Iterations)
+		})
+	}
+}
@@ -0,0 +1,44 @@
+// Package core provides promise verification for the loop engine.
+
+package core
+
+import (
+	"fmt"
+	"strings"
+)
+
+// verifyPromise runs the configured verification command after a promise was detected.
+// It returns true when the promise may be accepted. When the command fails, a
+// VerificationFailedEvent is emitted and the rejection is carried into the next prompt.
+func (e *LoopEngine) verifyPromise(iteration int) (bool, error) {
+	if e.config.VerifyCommand == "" {
+		return true, nil
+	}
+
+	result, err := runShellCommand(e.ctx, e.config.WorkingDir, e.config.VerifyCommand)
+	if err != nil {
+		return false, fmt.Errorf("verification failed to run: %w", err)
+	}
+
+	if result.Passed() {
+		return true, nil
+	}
+
+	e.emit(NewVerificationFailedEvent(e.config.VerifyCommand, result.Tail(), result.ExitCode, iteration))
+	e.carry(verificationFeedback(e.config.VerifyCommand, result))
+
+	return false, nil
+}
+
+// verificationFeedback builds the note telling the model why its promise was rejected.
+func verificationFeedback(command string, result *commandResult) string {
+	var builder strings.Builder
+
+	builder.WriteString(fmt.Sprintf("Your promise was rejected because the verification command `%s` failed with exit code %d.\n", command, result.ExitCode))
+	builder.WriteString("Fix the problems below before outputting the completion phrase again.\n\n")
+	builder.WriteString("```text\n")
+	builder.WriteString(strings.TrimSpace(result.Tail()))
+	builder.WriteString("\n```")
+
+	return builder.String()
+}
@@ -0,0 +1,104 @@
+package core
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRunShellCommand(t *testing.T) {
+	tests := []struct {
+		name       string
+		command    string
+		wantOutput string
+		wantExit   int
+	}{
+		{
+			name:       "success",
+			command:    "echo ok",
+			wantOutput: "ok",
+		},
+		{
+			name:       "failure keeps output",
+			command:    "echo broken && exit 3",
+			wantOutput: "broken",
+			wantExit:   3,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			result, err := runShellCommand(context.Background(), t.TempDir(), tt.command)
+
+			require.NoError(t, err)
+			assert.Contains(t, result.Output, tt.wantOutput)
+			assert.Equal(t, tt.wantExit, result.ExitCode)
+			assert.Equal(t, tt.wantExit == 0, result.Passed())
+		})
+	}
+}
+
+func TestRunShellCommandKeepsFullOutput(t *testing.T) {
+	result, err := runShellCommand(context.Background(), t.TempDir(), "echo head; head -c 20000 /dev/zero | tr '\\0' a; echo; echo tail")
+	require.NoError(t, err)
+
+	assert.True(t, strings.HasPrefix(result.Output, "head\n"))
+	assert.NotContains(t, result.Tail(), "head")
+	assert.True(t, strings.HasSuffix(result.Tail(), "tail\n"))
+	assert.Contains(t, result.Tail(), "output truncated")
+}
+
+func TestTruncateOutput(t *testing.T) {
+	assert.Equal(t, "short", truncateOutput("short", 10))
+
+	truncated := truncateOutput(strings.Repeat("a", 10)+"tail", 4)
+	assert.True(t, strings.HasSuffix(truncated, "tail"))
+	assert.Contains(t, truncated, "output truncated")
+}
+
+func TestLoopEngine_VerificationGate(t *testing.T) {
+	mockSDK := NewMockSDKClient()
+	mockSDK.SimulatePromise = true
+	mockSDK.PromisePhrase = "done"
+
+	// Fails the first time, passes afterwards
+	config := &LoopConfig{
+		Prompt:        "Test task",
+		MaxIterations: 5,
+		PromisePhrase: "done",
+		WorkingDir:    t.TempDir(),
+		VerifyCommand: "test -f verified || { touch verified; echo 'FAIL: TestParser'; exit 1; }",
+	}
+	engine := NewLoopEngine(config, mockSDK)
+
+	var rejected *VerificationFailedEvent
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		for event := range engine.Events() {
+			if ev, ok := event.(*VerificationFailedEvent); ok {
+				rejected = ev
+			}
+		}
+	}()
+
+	result, err := engine.Start(context.Background())
+	<-done
+
+	require.NoError(t, err)
+	assert.Equal(t, 2, result.Iterations)
+	assert.Equal(t, 2, result.PromiseIteration)
+
+	require.NotNil(t, rejected)
+	assert.Equal(t, 1, rejected.Iteration)
+	assert.Equal(t, 1, rejected.ExitCode)
+	assert.Contains(t, rejected.Output, "FAIL: TestParser")
+
+	require.Len(t, mockSDK.Prompts, 2)
+	assert.NotContains(t, mockSDK.Prompts[0], "rejected")
+	assert.Contains(t, mockSDK.Prompts[1], "Your promise was rejected")
+	assert.Contains(t, mockSDK.Prompts[1], "FAIL: TestParser")
+}
@@ -10,6 +10,7 @@ package sdk
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"strings"
 	"sync"
@@ -33,6 +34,13 @@ var retryBackoffs = []time.Duration{
 	5 * time.Second,
 }
 
+// abortWait bounds how long an aborted prompt waits for the session to finish its turn.
+const abortWait = 10 * time.Second
+
+// ErrAbortIncomplete indicates a cancelled prompt was aborted but the session did not
+// finish its turn, so its late events could end the next prompt sent to the session.
+var ErrAbortIncomplete = errors.New("aborted prompt did not finish")
+
 // isRetryableError determines if an error is transient and can be retried.
 func isRetryableError(err error) bool {
 	if err == nil {
@@ -72,6 +80,13 @@ type CopilotClient struct {
 	systemMessageMode string
 	systemMessage     string
 	timeout           time.Duration
+	permissionHandler PermissionHandler
+	toolPolicy        *ToolPolicy
+	tools             []Tool
+	mcpServers        map[string]MCPServer
+	promptEvents      chan<- Event
+	promptMu          sync.Mutex
+	sessionMu         sync.Mutex
 	streaming         bool
 	started           bool
 }
@@ -84,6 +99,9 @@ type clientConfig struct {
 	systemMessageMode string
 	systemMessage     string
 	timeout           time.Duration
+	toolPolicy        *ToolPolicy
+	tools             []Tool
+	mcpServers        map[string]MCPServer
 	streaming         bool
 }
 
@@ -134,6 +152,14 @@ func WithTimeout(timeout time.Duration) ClientOption {
 	}
 }
 
+// WithToolPolicy makes the client check every tool request against policy.
+// Denied requests are reported as ToolDeniedEvent on the event stream of the running prompt.
+func WithToolPolicy(policy *ToolPolicy) ClientOption {
+	return func(c *clientConfig) {
+		c.toolPolicy = policy
+	}
+}
+
 // NewCopilotClient creates a new Copilot SDK client with the given options.
 // It returns an error if the configuration is invalid.
 func NewCopilotClient(opts ...ClientOption) (*CopilotClient, error) {
@@ -169,6 +195,9 @@ func NewCopilotClient(opts ...ClientOption) (*CopilotClient, error) {
 		systemMessageMode: config.systemMessageMode,
 		systemMessage:     config.systemMessage,
 		timeout:           config.timeout,
+		toolPolicy:        config.toolPolicy,
+		tools:             config.tools,
+		mcpServers:        config.mcpServers,
 		started:           false,
 	}, nil
 }
@@ -201,9 +230,8 @@ func (c *CopilotClient) Stop() error {
 	}
 
 	// Destroy any active SDK session
-	if c.sdkSession != nil {
-		_ = c.sdkSession.Destroy()
-		c.sdkSession = nil
+	if session := c.swapSession(nil); session != nil {
+		_ = session.Destroy()
 	}
 
 	// Stop the SDK client
@@ -237,6 +265,25 @@ func (c *CopilotClient) CreateSession(ctx context.Context) error {
 		}
 	}
 
+	// Expose Ralph's own tools to the model
+	if len(c.tools) > 0 {
+		sessionConfig.Tools = c.sessionTools()
+	}
+
+	// Attach the configured MCP servers
+	if len(c.mcpServers) > 0 {
+		servers, err := c.sessionMCPServers()
+		if err != nil {
+			return err
+		}
+		sessionConfig.MCPServers = servers
+	}
+
+	// Check tools against the policy and ask before running them when a permission handler is set
+	if c.permissionHandler != nil || c.toolPolicy != nil {
+		sessionConfig.OnPermissionRequest = c.handlePermission
+	}
+
 	// Create SDK session
 	sdkSession, err := c.sdkClient.CreateSession(sessionConfig)
 	if err != nil {
@@ -244,32 +291,75 @@ func (c *CopilotClient) CreateSession(ctx context.Context) error {
 	}
 
 	// Store SDK session reference; we no longer maintain a local Session wrapper
-	c.sdkSession = sdkSession
+	c.swapSession(sdkSession)
 	return nil
 }
 
 // DestroySession destroys the current session and cleans up resources.
 func (c *CopilotClient) DestroySession(ctx context.Context) error {
-	if c.sdkSession == nil {
+	session := c.swapSession(nil)
+	if session == nil {
 		return nil
 	}
 
-	_ = c.sdkSession.Destroy()
-	c.sdkSession = nil
+	_ = session.Destroy()
 	return nil
 }
 
+// session returns the current SDK session, nil when there is none.
+// Steering sends follow-ups from another goroutine, so the session is guarded by sessionMu.
+func (c *CopilotClient) session() *copilot.Session {
+	c.sessionMu.Lock()
+	defer c.sessionMu.Unlock()
+	return c.sdkSession
+}
+
+// swapSession replaces the current SDK session and returns the previous one.
+func (c *CopilotClient) swapSession(session *copilot.Session) *copilot.Session {
+	c.sessionMu.Lock()
+	defer c.sessionMu.Unlock()
+	previous := c.sdkSession
+	c.sdkSession = session
+	return previous
+}
+
 // Model returns the configured model name.
 func (c *CopilotClient) Model() string {
 	return c.model
 }
 
+// SetModel changes the model used for sessions created afterwards.
+// The current session keeps its model until it is destroyed.
+func (c *CopilotClient) SetModel(model string) error {
+	if model == "" {
+		return fmt.Errorf("model cannot be empty")
+	}
+
+	c.model = model
+	return nil
+}
+
+// SendFollowUp queues a message in the current session while a prompt is being processed.
+// The response to it is streamed on the event channel of that prompt.
+func (c *CopilotClient) SendFollowUp(message string) error {
+	session := c.session()
+	if session == nil {
+		return fmt.Errorf("no active session")
+	}
+
+	if _, err := session.Send(copilot.MessageOptions{Prompt: message, Mode: "enqueue"}); err != nil {
+		return fmt.Errorf("failed to send follow-up: %w", err)
+	}
+
+	return nil
+}
+
 // SendPrompt sends a prompt to the Copilot SDK and returns an event stream.
 // The returned channel will be closed when the response is complete.
 // An error is returned if there is no active session.
 // This method includes automatic retry logic for transient errors.
 func (c *CopilotClient) SendPrompt(ctx context.Context, prompt string) (<-chan Event, error) {
-	if c.sdkSession == nil {
+	if c.session() == nil {
 		return nil, fmt.Errorf("no active session")
 	}
 
@@ -285,6 +375,14 @@ func (c *CopilotClient) SendPrompt(ctx context.Context, prompt string) (<-chan E
 	return events, nil
 }
 
+// floatValue dereferences an optional SDK number, returning 0 when it is missing.
+func floatValue(value *float64) float64 {
+	if value == nil {
+		return 0
+	}
+	return *value
+}
+
 // safeEventSender safely sends an event to a channel, recovering from panics if the channel is closed.
 // Returns an error if the send failed (e.g., channel closed).
 func safeEventSender(events chan<- Event, event Event) (err error) {
@@ -348,6 +446,11 @@ func (c *CopilotClient) sendPromptWithRetry(ctx context.Context, prompt string,
 
 // sendPromptOnce sends the prompt once without retrying.
 func (c *CopilotClient) sendPromptOnce(ctx context.Context, prompt string, events chan<- Event) error {
+	session := c.session()
+	if session == nil {
+		return fmt.Errorf("no active session")
+	}
+
 	// Set up done channel to wait for session.idle
 	done := make(chan struct{})
 	doneOnce := &sync.Once{}
@@ -360,19 +463,22 @@ func (c *CopilotClient) sendPromptOnce(ctx context.Context, prompt string, event
 	var sessionErr error
 	pendingToolCalls := make(map[string]ToolCall)
 
+	// Tool policy denials are reported on the event stream of this prompt
+	c.setPromptEvents(events)
+	defer c.setPromptEvents(nil)
+
 	// Subscribe to SDK session events
-	unsubscribe := c.sdkSession.On(func(event copilot.SessionEvent) {
-		// Check if context is cancelled before processing events
-		select {
-		case <-ctx.Done():
-			// Context cancelled, close done channel to unblock and stop processing
-			closeDone()
+	unsubscribe := session.On(func(event copilot.SessionEvent) {
+		// Once cancelled, events are no longer forwarded, only the end of the aborted turn matters
+		if ctx.Err() != nil {
+			if event.Type == "session.idle" {
+				closeDone()
+			}
 			return
-		default:
 		}
 
 		if event.Type == "session.error" && event.Data.Message != nil {
-			sessionErr = fmt.Errorf("SDK error: %s", *event.Data.Message)
+			sessionErr = newSessionError(event)
 		}
 
 		c.handleSDKEvent(event, events, closeDone, pendingToolCalls)
@@ -381,7 +487,7 @@ func (c *CopilotClient) sendPromptOnce(ctx context.Context, prompt string, event
 	defer unsubscribe()
 
 	// Send the message
-	_, err := c.sdkSession.Send(copilot.MessageOptions{
+	_, err := session.Send(copilot.MessageOptions{
 		Prompt: prompt,
 	})
 	if err != nil {
@@ -391,12 +497,17 @@ func (c *CopilotClient) sendPromptOnce(ctx context.Context, prompt string, event
 	// Wait for session to become idle or context cancellation
 	select {
 	case <-ctx.Done():
-		// Abort the session and close done to unblock any waiting
-		go func() {
-			_ = c.sdkSession.Abort()
-		}()
+		// The next prompt may go to the same session, so wait for the aborted turn to end
+		if err := session.Abort(); err != nil {
+			return fmt.Errorf("%w: %w", ErrAbortIncomplete, err)
+		}
+
+		select {
+		case <-done:
+		case <-time.After(abortWait):
+			return ErrAbortIncomplete
+		}
 
-		closeDone()
 		return ctx.Err()
 	case <-done:
 		// Response complete - check for session error
@@ -419,13 +530,54 @@ func (c *CopilotClient) handleSDKEvent(sdkEvent copilot.SessionEvent, events cha
 
 		_ = safeEventSender(events, NewTextEvent(*sdkEvent.Data.DeltaContent, strings.Contains(string(sdkEvent.Type), "reasoning")))
 
-	case "assistant.message", "assistant.reasoning":
-		// Complete assistant message
+	case "assistant.reasoning":
+		// Complete reasoning message
+		if sdkEvent.Data.Content == nil {
+			return
+		}
+
+		_ = safeEventSender(events, NewTextEvent(*sdkEvent.Data.Content, true))
+
+	case "assistant.message":
+		// Complete assistant message, streamed deltas (if any) were already forwarded
 		if sdkEvent.Data.Content == nil {
 			return
 		}
 
-		_ = safeEventSender(events, NewTextEvent(*sdkEvent.Data.Content, strings.Contains(string(sdkEvent.Type), "reasoning")))
+		message := Message{
+			Content:   *sdkEvent.Data.Content,
+			Timestamp: sdkEvent.Timestamp,
+		}
+
+		for _, request := range sdkEvent.Data.ToolRequests {
+			toolCall := ToolCall{
+				ID:   request.ToolCallID,
+				Name: request.Name,
+			}
+
+			if args, ok := request.Arguments.(map[string]any); ok {
+				toolCall.Parameters = args
+			}
+
+			message.ToolCalls = append(message.ToolCalls, toolCall)
+		}
+
+		_ = safeEventSender(events, NewResponseCompleteEvent(message))
+
+	case "assistant.usage":
+		var model string
+		if sdkEvent.Data.Model != nil {
+			model = *sdkEvent.Data.Model
+		}
+
+		_ = safeEventSender(events, NewUsageEvent(
+			model,
+			int64(floatValue(sdkEvent.Data.InputTokens)),
+			int64(floatValue(sdkEvent.Data.OutputTokens)),
+			int64(floatValue(sdkEvent.Data.CacheReadTokens)),
+			int64(floatValue(sdkEvent.Data.CacheWriteTokens)),
+			floatValue(sdkEvent.Data.Cost),
+		))
 
 	case "tool.execution_start":
 		// Tool execution started - the SDK handles this internally
@@ -495,6 +647,19 @@ func (c *CopilotClient) handleSDKEvent(sdkEvent copilot.SessionEvent, events cha
 			return
 		}
 
-		_ = safeEventSender(events, NewErrorEvent(fmt.Errorf("SDK error: %s", *sdkEvent.Data.Message)))
+		_ = safeEventSender(events, NewErrorEvent(newSessionError(sdkEvent)))
+	}
+}
+
+// newSessionError converts a session.error event of the Copilot SDK.
+func newSessionError(event copilot.SessionEvent) *SessionError {
+	sessionErr := &SessionError{}
+	if event.Data.Message != nil {
+		sessionErr.Message = *event.Data.Message
 	}
+	if event.Data.ErrorType != nil {
+		sessionErr.Type = *event.Data.ErrorType
+	}
+
+	return sessionErr
 }
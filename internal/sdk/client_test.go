@@ -109,6 +109,24 @@ func TestNewCopilotClient(t *testing.T) {
 	}
 }
 
+func TestCopilotClientSetModel(t *testing.T) {
+	client, err := NewCopilotClient(WithModel("gpt-4"))
+	require.NoError(t, err)
+
+	require.NoError(t, client.SetModel("claude-sonnet-4"))
+	assert.Equal(t, "claude-sonnet-4", client.Model())
+
+	assert.Error(t, client.SetModel(""))
+	assert.Equal(t, "claude-sonnet-4", client.Model())
+}
+
+func TestCopilotClientSendFollowUpWithoutSession(t *testing.T) {
+	client, err := NewCopilotClient()
+	require.NoError(t, err)
+
+	assert.ErrorContains(t, client.SendFollowUp("focus on the parser"), "no active session")
+}
+
 func TestCopilotClientStartStop(t *testing.T) {
 
 	t.Run("start and stop", func(t *testing.T) {
@@ -531,12 +549,15 @@ loop:
 		}
 	}
 
-	// Expect at least one TextEvent and at least one ToolResultEvent and one ErrorEvent
-	var hasText, hasToolResult, hasError bool
+	// Expect at least one TextEvent, a ResponseCompleteEvent, at least one ToolResultEvent and one ErrorEvent
+	var hasText, hasResponseComplete, hasToolResult, hasError bool
 	for _, e := range received {
 		switch e.Type() {
 		case EventTypeText:
 			hasText = true
+		case EventTypeResponseComplete:
+			hasResponseComplete = true
+			assert.Equal(t, "full", e.(*ResponseCompleteEvent).Message.Content)
 		case EventTypeToolResult:
 			hasToolResult = true
 		case EventTypeError:
@@ -545,6 +566,7 @@ loop:
 	}
 
 	assert.True(t, hasText, "should have text events")
+	assert.True(t, hasResponseComplete, "should have a response complete event")
 	assert.True(t, hasToolResult, "should have tool result events")
 	assert.True(t, hasError, "should have error events")
 	assert.True(t, closed, "closeDone should be called on session.idle")
@@ -552,6 +574,48 @@ loop:
 
 func ptrBool(b bool) *bool { return &b }
 
+func TestHandleSDKEventSessionError(t *testing.T) {
+	c := &CopilotClient{}
+	events := make(chan Event, 1)
+	message, errorType := "Too Many Requests", ErrorTypeRateLimit
+
+	c.handleSDKEvent(copilot.SessionEvent{Type: "session.error", Data: copilot.Data{Message: &message, ErrorType: &errorType}}, events, func() {}, nil)
+
+	event, ok := (<-events).(*ErrorEvent)
+	require.True(t, ok)
+
+	var sessionErr *SessionError
+	require.ErrorAs(t, event.Err, &sessionErr)
+	assert.Equal(t, ErrorTypeRateLimit, sessionErr.Type)
+	assert.Equal(t, "SDK error: Too Many Requests", sessionErr.Error())
+	assert.True(t, sessionErr.RateLimited())
+	assert.False(t, (&SessionError{Message: "429 files changed"}).RateLimited())
+}
+
+func TestHandleSDKEventUsage(t *testing.T) {
+	c := &CopilotClient{}
+	events := make(chan Event, 1)
+	input, output, cacheRead, cost := 1200.0, 300.0, 800.0, 0.25
+
+	c.handleSDKEvent(copilot.SessionEvent{Type: "assistant.usage", Data: copilot.Data{
+		Model:           ptrString("gpt-4"),
+		InputTokens:     &input,
+		OutputTokens:    &output,
+		CacheReadTokens: &cacheRead,
+		Cost:            &cost,
+	}}, events, func() {}, map[string]ToolCall{})
+
+	require.Len(t, events, 1)
+	usage, ok := (<-events).(*UsageEvent)
+	require.True(t, ok)
+	assert.Equal(t, "gpt-4", usage.Model)
+	assert.Equal(t, int64(1200), usage.InputTokens)
+	assert.Equal(t, int64(300), usage.OutputTokens)
+	assert.Equal(t, int64(800), usage.CacheReadTokens)
+	assert.Equal(t, int64(0), usage.CacheWriteTokens)
+	assert.InDelta(t, 0.25, usage.Cost, 0.0001)
+}
+
 func TestSendPromptOnceWithFakeSession(t *testing.T) {
 	c, err := NewCopilotClient()
 	require.NoError(t, err)
@@ -18,6 +18,10 @@ const (
 	EventTypeToolResult EventType = "tool_result"
 	// EventTypeResponseComplete indicates the response is complete.
 	EventTypeResponseComplete EventType = "response_complete"
+	// EventTypeUsage indicates token usage was reported for a model call.
+	EventTypeUsage EventType = "usage"
+	// EventTypeToolDenied indicates the tool policy refused a tool request.
+	EventTypeToolDenied EventType = "tool_denied"
 	// EventTypeError indicates an error occurred.
 	EventTypeError EventType = "error"
 )
@@ -110,6 +114,99 @@ func NewToolResultEvent(toolCall ToolCall, result string, err error) *ToolResult
 	}
 }
 
+// ResponseCompleteEvent represents a complete assistant message.
+type ResponseCompleteEvent struct {
+	// Message contains the complete assistant message.
+	Message   Message
+	timestamp time.Time
+}
+
+// Type returns EventTypeResponseComplete.
+func (e *ResponseCompleteEvent) Type() EventType {
+	return EventTypeResponseComplete
+}
+
+// Timestamp returns when the event occurred.
+func (e *ResponseCompleteEvent) Timestamp() time.Time {
+	return e.timestamp
+}
+
+// NewResponseCompleteEvent creates a new ResponseCompleteEvent with the given message.
+func NewResponseCompleteEvent(message Message) *ResponseCompleteEvent {
+	return &ResponseCompleteEvent{
+		Message:   message,
+		timestamp: time.Now(),
+	}
+}
+
+// UsageEvent reports the tokens and cost of a single model call.
+type UsageEvent struct {
+	timestamp time.Time
+	// Model is the model that served the call.
+	Model string
+	// InputTokens is the number of prompt tokens.
+	InputTokens int64
+	// OutputTokens is the number of generated tokens.
+	OutputTokens int64
+	// CacheReadTokens is the number of prompt tokens read from the cache.
+	CacheReadTokens int64
+	// CacheWriteTokens is the number of prompt tokens written to the cache.
+	CacheWriteTokens int64
+	// Cost is the cost reported by the SDK, 0 if unknown.
+	Cost float64
+}
+
+// Type returns EventTypeUsage.
+func (e *UsageEvent) Type() EventType {
+	return EventTypeUsage
+}
+
+// Timestamp returns when the event occurred.
+func (e *UsageEvent) Timestamp() time.Time {
+	return e.timestamp
+}
+
+// NewUsageEvent creates a new UsageEvent with the given token counts.
+func NewUsageEvent(model string, inputTokens, outputTokens, cacheReadTokens, cacheWriteTokens int64, cost float64) *UsageEvent {
+	return &UsageEvent{
+		Model:            model,
+		InputTokens:      inputTokens,
+		OutputTokens:     outputTokens,
+		CacheReadTokens:  cacheReadTokens,
+		CacheWriteTokens: cacheWriteTokens,
+		Cost:             cost,
+		timestamp:        time.Now(),
+	}
+}
+
+// ToolDeniedEvent reports a tool request the tool policy refused.
+type ToolDeniedEvent struct {
+	timestamp time.Time
+	// Request is the refused request.
+	Request PermissionRequest
+	// Rule describes the policy rule that denied the request, or the default action.
+	Rule string
+}
+
+// Type returns EventTypeToolDenied.
+func (e *ToolDeniedEvent) Type() EventType {
+	return EventTypeToolDenied
+}
+
+// Timestamp returns when the event occurred.
+func (e *ToolDeniedEvent) Timestamp() time.Time {
+	return e.timestamp
+}
+
+// NewToolDeniedEvent creates a new ToolDeniedEvent for the refused request.
+func NewToolDeniedEvent(request PermissionRequest, rule string) *ToolDeniedEvent {
+	return &ToolDeniedEvent{
+		Request:   request,
+		Rule:      rule,
+		timestamp: time.Now(),
+	}
+}
+
 // ErrorEvent represents an error that occurred during processing.
 type ErrorEvent struct {
 	// Err contains the error that occurred.
@@ -142,3 +239,29 @@ func NewErrorEvent(err error) *ErrorEvent {
 		timestamp: time.Now(),
 	}
 }
+
+// Error types of Copilot session errors.
+const (
+	// ErrorTypeRateLimit is reported when the model is rate limited.
+	ErrorTypeRateLimit = "rate_limit"
+	// ErrorTypeQuota is reported when the request quota is exhausted.
+	ErrorTypeQuota = "quota"
+)
+
+// SessionError is an error reported by the Copilot session while processing a prompt.
+type SessionError struct {
+	// Type classifies the error, e.g. rate_limit or quota, empty when the session did not say.
+	Type string
+	// Message describes the error.
+	Message string
+}
+
+// Error returns the error message.
+func (e *SessionError) Error() string {
+	return "SDK error: " + e.Message
+}
+
+// RateLimited reports whether the error is a rate limit or an exhausted quota.
+func (e *SessionError) RateLimited() bool {
+	return e.Type == ErrorTypeRateLimit || e.Type == ErrorTypeQuota
+}
@@ -22,6 +22,18 @@ func TestEventTypesAndConstructors(t *testing.T) {
 	assert.Equal(t, EventTypeToolResult, r.Type())
 	assert.Equal(t, "res", r.Result)
 
+	rc := NewResponseCompleteEvent(Message{Content: "done", ToolCalls: []ToolCall{tc}})
+	assert.Equal(t, EventTypeResponseComplete, rc.Type())
+	assert.Equal(t, "done", rc.Message.Content)
+	assert.Len(t, rc.Message.ToolCalls, 1)
+	assert.WithinDuration(t, time.Now(), rc.Timestamp(), time.Second)
+
+	u := NewUsageEvent("gpt-4", 100, 20, 50, 10, 0.5)
+	assert.Equal(t, EventTypeUsage, u.Type())
+	assert.Equal(t, int64(100), u.InputTokens)
+	assert.Equal(t, int64(20), u.OutputTokens)
+	assert.WithinDuration(t, time.Now(), u.Timestamp(), time.Second)
+
 	e := NewErrorEvent(nil)
 	assert.Equal(t, EventTypeError, e.Type())
 	assert.Equal(t, "", e.Error())
@@ -0,0 +1,216 @@
+// Package sdk provides MCP server configuration for Copilot sessions.
+
+package sdk
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"maps"
+	"os"
+	"slices"
+	"strings"
+
+	copilot "github.com/github/copilot-sdk/go"
+)
+
+// MCP server types.
+const (
+	// MCPLocal runs the server as a local process speaking MCP over stdio.
+	MCPLocal = "local"
+	// MCPStdio is an alias of MCPLocal.
+	MCPStdio = "stdio"
+	// MCPHTTP connects to a server over streamable HTTP.
+	MCPHTTP = "http"
+	// MCPSSE connects to a server over server-sent events.
+	MCPSSE = "sse"
+)
+
+// MCPServer configures an MCP server whose tools are available to the model.
+// Local servers set Command, remote servers set URL.
+type MCPServer struct {
+	// Env holds extra environment variables of a local server.
+	Env map[string]string `json:"env,omitempty"`
+	// Headers holds HTTP headers sent to a remote server, e.g. for authentication.
+	Headers map[string]string `json:"headers,omitempty"`
+	// Tools lists the tools of the server available to the model, all tools when empty.
+	Tools []string `json:"tools,omitempty"`
+	// Args are the arguments of the command of a local server.
+	Args []string `json:"args,omitempty"`
+	// Type is local, stdio, http or sse. It defaults to local when Command is set and http otherwise.
+	Type string `json:"type,omitempty"`
+	// Command starts a local server.
+	Command string `json:"command,omitempty"`
+	// Cwd is the working directory of a local server.
+	Cwd string `json:"cwd,omitempty"`
+	// URL is the endpoint of a remote server.
+	URL string `json:"url,omitempty"`
+	// Timeout is the timeout of tool calls in milliseconds, the Copilot default when 0.
+	Timeout int `json:"timeout,omitempty"`
+}
+
+// Remote reports whether the server is reached over the network instead of started locally.
+func (s MCPServer) Remote() bool {
+	return s.Type == MCPHTTP || s.Type == MCPSSE
+}
+
+// String describes the server as configured, e.g. "local: npx -y @acme/docs-mcp" or "http: https://mcp.example.com".
+// Environment references are not expanded, so secrets are never printed.
+func (s MCPServer) String() string {
+	if s.Remote() {
+		return s.Type + ": " + s.URL
+	}
+
+	return s.Type + ": " + strings.Join(append([]string{s.Command}, s.Args...), " ")
+}
+
+// sessionConfig converts the server to the MCP server configuration of the Copilot SDK,
+// expanding environment references in the URL, env values and headers.
+func (s MCPServer) sessionConfig() (copilot.MCPServerConfig, error) {
+	tools := s.Tools
+	if len(tools) == 0 {
+		tools = []string{"*"}
+	}
+
+	var server any = copilot.MCPLocalServerConfig{
+		Tools:   tools,
+		Type:    s.Type,
+		Timeout: s.Timeout,
+		Command: s.Command,
+		Args:    s.Args,
+		Env:     expandEnv(s.Env),
+		Cwd:     s.Cwd,
+	}
+	if s.Remote() {
+		server = copilot.MCPRemoteServerConfig{
+			Tools:   tools,
+			Type:    s.Type,
+			Timeout: s.Timeout,
+			URL:     os.ExpandEnv(s.URL),
+			Headers: expandEnv(s.Headers),
+		}
+	}
+
+	// The SDK takes servers of either type as a generic map
+	data, err := json.Marshal(server)
+	if err != nil {
+		return nil, err
+	}
+
+	var config copilot.MCPServerConfig
+	if err := json.Unmarshal(data, &config); err != nil {
+		return nil, err
+	}
+
+	return config, nil
+}
+
+// MCPConfig lists MCP servers by name, in the mcp-config.json format of the Copilot CLI.
+type MCPConfig struct {
+	// Servers maps server names to their configuration.
+	Servers map[string]MCPServer `json:"mcpServers"`
+}
+
+// LoadMCPConfig reads an MCP configuration from a JSON file.
+func LoadMCPConfig(path string) (*MCPConfig, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read MCP config file %s: %w", path, err)
+	}
+
+	return ParseMCPConfig(data)
+}
+
+// ParseMCPConfig parses a JSON MCP configuration.
+// ${VAR} references in env values, headers and URLs are kept as written and only expanded
+// from the environment when a session is created, so secrets stay out of the file and the output.
+func ParseMCPConfig(data []byte) (*MCPConfig, error) {
+	decoder := json.NewDecoder(bytes.NewReader(data))
+	decoder.DisallowUnknownFields()
+
+	var config MCPConfig
+	if err := decoder.Decode(&config); err != nil {
+		return nil, fmt.Errorf("invalid MCP config: %w", err)
+	}
+
+	if err := config.validate(); err != nil {
+		return nil, err
+	}
+
+	return &config, nil
+}
+
+// validate defaults the server types and checks every server can be reached.
+func (c *MCPConfig) validate() error {
+	for _, name := range c.Names() {
+		server := c.Servers[name]
+
+		if server.Type == "" {
+			server.Type = MCPHTTP
+			if server.Command != "" {
+				server.Type = MCPLocal
+			}
+		}
+
+		switch server.Type {
+		case MCPLocal, MCPStdio:
+			if server.Command == "" {
+				return fmt.Errorf("invalid MCP config: server %q has no command", name)
+			}
+		case MCPHTTP, MCPSSE:
+			if server.URL == "" {
+				return fmt.Errorf("invalid MCP config: server %q has no url", name)
+			}
+		default:
+			return fmt.Errorf("invalid MCP config: server %q has invalid type %q (must be local, stdio, http, or sse)", name, server.Type)
+		}
+
+		if server.Timeout < 0 {
+			return fmt.Errorf("invalid MCP config: server %q timeout cannot be negative (got: %d)", name, server.Timeout)
+		}
+
+		c.Servers[name] = server
+	}
+
+	return nil
+}
+
+// Names returns the names of the servers in sorted order.
+func (c *MCPConfig) Names() []string {
+	return slices.Sorted(maps.Keys(c.Servers))
+}
+
+// expandEnv expands environment references in the values of a map.
+func expandEnv(values map[string]string) map[string]string {
+	if values == nil {
+		return nil
+	}
+
+	expanded := make(map[string]string, len(values))
+	for key, value := range values {
+		expanded[key] = os.ExpandEnv(value)
+	}
+
+	return expanded
+}
+
+// WithMCPServers makes the tools of the MCP servers available in every session the client creates.
+func WithMCPServers(servers map[string]MCPServer) ClientOption {
+	return func(c *clientConfig) {
+		c.mcpServers = servers
+	}
+}
+
+// sessionMCPServers converts the configured MCP servers to the configuration of the Copilot SDK.
+func (c *CopilotClient) sessionMCPServers() (map[string]copilot.MCPServerConfig, error) {
+	servers := make(map[string]copilot.MCPServerConfig, len(c.mcpServers))
+	for name, server := range c.mcpServers {
+		config, err := server.sessionConfig()
+		if err != nil {
+			return nil, fmt.Errorf("invalid MCP server %s: %w", name, err)
+		}
+		servers[name] = config
+	}
+
+	return servers, nil
+}
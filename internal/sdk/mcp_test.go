@@ -0,0 +1,112 @@
+package sdk
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const testMCPConfig = `{
+  "mcpServers": {
+    "docs": {
+      "command": "npx",
+      "args": ["-y", "@acme/docs-mcp"],
+      "env": {"DOCS_TOKEN": "${TEST_DOCS_TOKEN}"},
+      "timeout": 30000
+    },
+    "issues": {
+      "type": "http",
+      "url": "https://mcp.example.com/issues?key=${TEST_ISSUES_KEY}",
+      "headers": {"Authorization": "Bearer ${TEST_ISSUES_TOKEN}"},
+      "tools": ["create_issue", "search_issues"]
+    }
+  }
+}`
+
+func TestParseMCPConfig(t *testing.T) {
+	tests := []struct {
+		name string
+		data string
+		err  string
+	}{
+		{name: "valid config", data: testMCPConfig},
+		{name: "no servers", data: `{"mcpServers": {}}`},
+		{name: "local server without command", data: `{"mcpServers": {"docs": {"type": "stdio"}}}`, err: `server "docs" has no command`},
+		{name: "remote server without url", data: `{"mcpServers": {"issues": {"type": "sse"}}}`, err: `server "issues" has no url`},
+		{name: "server without command or url", data: `{"mcpServers": {"empty": {}}}`, err: `server "empty" has no url`},
+		{name: "invalid type", data: `{"mcpServers": {"docs": {"type": "grpc", "url": "x"}}}`, err: `invalid type "grpc"`},
+		{name: "negative timeout", data: `{"mcpServers": {"docs": {"command": "docs", "timeout": -1}}}`, err: "timeout cannot be negative"},
+		{name: "unknown field", data: `{"mcpServers": {"docs": {"command": "docs", "cmd": "docs"}}}`, err: "unknown field"},
+		{name: "invalid json", data: `{`, err: "invalid MCP config"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := ParseMCPConfig([]byte(tt.data))
+			if tt.err != "" {
+				assert.ErrorContains(t, err, tt.err)
+				return
+			}
+
+			assert.NoError(t, err)
+		})
+	}
+}
+
+func TestLoadMCPConfig(t *testing.T) {
+	t.Setenv("TEST_ISSUES_KEY", "key-secret")
+
+	path := filepath.Join(t.TempDir(), "mcp.json")
+	require.NoError(t, os.WriteFile(path, []byte(testMCPConfig), 0o644))
+
+	config, err := LoadMCPConfig(path)
+	require.NoError(t, err)
+	assert.Equal(t, []string{"docs", "issues"}, config.Names())
+
+	docs := config.Servers["docs"]
+	assert.Equal(t, MCPLocal, docs.Type)
+	assert.False(t, docs.Remote())
+	assert.Equal(t, "local: npx -y @acme/docs-mcp", docs.String())
+
+	// Environment references are only expanded for the session, never in the output
+	issues := config.Servers["issues"]
+	assert.True(t, issues.Remote())
+	assert.Equal(t, "http: https://mcp.example.com/issues?key=${TEST_ISSUES_KEY}", issues.String())
+
+	_, err = LoadMCPConfig(filepath.Join(t.TempDir(), "missing.json"))
+	assert.ErrorContains(t, err, "failed to read MCP config file")
+}
+
+func TestCopilotClientSessionMCPServers(t *testing.T) {
+	t.Setenv("TEST_DOCS_TOKEN", "docs-secret")
+	t.Setenv("TEST_ISSUES_TOKEN", "issues-secret")
+	t.Setenv("TEST_ISSUES_KEY", "key-secret")
+
+	config, err := ParseMCPConfig([]byte(testMCPConfig))
+	require.NoError(t, err)
+
+	client, err := NewCopilotClient(WithMCPServers(config.Servers))
+	require.NoError(t, err)
+
+	servers, err := client.sessionMCPServers()
+	require.NoError(t, err)
+	require.Len(t, servers, 2)
+
+	docs := servers["docs"]
+	assert.Equal(t, "local", docs["type"])
+	assert.Equal(t, "npx", docs["command"])
+	assert.Equal(t, []any{"-y", "@acme/docs-mcp"}, docs["args"])
+	assert.Equal(t, []any{"*"}, docs["tools"])
+	assert.Equal(t, map[string]any{"DOCS_TOKEN": "docs-secret"}, docs["env"])
+	assert.EqualValues(t, 30000, docs["timeout"])
+
+	issues := servers["issues"]
+	assert.Equal(t, "http", issues["type"])
+	assert.Equal(t, "https://mcp.example.com/issues?key=key-secret", issues["url"])
+	assert.Equal(t, map[string]any{"Authorization": "Bearer issues-secret"}, issues["headers"])
+	assert.Equal(t, []any{"create_issue", "search_issues"}, issues["tools"])
+	assert.NotContains(t, issues, "command")
+}
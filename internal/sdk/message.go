@@ -1,12 +1,12 @@
-// Package sdk
+// Package sdk provides message types for Copilot SDK integration.
 
 package sdk
 
 import "time"
 
-// Message represents a message that can accompany response-complete events.
-// It is intentionally minimal and no longer tracked in a local session object.
-
+// Message represents a complete assistant message.
+// It accompanies response-complete events so callers can inspect the final
+// text of a turn without reassembling streaming deltas.
 type Message struct {
 	Timestamp time.Time
 	Content   string
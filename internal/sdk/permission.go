@@ -0,0 +1,165 @@
+// Package sdk provides permission requests for tool executions.
+
+package sdk
+
+import (
+	"fmt"
+	"maps"
+	"slices"
+	"strings"
+
+	copilot "github.com/github/copilot-sdk/go"
+)
+
+// PermissionKind identifies the kind of operation a tool asks permission for.
+type PermissionKind string
+
+const (
+	// PermissionShell is requested before running a shell command.
+	PermissionShell PermissionKind = "shell"
+	// PermissionWrite is requested before writing a file.
+	PermissionWrite PermissionKind = "write"
+	// PermissionRead is requested before reading a file.
+	PermissionRead PermissionKind = "read"
+	// PermissionMCP is requested before calling a tool of an MCP server.
+	PermissionMCP PermissionKind = "mcp"
+	// PermissionURL is requested before fetching a URL.
+	PermissionURL PermissionKind = "url"
+)
+
+// String returns the string representation of the kind.
+func (k PermissionKind) String() string {
+	return string(k)
+}
+
+// Permission result kinds understood by the Copilot CLI.
+const (
+	permissionApproved     = "approved"
+	permissionDenied       = "denied-interactively-by-user"
+	permissionDeniedByRule = "denied-by-rules"
+)
+
+// summaryArguments are the arguments describing a request, in order of preference.
+var summaryArguments = []string{"fullCommandText", "fileName", "path", "url", "toolName"}
+
+// PermissionRequest asks whether a tool may run.
+type PermissionRequest struct {
+	// Arguments are the details of the request, which vary by kind.
+	Arguments map[string]any
+	// Kind is the kind of operation.
+	Kind PermissionKind
+	// ToolCallID identifies the tool call asking for permission.
+	ToolCallID string
+}
+
+// PermissionHandler decides whether a tool may run. It reports true to allow it.
+type PermissionHandler func(request PermissionRequest) bool
+
+// Tool returns the name of the tool asking for permission: the MCP tool name,
+// or the kind of operation for built-in tools.
+func (r PermissionRequest) Tool() string {
+	if name, ok := r.Arguments["toolName"].(string); ok && name != "" {
+		return name
+	}
+
+	return string(r.Kind)
+}
+
+// Summary describes what the tool is about to do, e.g. the shell command or the file name.
+func (r PermissionRequest) Summary() string {
+	for _, key := range summaryArguments {
+		if value, ok := r.Arguments[key]; ok && fmt.Sprint(value) != "" {
+			return fmt.Sprint(value)
+		}
+	}
+
+	return ""
+}
+
+// Details renders the arguments as sorted "key: value" lines, leaving out the summary.
+func (r PermissionRequest) Details() []string {
+	summary := r.Summary()
+
+	var details []string
+	for _, key := range slices.Sorted(maps.Keys(r.Arguments)) {
+		value := strings.TrimSpace(fmt.Sprint(r.Arguments[key]))
+		if value == "" || value == summary {
+			continue
+		}
+
+		details = append(details, key+": "+value)
+	}
+
+	return details
+}
+
+// newPermissionRequest converts a permission request of the Copilot SDK.
+func newPermissionRequest(request copilot.PermissionRequest) PermissionRequest {
+	arguments := maps.Clone(request.Extra)
+	delete(arguments, "kind")
+	delete(arguments, "toolCallId")
+
+	return PermissionRequest{
+		Arguments:  arguments,
+		Kind:       PermissionKind(request.Kind),
+		ToolCallID: request.ToolCallID,
+	}
+}
+
+// SetPermissionHandler makes sessions created afterwards ask handler before running a tool.
+// Without a handler, every tool runs without asking.
+func (c *CopilotClient) SetPermissionHandler(handler PermissionHandler) {
+	c.permissionHandler = handler
+}
+
+// handlePermission answers a permission request of the Copilot SDK with the tool policy,
+// then with the permission handler. Requests a policy rule allows run without asking.
+func (c *CopilotClient) handlePermission(request copilot.PermissionRequest, _ copilot.PermissionInvocation) (copilot.PermissionRequestResult, error) {
+	permission := newPermissionRequest(request)
+
+	if c.toolPolicy != nil {
+		action, rule := c.toolPolicy.Evaluate(permission, c.workingDir)
+		if action == PolicyDeny {
+			c.sendPromptEvent(NewToolDeniedEvent(permission, policyRuleLabel(action, rule)))
+			return copilot.PermissionRequestResult{Kind: permissionDeniedByRule}, nil
+		}
+
+		if rule != nil {
+			return copilot.PermissionRequestResult{Kind: permissionApproved}, nil
+		}
+	}
+
+	if c.permissionHandler != nil && !c.permissionHandler(permission) {
+		return copilot.PermissionRequestResult{Kind: permissionDenied}, nil
+	}
+
+	return copilot.PermissionRequestResult{Kind: permissionApproved}, nil
+}
+
+// policyRuleLabel describes the policy rule that decided a request, e.g. "deny write",
+// or "default deny" when no rule matched.
+func policyRuleLabel(action PolicyAction, rule *ToolRule) string {
+	if rule == nil {
+		return "default " + action.String()
+	}
+
+	return rule.String()
+}
+
+// setPromptEvents sets the event stream of the running prompt, nil when no prompt is running.
+func (c *CopilotClient) setPromptEvents(events chan<- Event) {
+	c.promptMu.Lock()
+	defer c.promptMu.Unlock()
+	c.promptEvents = events
+}
+
+// sendPromptEvent reports an event on the event stream of the running prompt, if any.
+func (c *CopilotClient) sendPromptEvent(event Event) {
+	c.promptMu.Lock()
+	events := c.promptEvents
+	c.promptMu.Unlock()
+
+	if events != nil {
+		_ = safeEventSender(events, event)
+	}
+}
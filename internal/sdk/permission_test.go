@@ -0,0 +1,83 @@
+package sdk
+
+import (
+	"testing"
+
+	copilot "github.com/github/copilot-sdk/go"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestNewPermissionRequest(t *testing.T) {
+	request := newPermissionRequest(copilot.PermissionRequest{
+		Kind:       "shell",
+		ToolCallID: "call-1",
+		Extra: map[string]any{
+			"kind":            "shell",
+			"toolCallId":      "call-1",
+			"fullCommandText": "go test ./...",
+			"intention":       "Run the tests",
+		},
+	})
+
+	assert.Equal(t, PermissionShell, request.Kind)
+	assert.Equal(t, "call-1", request.ToolCallID)
+	assert.Equal(t, "shell", request.Tool())
+	assert.Equal(t, "go test ./...", request.Summary())
+	assert.Equal(t, []string{"intention: Run the tests"}, request.Details())
+}
+
+func TestPermissionRequestTool(t *testing.T) {
+	tests := []struct {
+		name    string
+		request PermissionRequest
+		tool    string
+		summary string
+	}{
+		{
+			name:    "mcp tool",
+			request: PermissionRequest{Kind: PermissionMCP, Arguments: map[string]any{"serverName": "github", "toolName": "create_issue"}},
+			tool:    "create_issue",
+			summary: "create_issue",
+		},
+		{
+			name:    "file write",
+			request: PermissionRequest{Kind: PermissionWrite, Arguments: map[string]any{"fileName": "main.go"}},
+			tool:    "write",
+			summary: "main.go",
+		},
+		{
+			name:    "no arguments",
+			request: PermissionRequest{Kind: PermissionURL},
+			tool:    "url",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.tool, tt.request.Tool())
+			assert.Equal(t, tt.summary, tt.request.Summary())
+		})
+	}
+}
+
+func TestCopilotClientHandlePermission(t *testing.T) {
+	client, err := NewCopilotClient()
+	require.NoError(t, err)
+
+	var requests []PermissionRequest
+	client.SetPermissionHandler(func(request PermissionRequest) bool {
+		requests = append(requests, request)
+		return request.Kind == PermissionRead
+	})
+
+	result, err := client.handlePermission(copilot.PermissionRequest{Kind: "read"}, copilot.PermissionInvocation{})
+	require.NoError(t, err)
+	assert.Equal(t, permissionApproved, result.Kind)
+
+	result, err = client.handlePermission(copilot.PermissionRequest{Kind: "shell"}, copilot.PermissionInvocation{})
+	require.NoError(t, err)
+	assert.Equal(t, permissionDenied, result.Kind)
+
+	assert.Len(t, requests, 2)
+}
@@ -0,0 +1,228 @@
+// Package sdk provides a declarative policy deciding which tools may run.
+
+package sdk
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"regexp"
+
+	"gopkg.in/yaml.v3"
+)
+
+// PolicyAction is what a tool policy does with a tool request.
+type PolicyAction string
+
+const (
+	// PolicyAllow lets the tool run.
+	PolicyAllow PolicyAction = "allow"
+	// PolicyDeny refuses the tool, the model is told the policy denied it.
+	PolicyDeny PolicyAction = "deny"
+)
+
+// String returns the string representation of the action.
+func (a PolicyAction) String() string {
+	return string(a)
+}
+
+// AnyTool matches every tool in a policy rule.
+const AnyTool = "*"
+
+// toolAliases map the tool names the model sees to the permission kind they request.
+var toolAliases = map[string]string{
+	"bash": string(PermissionShell),
+}
+
+// ToolRule allows or denies a tool, optionally only for arguments matching a pattern.
+// Exactly one of Allow and Deny is set.
+type ToolRule struct {
+	pattern *regexp.Regexp
+	// Allow is the tool the rule allows: a permission kind (shell, write, read, mcp, url),
+	// an MCP tool name, or * for every tool.
+	Allow string `yaml:"allow"`
+	// Deny is the tool the rule denies, named like Allow.
+	Deny string `yaml:"deny"`
+	// Match is a regular expression the request must match for the rule to apply.
+	// It is matched against the shell command, the file path relative to the working
+	// directory, or the URL. An empty pattern matches every request of the tool.
+	Match string `yaml:"match"`
+}
+
+// Action returns whether the rule allows or denies the tool.
+func (r *ToolRule) Action() PolicyAction {
+	if r.Deny != "" {
+		return PolicyDeny
+	}
+
+	return PolicyAllow
+}
+
+// Tool returns the tool the rule applies to.
+func (r *ToolRule) Tool() string {
+	tool := r.Allow
+	if r.Deny != "" {
+		tool = r.Deny
+	}
+
+	if alias, ok := toolAliases[tool]; ok {
+		return alias
+	}
+
+	return tool
+}
+
+// String describes the rule, e.g. "allow shell matching ^go test".
+func (r *ToolRule) String() string {
+	tool := r.Allow
+	if r.Deny != "" {
+		tool = r.Deny
+	}
+
+	rule := fmt.Sprintf("%s %s", r.Action(), tool)
+	if r.Match != "" {
+		rule += " matching " + r.Match
+	}
+
+	return rule
+}
+
+// matches reports whether the rule applies to a request whose arguments are summarized by target.
+func (r *ToolRule) matches(request PermissionRequest, target string) bool {
+	tool := r.Tool()
+	if tool != AnyTool && tool != request.Tool() && tool != string(request.Kind) {
+		return false
+	}
+
+	return r.pattern == nil || r.pattern.MatchString(target)
+}
+
+// ToolPolicy decides which tools may run without asking anyone, for unattended runs.
+// Rules are checked in order and the first one matching a request decides it.
+type ToolPolicy struct {
+	// Default is the action for requests no rule matches, allow when empty.
+	// Requests allowed by default still ask for approval when a permission handler is set,
+	// requests allowed by a rule do not.
+	Default PolicyAction `yaml:"default"`
+	// Rules are checked in order.
+	Rules []ToolRule `yaml:"rules"`
+}
+
+// LoadToolPolicy reads a tool policy from a YAML file.
+func LoadToolPolicy(path string) (*ToolPolicy, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read tool policy file %s: %w", path, err)
+	}
+
+	return ParseToolPolicy(data)
+}
+
+// ParseToolPolicy parses a YAML tool policy.
+func ParseToolPolicy(data []byte) (*ToolPolicy, error) {
+	decoder := yaml.NewDecoder(bytes.NewReader(data))
+	decoder.KnownFields(true)
+
+	var policy ToolPolicy
+	if err := decoder.Decode(&policy); err != nil && !errors.Is(err, io.EOF) {
+		return nil, fmt.Errorf("invalid tool policy: %w", err)
+	}
+
+	if err := policy.validate(); err != nil {
+		return nil, err
+	}
+
+	return &policy, nil
+}
+
+// validate checks the default action and compiles the rule patterns.
+func (p *ToolPolicy) validate() error {
+	switch p.Default {
+	case "":
+		p.Default = PolicyAllow
+	case PolicyAllow, PolicyDeny:
+	default:
+		return fmt.Errorf("invalid tool policy: default must be allow or deny (got: %q)", p.Default)
+	}
+
+	for i := range p.Rules {
+		rule := &p.Rules[i]
+
+		if (rule.Allow == "") == (rule.Deny == "") {
+			return fmt.Errorf("invalid tool policy: rule %d must set exactly one of allow and deny", i+1)
+		}
+
+		if rule.Match == "" {
+			continue
+		}
+
+		pattern, err := regexp.Compile(rule.Match)
+		if err != nil {
+			return fmt.Errorf("invalid tool policy: rule %d: %w", i+1, err)
+		}
+		rule.pattern = pattern
+	}
+
+	return nil
+}
+
+// ReadOnly returns a copy of the policy that denies writing files and running shell commands
+// before checking its own rules, for clients that must not change the working directory.
+// A nil policy allows every other tool.
+func (p *ToolPolicy) ReadOnly() *ToolPolicy {
+	readOnly := &ToolPolicy{
+		Default: PolicyAllow,
+		Rules: []ToolRule{
+			{Deny: string(PermissionWrite)},
+			{Deny: string(PermissionShell)},
+		},
+	}
+
+	if p == nil {
+		return readOnly
+	}
+
+	readOnly.Default = p.Default
+	readOnly.Rules = append(readOnly.Rules, p.Rules...)
+
+	return readOnly
+}
+
+// Evaluate decides a tool request. It returns the matching rule,
+// or nil when no rule matches and the default action applies.
+// File paths are matched relative to workingDir.
+func (p *ToolPolicy) Evaluate(request PermissionRequest, workingDir string) (PolicyAction, *ToolRule) {
+	target := policyTarget(request, workingDir)
+
+	for i := range p.Rules {
+		if p.Rules[i].matches(request, target) {
+			return p.Rules[i].Action(), &p.Rules[i]
+		}
+	}
+
+	if p.Default == "" {
+		return PolicyAllow, nil
+	}
+
+	return p.Default, nil
+}
+
+// policyTarget returns what rule patterns are matched against: the request summary,
+// with file paths made relative to the working directory and using forward slashes.
+func policyTarget(request PermissionRequest, workingDir string) string {
+	target := request.Summary()
+	if target == "" || (request.Kind != PermissionWrite && request.Kind != PermissionRead) {
+		return target
+	}
+
+	if root, err := filepath.Abs(workingDir); err == nil && filepath.IsAbs(target) {
+		if relative, err := filepath.Rel(root, target); err == nil {
+			target = relative
+		}
+	}
+
+	return filepath.ToSlash(filepath.Clean(target))
+}
@@ -0,0 +1,237 @@
+package sdk
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	copilot "github.com/github/copilot-sdk/go"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const testPolicy = `
+default: deny
+rules:
+  - allow: bash
+    match: '^go (test|build|vet)( |$)'
+  - deny: shell
+  - allow: write
+    match: '^internal/'
+  - deny: write
+  - allow: read
+`
+
+func TestParseToolPolicy(t *testing.T) {
+	tests := []struct {
+		name string
+		data string
+		err  string
+	}{
+		{name: "valid policy", data: testPolicy},
+		{name: "empty policy", data: ""},
+		{name: "invalid default", data: "default: ask", err: `default must be allow or deny (got: "ask")`},
+		{name: "rule without action", data: "rules:\n  - match: x", err: "rule 1 must set exactly one of allow and deny"},
+		{name: "rule with both actions", data: "rules:\n  - allow: shell\n    deny: shell", err: "rule 1 must set exactly one of allow and deny"},
+		{name: "invalid pattern", data: "rules:\n  - allow: shell\n    match: '('", err: "rule 1: error parsing regexp"},
+		{name: "unknown field", data: "rules:\n  - allow: shell\n    command: go", err: "field command not found"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			policy, err := ParseToolPolicy([]byte(tt.data))
+			if tt.err != "" {
+				require.Error(t, err)
+				assert.Contains(t, err.Error(), tt.err)
+				return
+			}
+
+			require.NoError(t, err)
+			assert.NotEmpty(t, policy.Default)
+		})
+	}
+}
+
+func TestLoadToolPolicy(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "policy.yaml")
+	require.NoError(t, os.WriteFile(path, []byte(testPolicy), 0o644))
+
+	policy, err := LoadToolPolicy(path)
+	require.NoError(t, err)
+	assert.Equal(t, PolicyDeny, policy.Default)
+	assert.Len(t, policy.Rules, 5)
+
+	_, err = LoadToolPolicy(filepath.Join(t.TempDir(), "missing.yaml"))
+	assert.ErrorContains(t, err, "failed to read tool policy file")
+}
+
+func TestToolPolicyEvaluate(t *testing.T) {
+	policy, err := ParseToolPolicy([]byte(testPolicy))
+	require.NoError(t, err)
+
+	workingDir := t.TempDir()
+
+	tests := []struct {
+		name    string
+		request PermissionRequest
+		action  PolicyAction
+		rule    string
+	}{
+		{
+			name:    "allowed command",
+			request: PermissionRequest{Kind: PermissionShell, Arguments: map[string]any{"fullCommandText": "go test ./..."}},
+			action:  PolicyAllow,
+			rule:    "allow bash matching ^go (test|build|vet)( |$)",
+		},
+		{
+			name:    "other command",
+			request: PermissionRequest{Kind: PermissionShell, Arguments: map[string]any{"fullCommandText": "rm -rf /"}},
+			action:  PolicyDeny,
+			rule:    "deny shell",
+		},
+		{
+			name:    "write inside internal",
+			request: PermissionRequest{Kind: PermissionWrite, Arguments: map[string]any{"fileName": filepath.Join(workingDir, "internal", "core", "loop.go")}},
+			action:  PolicyAllow,
+			rule:    "allow write matching ^internal/",
+		},
+		{
+			name:    "relative write inside internal",
+			request: PermissionRequest{Kind: PermissionWrite, Arguments: map[string]any{"fileName": "./internal/cli/run.go"}},
+			action:  PolicyAllow,
+			rule:    "allow write matching ^internal/",
+		},
+		{
+			name:    "write outside internal",
+			request: PermissionRequest{Kind: PermissionWrite, Arguments: map[string]any{"fileName": filepath.Join(workingDir, "go.mod")}},
+			action:  PolicyDeny,
+			rule:    "deny write",
+		},
+		{
+			name:    "write escaping internal",
+			request: PermissionRequest{Kind: PermissionWrite, Arguments: map[string]any{"fileName": "internal/../go.mod"}},
+			action:  PolicyDeny,
+			rule:    "deny write",
+		},
+		{
+			name:    "read",
+			request: PermissionRequest{Kind: PermissionRead, Arguments: map[string]any{"path": "README.md"}},
+			action:  PolicyAllow,
+			rule:    "allow read",
+		},
+		{
+			name:    "default",
+			request: PermissionRequest{Kind: PermissionMCP, Arguments: map[string]any{"toolName": "create_issue"}},
+			action:  PolicyDeny,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			action, rule := policy.Evaluate(tt.request, workingDir)
+			assert.Equal(t, tt.action, action)
+			if tt.rule == "" {
+				assert.Nil(t, rule)
+				return
+			}
+
+			require.NotNil(t, rule)
+			assert.Equal(t, tt.rule, rule.String())
+		})
+	}
+}
+
+func TestToolPolicyReadOnly(t *testing.T) {
+	policy, err := ParseToolPolicy([]byte(testPolicy))
+	require.NoError(t, err)
+
+	workingDir := t.TempDir()
+	test := PermissionRequest{Kind: PermissionShell, Arguments: map[string]any{"fullCommandText": "go test ./..."}}
+	write := PermissionRequest{Kind: PermissionWrite, Arguments: map[string]any{"fileName": "internal/core/loop.go"}}
+	fetch := PermissionRequest{Kind: PermissionURL, Arguments: map[string]any{"url": "https://example.com"}}
+
+	readOnly := policy.ReadOnly()
+	for _, request := range []PermissionRequest{test, write} {
+		action, rule := readOnly.Evaluate(request, workingDir)
+		assert.Equal(t, PolicyDeny, action)
+		require.NotNil(t, rule)
+		assert.Equal(t, "deny "+request.Kind.String(), rule.String())
+	}
+
+	// The rules and default of the policy still apply to other tools
+	action, _ := readOnly.Evaluate(fetch, workingDir)
+	assert.Equal(t, PolicyDeny, action)
+	assert.Len(t, policy.Rules, 5)
+
+	var none *ToolPolicy
+	action, _ = none.ReadOnly().Evaluate(write, workingDir)
+	assert.Equal(t, PolicyDeny, action)
+	action, rule := none.ReadOnly().Evaluate(fetch, workingDir)
+	assert.Equal(t, PolicyAllow, action)
+	assert.Nil(t, rule)
+}
+
+func TestCopilotClientEnforcesToolPolicy(t *testing.T) {
+	policy, err := ParseToolPolicy([]byte(testPolicy))
+	require.NoError(t, err)
+
+	client, err := NewCopilotClient(WithToolPolicy(policy))
+	require.NoError(t, err)
+
+	var asked []PermissionRequest
+	client.SetPermissionHandler(func(request PermissionRequest) bool {
+		asked = append(asked, request)
+		return true
+	})
+
+	events := make(chan Event, 10)
+	client.setPromptEvents(events)
+
+	tests := []struct {
+		name    string
+		request copilot.PermissionRequest
+		result  string
+	}{
+		{
+			name:    "allowed by rule without asking",
+			request: copilot.PermissionRequest{Kind: "shell", Extra: map[string]any{"fullCommandText": "go vet ./..."}},
+			result:  permissionApproved,
+		},
+		{
+			name:    "denied by rule",
+			request: copilot.PermissionRequest{Kind: "shell", Extra: map[string]any{"fullCommandText": "curl example.com"}},
+			result:  permissionDeniedByRule,
+		},
+		{
+			name:    "denied by default",
+			request: copilot.PermissionRequest{Kind: "url", Extra: map[string]any{"url": "https://example.com"}},
+			result:  permissionDeniedByRule,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			result, err := client.handlePermission(tt.request, copilot.PermissionInvocation{})
+			require.NoError(t, err)
+			assert.Equal(t, tt.result, result.Kind)
+		})
+	}
+
+	assert.Empty(t, asked)
+
+	client.setPromptEvents(nil)
+	close(events)
+
+	var denied []*ToolDeniedEvent
+	for event := range events {
+		if ev, ok := event.(*ToolDeniedEvent); ok {
+			denied = append(denied, ev)
+		}
+	}
+
+	require.Len(t, denied, 2)
+	assert.Equal(t, "deny shell", denied[0].Rule)
+	assert.Equal(t, "curl example.com", denied[0].Request.Summary())
+	assert.Equal(t, "default deny", denied[1].Rule)
+	assert.Equal(t, EventTypeToolDenied, denied[1].Type())
+}
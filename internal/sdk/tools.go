@@ -2,9 +2,144 @@
 
 package sdk
 
+import (
+	"encoding/json"
+	"fmt"
+	"maps"
+	"slices"
+
+	copilot "github.com/github/copilot-sdk/go"
+)
+
 // ToolCall represents a tool invocation request from the assistant.
 type ToolCall struct {
 	Parameters map[string]any
 	ID         string
 	Name       string
 }
+
+// ToolHandler runs a tool with the arguments passed by the model and returns the result for the model.
+// Returning an error reports the tool execution as failed.
+type ToolHandler func(arguments map[string]any) (string, error)
+
+// Tool is a tool Ralph exposes to the model in its sessions.
+type Tool struct {
+	// Parameters is the JSON schema of the arguments.
+	Parameters map[string]any
+	// Handler runs the tool.
+	Handler ToolHandler
+	// Name is the name the model calls the tool by.
+	Name string
+	// Description tells the model what the tool does and when to use it.
+	Description string
+}
+
+// StringParameters returns the JSON schema of an object with the given required string properties,
+// mapping property names to their description.
+func StringParameters(properties map[string]string) map[string]any {
+	schema := make(map[string]any, len(properties))
+	required := make([]string, 0, len(properties))
+	for _, name := range slices.Sorted(maps.Keys(properties)) {
+		schema[name] = map[string]any{
+			"type":        "string",
+			"description": properties[name],
+		}
+		required = append(required, name)
+	}
+
+	return map[string]any{
+		"type":       "object",
+		"properties": schema,
+		"required":   required,
+	}
+}
+
+// Tool result types understood by the Copilot CLI.
+const (
+	toolResultSuccess = "success"
+	toolResultFailure = "failure"
+)
+
+// WithTools exposes tools to the model in every session the client creates.
+func WithTools(tools ...Tool) ClientOption {
+	return func(c *clientConfig) {
+		c.tools = append(c.tools, tools...)
+	}
+}
+
+// RegisterTools exposes tools to the model in sessions created afterwards.
+// A tool replaces a registered tool with the same name.
+func (c *CopilotClient) RegisterTools(tools ...Tool) {
+	for _, tool := range tools {
+		c.tools = slices.DeleteFunc(c.tools, func(registered Tool) bool {
+			return registered.Name == tool.Name
+		})
+		c.tools = append(c.tools, tool)
+	}
+}
+
+// sessionTools converts the registered tools to tools of the Copilot SDK.
+func (c *CopilotClient) sessionTools() []copilot.Tool {
+	tools := make([]copilot.Tool, 0, len(c.tools))
+	for _, tool := range c.tools {
+		tools = append(tools, copilot.Tool{
+			Name:        tool.Name,
+			Description: tool.Description,
+			Parameters:  tool.Parameters,
+			Handler:     invokeTool(tool.Handler),
+		})
+	}
+
+	return tools
+}
+
+// invokeTool adapts a tool handler to a tool handler of the Copilot SDK.
+func invokeTool(handler ToolHandler) copilot.ToolHandler {
+	return func(invocation copilot.ToolInvocation) (copilot.ToolResult, error) {
+		arguments, err := toolArguments(invocation.Arguments)
+		if err != nil {
+			return toolFailure(err), nil
+		}
+
+		result, err := handler(arguments)
+		if err != nil {
+			return toolFailure(err), nil
+		}
+
+		return copilot.ToolResult{
+			TextResultForLLM: result,
+			ResultType:       toolResultSuccess,
+		}, nil
+	}
+}
+
+// toolFailure reports a failed tool execution to the model.
+func toolFailure(err error) copilot.ToolResult {
+	return copilot.ToolResult{
+		TextResultForLLM: err.Error(),
+		ResultType:       toolResultFailure,
+		Error:            err.Error(),
+	}
+}
+
+// toolArguments converts the arguments of a tool invocation to a map.
+func toolArguments(arguments any) (map[string]any, error) {
+	switch args := arguments.(type) {
+	case nil:
+		return map[string]any{}, nil
+	case map[string]any:
+		return args, nil
+	}
+
+	data, err := json.Marshal(arguments)
+	if err != nil {
+		return nil, fmt.Errorf("invalid tool arguments: %w", err)
+	}
+
+	var args map[string]any
+	if err := json.Unmarshal(data, &args); err != nil {
+		return nil, fmt.Errorf("invalid tool arguments: %w", err)
+	}
+
+	return args, nil
+}
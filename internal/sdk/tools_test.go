@@ -0,0 +1,73 @@
+package sdk
+
+import (
+	"errors"
+	"testing"
+
+	copilot "github.com/github/copilot-sdk/go"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestStringParameters(t *testing.T) {
+	schema := StringParameters(map[string]string{"text": "The note", "kind": "The kind"})
+
+	assert.Equal(t, "object", schema["type"])
+	assert.Equal(t, []string{"kind", "text"}, schema["required"])
+	assert.Equal(t, map[string]any{"type": "string", "description": "The note"}, schema["properties"].(map[string]any)["text"])
+}
+
+func TestCopilotClientRegisterTools(t *testing.T) {
+	echo := Tool{Name: "echo", Handler: func(map[string]any) (string, error) { return "first", nil }}
+
+	client, err := NewCopilotClient(WithTools(echo))
+	require.NoError(t, err)
+
+	client.RegisterTools(
+		Tool{Name: "note", Handler: func(map[string]any) (string, error) { return "", nil }},
+		Tool{Name: "echo", Handler: func(map[string]any) (string, error) { return "second", nil }},
+	)
+
+	tools := client.sessionTools()
+	require.Len(t, tools, 2)
+	assert.Equal(t, "note", tools[0].Name)
+	assert.Equal(t, "echo", tools[1].Name)
+
+	result, err := tools[1].Handler(copilot.ToolInvocation{})
+	require.NoError(t, err)
+	assert.Equal(t, "second", result.TextResultForLLM)
+}
+
+func TestInvokeTool(t *testing.T) {
+	handler := invokeTool(func(arguments map[string]any) (string, error) {
+		text, _ := arguments["text"].(string)
+		if text == "" {
+			return "", errors.New("text is required")
+		}
+
+		return "noted: " + text, nil
+	})
+
+	tests := []struct {
+		name       string
+		arguments  any
+		resultType string
+		result     string
+	}{
+		{name: "map arguments", arguments: map[string]any{"text": "hello"}, resultType: toolResultSuccess, result: "noted: hello"},
+		{name: "struct arguments", arguments: struct {
+			Text string `json:"text"`
+		}{Text: "hi"}, resultType: toolResultSuccess, result: "noted: hi"},
+		{name: "handler error", arguments: nil, resultType: toolResultFailure, result: "text is required"},
+		{name: "invalid arguments", arguments: []string{"text"}, resultType: toolResultFailure, result: "invalid tool arguments"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			result, err := handler(copilot.ToolInvocation{Arguments: tt.arguments})
+			require.NoError(t, err)
+			assert.Equal(t, tt.resultType, result.ResultType)
+			assert.Contains(t, result.TextResultForLLM, tt.result)
+		})
+	}
+}